@@ -1,9 +1,14 @@
 package di
 
 import (
+	"DobrikaDev/max-bot/internal/metrics"
 	"DobrikaDev/max-bot/internal/service/bot"
+	"DobrikaDev/max-bot/internal/service/monitoring"
+	"DobrikaDev/max-bot/internal/storage"
 	"DobrikaDev/max-bot/utils/config"
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 
 	"go.uber.org/zap"
@@ -15,15 +20,43 @@ type Container struct {
 	logger     *zap.Logger
 	bot        *bot.Bot
 	httpClient *http.Client
+	store      storage.Store
+	monitoring *monitoring.Server
 }
 
+const defaultMonitoringAddr = ":9090"
+
 func NewContainer(ctx context.Context, cfg *config.Config, logger *zap.Logger) *Container {
 	return &Container{ctx: ctx, cfg: cfg, logger: logger}
 }
 
 func (c *Container) GetBot() *bot.Bot {
 	return get(&c.bot, func() *bot.Bot {
-		return bot.NewBot(c.ctx, c.cfg, c.logger)
+		return bot.NewBot(c.ctx, c.cfg, c.logger, c.GetSessionStore())
+	})
+}
+
+func (c *Container) GetSessionStore() storage.Store {
+	return get(&c.store, func() storage.Store {
+		store, err := storage.New(c.cfg)
+		if err != nil {
+			c.logger.Panic("failed to open session store", zap.Error(err))
+		}
+		c.logger.Info("Session store ready", zap.String("backend", c.cfg.SessionStore))
+		if err := metrics.RegisterSessionStore(store); err != nil {
+			c.logger.Warn("failed to register session store metrics", zap.Error(err))
+		}
+		return store
+	})
+}
+
+func (c *Container) GetMonitoringServer() *monitoring.Server {
+	return get(&c.monitoring, func() *monitoring.Server {
+		addr := c.cfg.MonitoringAddr
+		if addr == "" {
+			addr = defaultMonitoringAddr
+		}
+		return monitoring.NewServer(addr, c.logger, c.GetBot().Ready)
 	})
 }
 
@@ -33,6 +66,34 @@ func (c *Container) GetHTTPClient() *http.Client {
 	})
 }
 
+// Close shuts down everything the container has built, in reverse order of
+// dependency: the bot drains its updates first, then the session store is
+// flushed and closed. The monitoring server goes last so probes and scrapes
+// keep working while the bot drains.
+func (c *Container) Close(ctx context.Context) error {
+	var errs []error
+
+	if c.bot != nil {
+		if err := c.bot.Close(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("close bot: %w", err))
+		}
+	}
+
+	if c.store != nil {
+		if err := c.store.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close session store: %w", err))
+		}
+	}
+
+	if c.monitoring != nil {
+		if err := c.monitoring.Close(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("close monitoring server: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
 func get[T comparable](obj *T, builder func() T) T {
 	if *obj != *new(T) {
 		return *obj
@@ -0,0 +1,309 @@
+// Package callbackcodec turns callback payloads into compact signed tokens.
+//
+// A payload such as "customer:task:approve:<uuid>:<uuid>" is split into a
+// known route and its arguments. The route is replaced by a short hash, and
+// numbers and UUIDs are stored in binary. The result is signed with HMAC-SHA256
+// so it cannot be forged or edited. Tokens may carry an expiry.
+package callbackcodec
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Prefix marks a payload as a token. Plain payloads never start with it.
+const Prefix = "~"
+
+const (
+	version   byte = 1
+	macSize        = 12
+	routeSize      = 3
+
+	kindRaw    byte = 0
+	kindRouted byte = 1
+
+	argString byte = 0
+	argNumber byte = 1
+	argUUID   byte = 2
+)
+
+var (
+	ErrMalformed    = errors.New("callbackcodec: malformed token")
+	ErrSignature    = errors.New("callbackcodec: bad signature")
+	ErrExpired      = errors.New("callbackcodec: token expired")
+	ErrUnknownRoute = errors.New("callbackcodec: unknown route")
+	ErrNotToken     = errors.New("callbackcodec: payload is not a token")
+)
+
+// Codec encodes and decodes tokens. It is safe for concurrent use.
+type Codec struct {
+	key    []byte
+	ttl    time.Duration
+	routes map[[routeSize]byte]string
+	hashes map[string][routeSize]byte
+	now    func() time.Time
+}
+
+// New builds a codec signing with secret. Tokens expire after ttl unless ttl
+// is zero. Routes are the payload prefixes that get compacted; two routes
+// whose hashes collide are rejected so a token can never change meaning.
+func New(secret []byte, ttl time.Duration, routes []string) (*Codec, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("callbackcodec: empty secret")
+	}
+
+	c := &Codec{
+		key:    append([]byte(nil), secret...),
+		ttl:    ttl,
+		routes: make(map[[routeSize]byte]string, len(routes)),
+		hashes: make(map[string][routeSize]byte, len(routes)),
+		now:    time.Now,
+	}
+
+	for _, route := range routes {
+		route = strings.TrimSpace(route)
+		if route == "" {
+			continue
+		}
+		if _, ok := c.hashes[route]; ok {
+			continue
+		}
+
+		sum := sha256.Sum256([]byte(route))
+		var hash [routeSize]byte
+		copy(hash[:], sum[:])
+		if other, ok := c.routes[hash]; ok {
+			return nil, fmt.Errorf("callbackcodec: routes %q and %q collide", other, route)
+		}
+		c.routes[hash] = route
+		c.hashes[route] = hash
+	}
+
+	return c, nil
+}
+
+// IsToken reports whether payload looks like a token rather than plain text.
+func IsToken(payload string) bool {
+	return strings.HasPrefix(payload, Prefix)
+}
+
+// Encode returns the signed token for payload. Encoding a token again
+// returns it unchanged.
+func (c *Codec) Encode(payload string) string {
+	if IsToken(payload) {
+		return payload
+	}
+
+	body := []byte{version}
+
+	var expiry uint64
+	if c.ttl > 0 {
+		expiry = uint64(c.now().Add(c.ttl).Unix())
+	}
+	body = binary.AppendUvarint(body, expiry)
+
+	if route, args, ok := c.split(payload); ok {
+		hash := c.hashes[route]
+		body = append(body, kindRouted)
+		body = append(body, hash[:]...)
+		body = binary.AppendUvarint(body, uint64(len(args)))
+		for _, arg := range args {
+			body = appendArg(body, arg)
+		}
+	} else {
+		body = append(body, kindRaw)
+		body = binary.AppendUvarint(body, uint64(len(payload)))
+		body = append(body, payload...)
+	}
+
+	body = append(body, c.sign(body)...)
+	return Prefix + base64.RawURLEncoding.EncodeToString(body)
+}
+
+// Decode verifies token and returns the payload it was made from.
+func (c *Codec) Decode(token string) (string, error) {
+	if !IsToken(token) {
+		return "", ErrNotToken
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(token, Prefix))
+	if err != nil || len(data) < 1+macSize {
+		return "", ErrMalformed
+	}
+
+	body, mac := data[:len(data)-macSize], data[len(data)-macSize:]
+	if !hmac.Equal(mac, c.sign(body)) {
+		return "", ErrSignature
+	}
+
+	r := bytes.NewReader(body)
+	if v, err := r.ReadByte(); err != nil || v != version {
+		return "", ErrMalformed
+	}
+
+	expiry, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", ErrMalformed
+	}
+	if expiry != 0 && uint64(c.now().Unix()) > expiry {
+		return "", ErrExpired
+	}
+
+	kind, err := r.ReadByte()
+	if err != nil {
+		return "", ErrMalformed
+	}
+
+	switch kind {
+	case kindRaw:
+		payload, err := readString(r)
+		if err != nil || r.Len() != 0 {
+			return "", ErrMalformed
+		}
+		return payload, nil
+	case kindRouted:
+		var hash [routeSize]byte
+		if _, err := r.Read(hash[:]); err != nil {
+			return "", ErrMalformed
+		}
+		route, ok := c.routes[hash]
+		if !ok {
+			return "", ErrUnknownRoute
+		}
+
+		count, err := binary.ReadUvarint(r)
+		if err != nil || count > uint64(r.Len()) {
+			return "", ErrMalformed
+		}
+
+		parts := make([]string, 0, count+1)
+		parts = append(parts, route)
+		for i := uint64(0); i < count; i++ {
+			arg, err := readArg(r)
+			if err != nil {
+				return "", ErrMalformed
+			}
+			parts = append(parts, arg)
+		}
+		if r.Len() != 0 {
+			return "", ErrMalformed
+		}
+		return strings.Join(parts, ":"), nil
+	default:
+		return "", ErrMalformed
+	}
+}
+
+// split finds the longest registered route that payload starts with.
+func (c *Codec) split(payload string) (string, []string, bool) {
+	best := ""
+	for route := range c.hashes {
+		if len(route) <= len(best) {
+			continue
+		}
+		if payload == route || strings.HasPrefix(payload, route+":") {
+			best = route
+		}
+	}
+	if best == "" {
+		return "", nil, false
+	}
+	if payload == best {
+		return best, nil, true
+	}
+	return best, strings.Split(payload[len(best)+1:], ":"), true
+}
+
+func (c *Codec) sign(body []byte) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(body)
+	return mac.Sum(nil)[:macSize]
+}
+
+func appendArg(buf []byte, arg string) []byte {
+	if n, ok := canonicalNumber(arg); ok {
+		buf = append(buf, argNumber)
+		return binary.AppendUvarint(buf, n)
+	}
+	if id, ok := canonicalUUID(arg); ok {
+		buf = append(buf, argUUID)
+		return append(buf, id...)
+	}
+	buf = append(buf, argString)
+	buf = binary.AppendUvarint(buf, uint64(len(arg)))
+	return append(buf, arg...)
+}
+
+func readArg(r *bytes.Reader) (string, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+
+	switch tag {
+	case argNumber:
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatUint(n, 10), nil
+	case argUUID:
+		id := make([]byte, 16)
+		if _, err := r.Read(id); err != nil {
+			return "", err
+		}
+		s := hex.EncodeToString(id)
+		return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:], nil
+	case argString:
+		return readString(r)
+	default:
+		return "", ErrMalformed
+	}
+}
+
+func readString(r *bytes.Reader) (string, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil || size > uint64(r.Len()) {
+		return "", ErrMalformed
+	}
+	data := make([]byte, size)
+	if _, err := r.Read(data); err != nil && size > 0 {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// canonicalNumber accepts only digits that round-trip exactly, so "007"
+// stays a string.
+func canonicalNumber(s string) (uint64, bool) {
+	if s == "" || (len(s) > 1 && s[0] == '0') {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	return n, err == nil
+}
+
+// canonicalUUID accepts only the lowercase 8-4-4-4-12 form, which is the
+// one readArg writes back.
+func canonicalUUID(s string) ([]byte, bool) {
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return nil, false
+	}
+	if strings.ToLower(s) != s {
+		return nil, false
+	}
+	id, err := hex.DecodeString(s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:])
+	if err != nil {
+		return nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,181 @@
+package callbackcodec
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testRoutes = []string{"customer:task:view", "customer:task:approve", "main:menu"}
+
+func newTestCodec(t *testing.T, ttl time.Duration, routes []string) *Codec {
+	t.Helper()
+	c, err := New([]byte("test secret"), ttl, routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestRoundTrip(t *testing.T) {
+	c := newTestCodec(t, time.Hour, testRoutes)
+
+	payloads := []string{
+		"main:menu",
+		"customer:task:view:42",
+		"customer:task:approve:1b4e28ba-2fa1-11d2-883f-0016d3cca427:200",
+		"customer:task:view:007",
+		"customer:task:view:1B4E28BA-2FA1-11D2-883F-0016D3CCA427",
+		"customer:task:view:18446744073709551615",
+		"customer:task:view:18446744073709551616",
+		"customer:task:view:",
+		"customer:task:view:a::b",
+		"profile:data:export",
+		"",
+		"привет:мир",
+	}
+
+	for _, payload := range payloads {
+		t.Run(payload, func(t *testing.T) {
+			token := c.Encode(payload)
+			if !IsToken(token) {
+				t.Fatalf("token %q lacks the prefix", token)
+			}
+			if again := c.Encode(token); again != token {
+				t.Fatalf("encoding a token changed it: %q", again)
+			}
+
+			got, err := c.Decode(token)
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if got != payload {
+				t.Fatalf("got %q, want %q", got, payload)
+			}
+		})
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	c := newTestCodec(t, time.Hour, testRoutes)
+	valid := c.Encode("customer:task:approve:42:200")
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(valid, Prefix))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// modified returns valid with one byte changed.
+	modified := func(pos int) string {
+		changed := append([]byte(nil), data...)
+		changed[pos] ^= 0x01
+		return Prefix + base64.RawURLEncoding.EncodeToString(changed)
+	}
+	// resigned signs body as c would, for well-signed but invalid tokens.
+	resigned := func(body []byte) string {
+		return Prefix + base64.RawURLEncoding.EncodeToString(append(body, c.sign(body)...))
+	}
+
+	body := data[:len(data)-macSize]
+	badVersion := append([]byte{version + 1}, body[1:]...)
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"tampered MAC", modified(len(data) - 1), ErrSignature},
+		{"flipped payload byte", modified(len(body) - 1), ErrSignature},
+		{"flipped version byte", modified(0), ErrSignature},
+		{"bad version", resigned(badVersion), ErrMalformed},
+		{"unknown kind", resigned(append([]byte{version, 0}, 9)), ErrMalformed},
+		{"trailing bytes", resigned(append(append([]byte(nil), body...), 0)), ErrMalformed},
+		{"malformed base64", Prefix + "!!not*base64", ErrMalformed},
+		{"padded base64", Prefix + base64.URLEncoding.EncodeToString(data), ErrMalformed},
+		{"truncated", Prefix + base64.RawURLEncoding.EncodeToString(data[:macSize]), ErrMalformed},
+		{"cut short", valid[:len(valid)-4], ErrSignature},
+		{"empty token", Prefix, ErrMalformed},
+		{"legacy unsigned payload", "customer:task:approve:42:200", ErrNotToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := c.Decode(tt.token)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v (payload %q)", err, tt.err, payload)
+			}
+		})
+	}
+}
+
+func TestDecodeExpired(t *testing.T) {
+	c := newTestCodec(t, time.Minute, testRoutes)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	token := c.Encode("main:menu")
+
+	c.now = func() time.Time { return now.Add(time.Minute) }
+	if _, err := c.Decode(token); err != nil {
+		t.Fatalf("at expiry: %v", err)
+	}
+
+	c.now = func() time.Time { return now.Add(time.Minute + time.Second) }
+	if _, err := c.Decode(token); !errors.Is(err, ErrExpired) {
+		t.Fatalf("after expiry: err = %v, want %v", err, ErrExpired)
+	}
+}
+
+func TestDecodeNoExpiry(t *testing.T) {
+	c := newTestCodec(t, 0, testRoutes)
+	token := c.Encode("main:menu")
+
+	c.now = func() time.Time { return time.Now().AddDate(10, 0, 0) }
+	if _, err := c.Decode(token); err != nil {
+		t.Fatalf("token without ttl: %v", err)
+	}
+}
+
+func TestDecodeUnknownRoute(t *testing.T) {
+	token := newTestCodec(t, time.Hour, testRoutes).Encode("customer:task:view:42")
+
+	// Same key, but the route was removed from the table since.
+	c := newTestCodec(t, time.Hour, []string{"main:menu"})
+	if _, err := c.Decode(token); !errors.Is(err, ErrUnknownRoute) {
+		t.Fatalf("err = %v, want %v", err, ErrUnknownRoute)
+	}
+}
+
+func TestDecodeOtherSecret(t *testing.T) {
+	token := newTestCodec(t, time.Hour, testRoutes).Encode("main:menu")
+
+	c, err := New([]byte("other secret"), time.Hour, testRoutes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Decode(token); !errors.Is(err, ErrSignature) {
+		t.Fatalf("err = %v, want %v", err, ErrSignature)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(nil, time.Hour, testRoutes); err == nil {
+		t.Fatal("empty secret accepted")
+	}
+	if _, err := New([]byte("k"), time.Hour, []string{"main:menu", " main:menu ", ""}); err != nil {
+		t.Fatalf("repeated route: %v", err)
+	}
+}
+
+func TestLongestRouteWins(t *testing.T) {
+	c := newTestCodec(t, time.Hour, []string{"customer:task", "customer:task:view"})
+
+	route, args, ok := c.split("customer:task:view:42")
+	if !ok || route != "customer:task:view" || len(args) != 1 || args[0] != "42" {
+		t.Fatalf("split = %q %q %v", route, args, ok)
+	}
+	if _, _, ok := c.split("customer:taskview"); ok {
+		t.Fatal("matched a route without a separator")
+	}
+}
@@ -0,0 +1,168 @@
+// Command callbackgen generates callback payload constants from a route
+// table.
+//
+// It scans a Go file for composite literals of the given type whose first
+// two fields are string literals, the constant name and the route pattern:
+//
+//	{"callbackVolunteerTaskView", "volunteer:task:view/{taskID}", h.routeVolunteerTaskView}
+//
+// and writes a file declaring one constant per name holding the pattern's
+// static prefix, plus a callbackPrefixByName map used to detect a stale
+// output at startup.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"DobrikaDev/max-bot/internal/callbackrouter"
+)
+
+type entry struct {
+	name   string
+	prefix string
+}
+
+func main() {
+	in := flag.String("in", "routes.go", "file containing the route table")
+	out := flag.String("out", "navigation.go", "file to generate")
+	typeName := flag.String("type", "callbackRoute", "type of the route table entries")
+	flag.Parse()
+
+	entries, pkg, err := collect(*in, *typeName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(entries) == 0 {
+		log.Fatalf("no %s entries found in %s", *typeName, *in)
+	}
+
+	src, err := render(pkg, filepath.Base(*in), entries)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func collect(path, typeName string) ([]entry, string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var (
+		entries  []entry
+		byName   = make(map[string]string)
+		byPrefix = make(map[string]string)
+		firstErr error
+	)
+
+	add := func(lit *ast.CompositeLit) {
+		if firstErr != nil {
+			return
+		}
+		if len(lit.Elts) < 2 {
+			firstErr = fmt.Errorf("%s: route entries need a name and a pattern", fset.Position(lit.Pos()))
+			return
+		}
+
+		name, okName := stringLit(lit.Elts[0])
+		pattern, okPattern := stringLit(lit.Elts[1])
+		if !okName || !okPattern {
+			firstErr = fmt.Errorf("%s: route entries must start with two string literals", fset.Position(lit.Pos()))
+			return
+		}
+
+		prefix, _, err := callbackrouter.ParsePattern(pattern)
+		if err != nil {
+			firstErr = fmt.Errorf("%s: %w", fset.Position(lit.Pos()), err)
+			return
+		}
+
+		if existing, ok := byName[name]; ok {
+			if existing != prefix {
+				firstErr = fmt.Errorf("%s: %s is used for both %q and %q", fset.Position(lit.Pos()), name, existing, prefix)
+			}
+			return
+		}
+		if existing, ok := byPrefix[prefix]; ok {
+			firstErr = fmt.Errorf("%s: prefix %q is named both %s and %s", fset.Position(lit.Pos()), prefix, existing, name)
+			return
+		}
+
+		byName[name] = prefix
+		byPrefix[prefix] = name
+		entries = append(entries, entry{name: name, prefix: prefix})
+	}
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		lit, ok := node.(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+
+		switch typ := lit.Type.(type) {
+		case *ast.Ident:
+			if typ.Name == typeName {
+				add(lit)
+				return false
+			}
+		case *ast.ArrayType:
+			if elt, ok := typ.Elt.(*ast.Ident); ok && elt.Name == typeName {
+				for _, expr := range lit.Elts {
+					if item, ok := expr.(*ast.CompositeLit); ok {
+						add(item)
+					}
+				}
+				return false
+			}
+		}
+		return true
+	})
+
+	return entries, file.Name.Name, firstErr
+}
+
+func stringLit(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	return value, err == nil
+}
+
+func render(pkg, source string, entries []entry) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by callbackgen from %s; DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+
+	buf.WriteString("const (\n")
+	for _, e := range entries {
+		fmt.Fprintf(&buf, "\t%s = %q\n", e.name, e.prefix)
+	}
+	buf.WriteString(")\n\n")
+
+	buf.WriteString("// callbackPrefixByName lets the router check at startup that this file\n")
+	buf.WriteString("// matches the route table it was generated from.\n")
+	buf.WriteString("var callbackPrefixByName = map[string]string{\n")
+	for _, e := range entries {
+		fmt.Fprintf(&buf, "\t%q: %s,\n", e.name, e.name)
+	}
+	buf.WriteString("}\n")
+
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,93 @@
+package callbackrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrPanic wraps a panic recovered by Recover.
+var ErrPanic = errors.New("callbackrouter: handler panicked")
+
+type answerKey struct{}
+
+// ClaimAnswer reports whether the caller should answer the callback. The
+// first call inside a dispatch wrapped by AutoAnswer returns true and every
+// later call false, so a callback is answered exactly once no matter how
+// many helpers try. Outside AutoAnswer it always returns true.
+func ClaimAnswer(ctx context.Context) bool {
+	answered, ok := ctx.Value(answerKey{}).(*atomic.Bool)
+	if !ok {
+		return true
+	}
+	return answered.CompareAndSwap(false, true)
+}
+
+// AutoAnswer makes sure every callback is answered, which stops the client
+// from showing a spinner on the button. The answer is also sent when the
+// handler panics, so Recover can sit outside it. Handlers that answer on
+// their own, for example with a notification, should check ClaimAnswer
+// first.
+func AutoAnswer(answer func(ctx context.Context, req *Request)) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Request) error {
+			ctx = context.WithValue(ctx, answerKey{}, new(atomic.Bool))
+			defer func() {
+				if ClaimAnswer(ctx) {
+					answer(ctx, req)
+				}
+			}()
+			return next(ctx, req)
+		}
+	}
+}
+
+// Recover turns a panic in a handler into an ErrPanic error and logs the
+// stack.
+func Recover(logger *zap.Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Request) (err error) {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					logger.Error("callback handler panicked",
+						zap.String("route", req.Route.Pattern),
+						zap.Any("panic", recovered),
+						zap.ByteString("stack", debug.Stack()),
+					)
+					err = fmt.Errorf("%w: %v", ErrPanic, recovered)
+				}
+			}()
+			return next(ctx, req)
+		}
+	}
+}
+
+// Logging logs every dispatched callback with its route and duration.
+func Logging(logger *zap.Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Request) error {
+			started := time.Now()
+			err := next(ctx, req)
+
+			fields := []zap.Field{
+				zap.String("route", req.Route.Pattern),
+				zap.Int64("user_id", req.UserID()),
+				zap.Duration("duration", time.Since(started)),
+			}
+			switch {
+			case err == nil:
+				logger.Debug("callback handled", fields...)
+			case errors.Is(err, ErrNotHandled):
+				logger.Debug("callback not handled", fields...)
+			default:
+				logger.Warn("callback failed", append(fields, zap.Error(err))...)
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package callbackrouter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// chain registers handler behind the middleware the bot uses, in the same
+// order: Recover, AutoAnswer, Logging and an authorization check that lets
+// only allowed users through and answers the others itself.
+func chain(t *testing.T, handler HandlerFunc, allowed int64) (*Router, *int, *observer.ObservedLogs) {
+	t.Helper()
+
+	core, logs := observer.New(zap.DebugLevel)
+	logger := zap.New(core)
+	answers := new(int)
+
+	authorize := func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Request) error {
+			if req.UserID() != allowed {
+				if ClaimAnswer(ctx) {
+					*answers++
+				}
+				return nil
+			}
+			return next(ctx, req)
+		}
+	}
+
+	router := New()
+	router.Use(
+		Recover(logger),
+		AutoAnswer(func(context.Context, *Request) { *answers++ }),
+		Logging(logger),
+		authorize,
+	)
+	if err := router.Handle("task:view/{taskID}", handler); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	return router, answers, logs
+}
+
+func TestChainAnswersOnce(t *testing.T) {
+	ran := false
+	router, answers, logs := chain(t, func(context.Context, *Request) error {
+		ran = true
+		return nil
+	}, 7)
+
+	if err := router.Dispatch(context.Background(), update("task:view:42")); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if !ran || *answers != 1 {
+		t.Fatalf("ran %v, answers %d; want the handler to run and one answer", ran, *answers)
+	}
+	if logs.FilterMessage("callback handled").Len() != 1 {
+		t.Fatalf("logs: %v, want the callback logged as handled", logs.All())
+	}
+}
+
+func TestChainHandlerAnswersItself(t *testing.T) {
+	own := 0
+	router, answers, _ := chain(t, func(ctx context.Context, _ *Request) error {
+		if ClaimAnswer(ctx) {
+			own++
+		}
+		return nil
+	}, 7)
+
+	if err := router.Dispatch(context.Background(), update("task:view:42")); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if own != 1 || *answers != 0 {
+		t.Fatalf("handler answered %d times, AutoAnswer %d times; want 1 and 0", own, *answers)
+	}
+}
+
+func TestChainDenied(t *testing.T) {
+	ran := false
+	router, answers, logs := chain(t, func(context.Context, *Request) error {
+		ran = true
+		return nil
+	}, 8)
+
+	if err := router.Dispatch(context.Background(), update("task:view:42")); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if ran {
+		t.Fatal("handler ran for a denied user")
+	}
+	if *answers != 1 {
+		t.Fatalf("answers: got %d, want 1", *answers)
+	}
+	// Logging runs outside the check, so denials are logged too.
+	if logs.FilterMessage("callback handled").Len() != 1 {
+		t.Fatalf("logs: %v, want the denial logged", logs.All())
+	}
+}
+
+func TestChainRecoversPanic(t *testing.T) {
+	router, answers, logs := chain(t, func(context.Context, *Request) error {
+		panic("boom")
+	}, 7)
+
+	err := router.Dispatch(context.Background(), update("task:view:42"))
+	if !errors.Is(err, ErrPanic) {
+		t.Fatalf("Dispatch: got %v, want ErrPanic", err)
+	}
+	if *answers != 1 {
+		t.Fatalf("answers: got %d, want the button answered despite the panic", *answers)
+	}
+	if logs.FilterMessage("callback handler panicked").Len() != 1 {
+		t.Fatalf("logs: %v, want the panic logged", logs.All())
+	}
+}
+
+func TestChainReportsErrors(t *testing.T) {
+	failure := errors.New("task service down")
+	router, answers, logs := chain(t, func(context.Context, *Request) error {
+		return failure
+	}, 7)
+
+	if err := router.Dispatch(context.Background(), update("task:view:42")); !errors.Is(err, failure) {
+		t.Fatalf("Dispatch: got %v, want the handler error", err)
+	}
+	if *answers != 1 {
+		t.Fatalf("answers: got %d, want 1", *answers)
+	}
+	if logs.FilterMessage("callback failed").Len() != 1 {
+		t.Fatalf("logs: %v, want the failure logged", logs.All())
+	}
+
+	router, _, logs = chain(t, func(context.Context, *Request) error {
+		return ErrNotHandled
+	}, 7)
+	if err := router.Dispatch(context.Background(), update("task:view:42")); !errors.Is(err, ErrNotHandled) {
+		t.Fatalf("Dispatch: got %v, want ErrNotHandled", err)
+	}
+	if logs.FilterMessage("callback not handled").Len() != 1 {
+		t.Fatalf("logs: %v, want the callback logged as not handled", logs.All())
+	}
+}
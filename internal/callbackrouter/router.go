@@ -0,0 +1,218 @@
+// Package callbackrouter dispatches callback queries to handlers registered
+// by route pattern.
+//
+// A pattern is a static prefix followed by named parameters, for example
+// "volunteer:task:view/{taskID}". It matches payloads such as
+// "volunteer:task:view:42", with exactly one ":"-separated segment per
+// parameter. Routes that could both match the same payload are rejected when
+// they are registered, so dispatch never depends on registration order.
+package callbackrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
+)
+
+var (
+	// ErrNoRoute is returned by Dispatch when no route matches the payload.
+	ErrNoRoute = errors.New("callbackrouter: no route")
+	// ErrNotHandled may be returned by a handler that matched but declined
+	// the callback, for example because the flow it belongs to is not active.
+	ErrNotHandled = errors.New("callbackrouter: not handled")
+)
+
+// HandlerFunc handles a matched callback.
+type HandlerFunc func(ctx context.Context, req *Request) error
+
+// Middleware wraps a handler with cross-cutting behaviour.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Route is a registered pattern.
+type Route struct {
+	Pattern string
+	Prefix  string
+	Params  []string
+
+	handler HandlerFunc
+}
+
+// Request is a callback matched to a route.
+type Request struct {
+	Update  *schemes.MessageCallbackUpdate
+	Route   *Route
+	Payload string
+
+	params map[string]string
+}
+
+// Param returns the value of a named parameter, or "" if the route has none
+// by that name.
+func (r *Request) Param(name string) string {
+	return r.params[name]
+}
+
+// UserID is the id of the user who pressed the button.
+func (r *Request) UserID() int64 {
+	return r.Update.Callback.User.UserId
+}
+
+// ChatID is the chat of the message carrying the button, or 0 when the
+// update has no message.
+func (r *Request) ChatID() int64 {
+	if r.Update.Message == nil {
+		return 0
+	}
+	return r.Update.Message.Recipient.ChatId
+}
+
+// Router holds the route table. Register routes and middleware before the
+// first Dispatch; the router is not safe for concurrent registration.
+type Router struct {
+	routes     []*Route
+	byPrefix   map[string][]*Route
+	middleware []Middleware
+	errs       []error
+}
+
+func New() *Router {
+	return &Router{byPrefix: make(map[string][]*Route)}
+}
+
+// Use appends middleware applied to every route, outermost first.
+func (r *Router) Use(mw ...Middleware) {
+	r.middleware = append(r.middleware, mw...)
+}
+
+// Handle registers a route. Route-specific middleware runs inside the
+// router-wide middleware. Invalid or conflicting patterns are returned and
+// also remembered for Err, so a table can be registered in a loop and
+// checked once.
+func (r *Router) Handle(pattern string, handler HandlerFunc, mw ...Middleware) error {
+	prefix, params, err := ParsePattern(pattern)
+	if err == nil && handler == nil {
+		err = fmt.Errorf("callbackrouter: route %q has no handler", pattern)
+	}
+	if err == nil {
+		route := &Route{Pattern: pattern, Prefix: prefix, Params: params}
+		for _, existing := range r.routes {
+			if conflicts(existing, route) {
+				err = fmt.Errorf("callbackrouter: route %q conflicts with %q", pattern, existing.Pattern)
+				break
+			}
+		}
+		if err == nil {
+			for i := len(mw) - 1; i >= 0; i-- {
+				handler = mw[i](handler)
+			}
+			route.handler = handler
+			r.routes = append(r.routes, route)
+			r.byPrefix[prefix] = append(r.byPrefix[prefix], route)
+		}
+	}
+
+	if err != nil {
+		r.errs = append(r.errs, err)
+	}
+	return err
+}
+
+// Err reports every registration error seen so far.
+func (r *Router) Err() error {
+	return errors.Join(r.errs...)
+}
+
+// Routes returns the registered routes in registration order.
+func (r *Router) Routes() []*Route {
+	return append([]*Route(nil), r.routes...)
+}
+
+// Match finds the route for payload without running it.
+func (r *Router) Match(payload string) (*Route, map[string]string, bool) {
+	segments := strings.Split(payload, ":")
+	for i := len(segments); i > 0; i-- {
+		candidates := r.byPrefix[strings.Join(segments[:i], ":")]
+		arity := len(segments) - i
+		for _, route := range candidates {
+			if len(route.Params) != arity {
+				continue
+			}
+			params := make(map[string]string, arity)
+			for j, name := range route.Params {
+				params[name] = segments[i+j]
+			}
+			return route, params, true
+		}
+	}
+	return nil, nil, false
+}
+
+// Dispatch runs the handler for the update's payload through the middleware
+// chain. It returns ErrNoRoute when nothing matches.
+func (r *Router) Dispatch(ctx context.Context, update *schemes.MessageCallbackUpdate) error {
+	payload := update.Callback.Payload
+	route, params, ok := r.Match(payload)
+	if !ok {
+		return ErrNoRoute
+	}
+
+	handler := route.handler
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		handler = r.middleware[i](handler)
+	}
+
+	return handler(ctx, &Request{Update: update, Route: route, Payload: payload, params: params})
+}
+
+// ParsePattern splits a pattern into its static prefix and parameter names.
+func ParsePattern(pattern string) (string, []string, error) {
+	parts := strings.Split(pattern, "/")
+	prefix := parts[0]
+	if prefix == "" || strings.ContainsAny(prefix, "{}") || strings.HasPrefix(prefix, ":") || strings.HasSuffix(prefix, ":") || strings.Contains(prefix, "::") {
+		return "", nil, fmt.Errorf("callbackrouter: invalid prefix in pattern %q", pattern)
+	}
+
+	params := make([]string, 0, len(parts)-1)
+	seen := make(map[string]bool, len(parts)-1)
+	for _, part := range parts[1:] {
+		if len(part) < 3 || part[0] != '{' || part[len(part)-1] != '}' {
+			return "", nil, fmt.Errorf("callbackrouter: invalid parameter %q in pattern %q", part, pattern)
+		}
+		name := part[1 : len(part)-1]
+		if strings.ContainsAny(name, "{}:") || seen[name] {
+			return "", nil, fmt.Errorf("callbackrouter: invalid or duplicate parameter %q in pattern %q", name, pattern)
+		}
+		seen[name] = true
+		params = append(params, name)
+	}
+
+	return prefix, params, nil
+}
+
+// conflicts reports whether some payload would match both routes: they have
+// the same number of segments and every static segment of one lines up with
+// an equal static segment or a parameter of the other.
+func conflicts(a, b *Route) bool {
+	sa, sb := slots(a), slots(b)
+	if len(sa) != len(sb) {
+		return false
+	}
+	for i := range sa {
+		if sa[i] != "" && sb[i] != "" && sa[i] != sb[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// slots lists the segments of a route, with "" standing for a parameter.
+func slots(route *Route) []string {
+	out := strings.Split(route.Prefix, ":")
+	for range route.Params {
+		out = append(out, "")
+	}
+	return out
+}
@@ -0,0 +1,218 @@
+package callbackrouter
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
+)
+
+func nop(context.Context, *Request) error { return nil }
+
+func update(payload string) *schemes.MessageCallbackUpdate {
+	return &schemes.MessageCallbackUpdate{Callback: schemes.Callback{
+		CallbackID: "cb-1",
+		Payload:    payload,
+		User:       schemes.User{UserId: 7},
+	}}
+}
+
+func TestParsePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		prefix  string
+		params  []string
+		ok      bool
+	}{
+		{"profile:back", "profile:back", []string{}, true},
+		{"volunteer:task:view/{taskID}", "volunteer:task:view", []string{"taskID"}, true},
+		{"customer:task:approve/{taskID}/{volunteerID}", "customer:task:approve", []string{"taskID", "volunteerID"}, true},
+		{"", "", nil, false},
+		{"/{taskID}", "", nil, false},
+		{":profile", "", nil, false},
+		{"profile:", "", nil, false},
+		{"profile::back", "", nil, false},
+		{"profile:{id}", "", nil, false},
+		{"profile/id", "", nil, false},
+		{"profile/{}", "", nil, false},
+		{"profile/{a:b}", "", nil, false},
+		{"profile/{id}/{id}", "", nil, false},
+	}
+
+	for _, tt := range tests {
+		prefix, params, err := ParsePattern(tt.pattern)
+		if (err == nil) != tt.ok {
+			t.Errorf("ParsePattern(%q): err = %v, want ok %v", tt.pattern, err, tt.ok)
+			continue
+		}
+		if tt.ok && (prefix != tt.prefix || !slices.Equal(params, tt.params)) {
+			t.Errorf("ParsePattern(%q) = %q, %v; want %q, %v", tt.pattern, prefix, params, tt.prefix, tt.params)
+		}
+	}
+}
+
+func TestHandleRejectsConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		pattern  string
+		conflict bool
+	}{
+		{"duplicate", "profile:back", "profile:back", true},
+		{"duplicate with parameters", "task:view/{taskID}", "task:view/{id}", true},
+		{"parameter covers static segment", "task:view/{taskID}", "task:view:all", true},
+		{"static segment under parameter", "task:view:all", "task:view/{taskID}", true},
+		{"parameter covers prefix tail", "task/{action}/{taskID}", "task:view/{taskID}", true},
+		{"longer prefix", "profile:data", "profile:data:delete", false},
+		{"different arity", "task:view", "task:view/{taskID}", false},
+		{"different static segment", "task:view/{taskID}", "task:edit/{taskID}", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := New()
+			if err := router.Handle(tt.existing, nop); err != nil {
+				t.Fatalf("register %q: %v", tt.existing, err)
+			}
+
+			err := router.Handle(tt.pattern, nop)
+			if got := err != nil; got != tt.conflict {
+				t.Fatalf("register %q after %q: err = %v, want conflict %v", tt.pattern, tt.existing, err, tt.conflict)
+			}
+			if tt.conflict && len(router.Routes()) != 1 {
+				t.Fatalf("conflicting route was registered: %d routes", len(router.Routes()))
+			}
+		})
+	}
+}
+
+func TestErrCollectsEveryProblem(t *testing.T) {
+	router := New()
+	router.Handle("profile:back", nop)
+	router.Handle("profile:back", nop)
+	router.Handle("profile/{id}/{id}", nop)
+	router.Handle("profile:edit", nil)
+
+	err := router.Err()
+	if err == nil {
+		t.Fatal("Err() = nil, want the registration errors")
+	}
+	for _, want := range []string{`"profile:back" conflicts`, `duplicate parameter "id"`, "has no handler"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Err() = %q, want it to mention %q", err, want)
+		}
+	}
+	if got := len(router.Routes()); got != 1 {
+		t.Fatalf("routes: got %d, want 1", got)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	router := New()
+	for _, pattern := range []string{
+		"profile:data",
+		"profile:data:delete",
+		"profile:history:page/{page}",
+		"registration:about:toggle",
+		"registration:about:toggle/{index}",
+		"volunteer:tasks:page/{mode}/{filter}/{page}",
+		"customer:task:approve/{taskID}/{volunteerID}",
+	} {
+		if err := router.Handle(pattern, nop); err != nil {
+			t.Fatalf("register %q: %v", pattern, err)
+		}
+	}
+
+	tests := []struct {
+		payload string
+		pattern string
+		params  map[string]string
+	}{
+		{"profile:data", "profile:data", map[string]string{}},
+		{"profile:data:delete", "profile:data:delete", map[string]string{}},
+		{"profile:history:page:3", "profile:history:page/{page}", map[string]string{"page": "3"}},
+		{"registration:about:toggle", "registration:about:toggle", map[string]string{}},
+		{"registration:about:toggle:2", "registration:about:toggle/{index}", map[string]string{"index": "2"}},
+		{"volunteer:tasks:page:geo:all:1", "volunteer:tasks:page/{mode}/{filter}/{page}", map[string]string{"mode": "geo", "filter": "all", "page": "1"}},
+		{"customer:task:approve:42:200", "customer:task:approve/{taskID}/{volunteerID}", map[string]string{"taskID": "42", "volunteerID": "200"}},
+		{"customer:task:approve::", "customer:task:approve/{taskID}/{volunteerID}", map[string]string{"taskID": "", "volunteerID": ""}},
+
+		{"profile", "", nil},
+		{"profile:data:export", "", nil},
+		{"profile:history:page", "", nil},
+		{"profile:history:page:3:4", "", nil},
+		{"registration:about:toggle:2:3", "", nil},
+		{"customer:task:approve:42", "", nil},
+		{"", "", nil},
+	}
+
+	for _, tt := range tests {
+		route, params, ok := router.Match(tt.payload)
+		if tt.pattern == "" {
+			if ok {
+				t.Errorf("Match(%q) = %q, want no route", tt.payload, route.Pattern)
+			}
+			continue
+		}
+		if !ok {
+			t.Errorf("Match(%q): no route, want %q", tt.payload, tt.pattern)
+			continue
+		}
+		if route.Pattern != tt.pattern || !maps.Equal(params, tt.params) {
+			t.Errorf("Match(%q) = %q %v, want %q %v", tt.payload, route.Pattern, params, tt.pattern, tt.params)
+		}
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	router := New()
+	var got *Request
+	router.Handle("volunteer:task:view/{taskID}", func(_ context.Context, req *Request) error {
+		got = req
+		return nil
+	})
+
+	if err := router.Dispatch(context.Background(), update("volunteer:task:view:42")); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if got == nil || got.Param("taskID") != "42" || got.Param("other") != "" || got.UserID() != 7 || got.ChatID() != 0 {
+		t.Fatalf("handler got %+v", got)
+	}
+
+	if err := router.Dispatch(context.Background(), update("volunteer:task:edit:42")); !errors.Is(err, ErrNoRoute) {
+		t.Fatalf("Dispatch of an unknown payload: got %v, want ErrNoRoute", err)
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(ctx context.Context, req *Request) error {
+				calls = append(calls, name+" in")
+				err := next(ctx, req)
+				calls = append(calls, name+" out")
+				return err
+			}
+		}
+	}
+
+	router := New()
+	router.Use(record("first"), record("second"))
+	router.Handle("profile:back", func(context.Context, *Request) error {
+		calls = append(calls, "handler")
+		return nil
+	}, record("route"))
+
+	if err := router.Dispatch(context.Background(), update("profile:back")); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	want := []string{"first in", "second in", "route in", "handler", "route out", "second out", "first out"}
+	if !slices.Equal(calls, want) {
+		t.Fatalf("calls: got %v, want %v", calls, want)
+	}
+}
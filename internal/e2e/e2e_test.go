@@ -0,0 +1,29 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// scenarioTimeout bounds one scenario, so a stuck step fails the test
+// instead of hanging CI.
+const scenarioTimeout = time.Minute
+
+// TestScenarios plays every scenario in All against fresh fakes. The bot's
+// logs go to the test log, shown with -v or when a scenario fails; pick
+// scenarios with -run 'TestScenarios/<name>'.
+func TestScenarios(t *testing.T) {
+	for _, scenario := range All {
+		t.Run(scenario.Name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), scenarioTimeout)
+			defer cancel()
+
+			if err := scenario.Run(ctx, zaptest.NewLogger(t)); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
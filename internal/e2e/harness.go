@@ -0,0 +1,372 @@
+// Package e2e drives the bot's MessageHandler end to end against in-memory
+// fakes: the user, customer and task services over bufconn and the MAX Bot
+// API over a local HTTP server. Scenarios are scripted as steps that send
+// messages, press buttons and check what the chats show.
+//
+// The scenarios run as part of go test ./... and need no network or
+// credentials.
+package e2e
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"DobrikaDev/max-bot/internal/locales"
+	"DobrikaDev/max-bot/internal/service/bot"
+	"DobrikaDev/max-bot/internal/service/bot/handlers"
+	"DobrikaDev/max-bot/internal/storage"
+	"DobrikaDev/max-bot/utils/config"
+
+	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
+	"go.uber.org/zap"
+)
+
+const (
+	harnessToken = "e2e-token"
+
+	// expectTimeout bounds how long an expectation waits for the chat to
+	// catch up; notifications and retried sends arrive asynchronously.
+	expectTimeout = 3 * time.Second
+	pollInterval  = 10 * time.Millisecond
+)
+
+// Harness is a MessageHandler wired to fresh fakes.
+type Harness struct {
+	Services *Services
+	API      *MaxAPI
+	Handler  *handlers.MessageHandler
+	Messages locales.Messages
+
+	// LocaleDir is the handler's locale override directory. It starts
+	// empty; scenarios write files into it to change wording live.
+	LocaleDir string
+
+	store    storage.Store
+	sequence atomic.Int64
+}
+
+func NewHarness(logger *zap.Logger) (*Harness, error) {
+	localeDir, err := os.MkdirTemp("", "max-bot-e2e-locales-")
+	if err != nil {
+		return nil, fmt.Errorf("create locale dir: %w", err)
+	}
+
+	services := StartServices()
+	maxAPI := StartMaxAPI(harnessToken)
+
+	cfg := &config.Config{
+		MaxToken:           harnessToken,
+		MaxAPIURL:          maxAPI.URL(),
+		UserServiceURL:     ServiceTarget,
+		CustomerServiceURL: ServiceTarget,
+		TaskServiceURL:     ServiceTarget,
+		SessionTTL:         time.Hour,
+		CallbackTTL:        time.Hour,
+		NotifyDigestWindow: time.Minute,
+		NotifyTimezone:     "UTC",
+		OutboxMaxAttempts:  3,
+		OutboxMaxAge:       time.Minute,
+		LocaleDir:          localeDir,
+	}
+
+	client, err := bot.NewAPI(cfg)
+	if err != nil {
+		maxAPI.Close()
+		services.Close()
+		os.RemoveAll(localeDir)
+		return nil, fmt.Errorf("create MAX client: %w", err)
+	}
+
+	messages, err := locales.Load()
+	if err != nil {
+		maxAPI.Close()
+		services.Close()
+		os.RemoveAll(localeDir)
+		return nil, fmt.Errorf("load locales: %w", err)
+	}
+
+	store := storage.NewMemoryStore(time.Minute)
+
+	return &Harness{
+		Services:  services,
+		API:       maxAPI,
+		Handler:   handlers.NewMessageHandler(client, cfg, logger, store, handlers.WithDialOptions(services.DialOption())),
+		Messages:  messages,
+		LocaleDir: localeDir,
+		store:     store,
+	}, nil
+}
+
+func (h *Harness) Close() error {
+	err := errors.Join(h.Handler.Close(), h.store.Close())
+	h.API.Close()
+	h.Services.Close()
+	return errors.Join(err, os.RemoveAll(h.LocaleDir))
+}
+
+func (h *Harness) next() int64 {
+	return h.sequence.Add(1)
+}
+
+// eventually retries check until it passes or expectTimeout runs out, and
+// returns the last failure.
+func (h *Harness) eventually(ctx context.Context, check func() error) error {
+	deadline := time.Now().Add(expectTimeout)
+	for {
+		err := check()
+		if err == nil || time.Now().After(deadline) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// User is somebody talking to the bot. Their dialog with the bot has the
+// same id as the user, which is what the bot assumes when it messages a
+// user first.
+type User struct {
+	ID   int64
+	Name string
+}
+
+func (u User) MaxID() string {
+	return strconv.FormatInt(u.ID, 10)
+}
+
+func (u User) String() string {
+	return fmt.Sprintf("%s (%d)", u.Name, u.ID)
+}
+
+// Step is one action or check of a scenario.
+type Step struct {
+	Name string
+	Run  func(ctx context.Context, h *Harness) error
+}
+
+// Scenario is a named script. Script gets the loaded locale so steps can
+// refer to button labels and texts by field.
+type Scenario struct {
+	Name   string
+	Script func(m locales.Messages) []Step
+}
+
+// Run plays the scenario against a fresh harness and stops at the first
+// failing step.
+func (s Scenario) Run(ctx context.Context, logger *zap.Logger) (err error) {
+	h, err := NewHarness(logger)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := h.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("close harness: %w", closeErr)
+		}
+	}()
+
+	for i, step := range s.Script(h.Messages) {
+		if err := step.Run(ctx, h); err != nil {
+			return fmt.Errorf("step %d, %s: %w", i+1, step.Name, err)
+		}
+	}
+	return nil
+}
+
+// Steps joins groups of steps into one script.
+func Steps(groups ...[]Step) []Step {
+	var steps []Step
+	for _, group := range groups {
+		steps = append(steps, group...)
+	}
+	return steps
+}
+
+// Send has the user write text to the bot.
+func Send(u User, text string) Step {
+	return Step{
+		Name: fmt.Sprintf("%s sends %q", u, text),
+		Run: func(ctx context.Context, h *Harness) error {
+			h.Handler.HandleMessage(ctx, &schemes.MessageCreatedUpdate{
+				Update: schemes.Update{UpdateType: schemes.TypeMessageCreated, Timestamp: int(time.Now().Unix())},
+				Message: schemes.Message{
+					Sender:    schemes.User{UserId: u.ID, Name: u.Name},
+					Recipient: schemes.Recipient{ChatId: u.ID, ChatType: schemes.DIALOG},
+					Timestamp: time.Now().Unix(),
+					Body:      schemes.MessageBody{Mid: fmt.Sprintf("user.%d", h.next()), Text: text},
+				},
+			})
+			return nil
+		},
+	}
+}
+
+// Press has the user press the button whose text contains label. The most
+// recently updated message carrying such a button is used, so older menus
+// are only reached when the current one has no match.
+func Press(u User, label string) Step {
+	return Step{
+		Name: fmt.Sprintf("%s presses %q", u, label),
+		Run: func(ctx context.Context, h *Harness) error {
+			var (
+				message Message
+				button  Button
+			)
+			err := h.eventually(ctx, func() error {
+				for _, msg := range h.API.Recent(u.ID) {
+					if b, ok := msg.Button(label); ok {
+						message, button = msg, b
+						return nil
+					}
+				}
+				return fmt.Errorf("no button %q in chat; last message:\n%s", label, h.screen(u))
+			})
+			if err != nil {
+				return err
+			}
+			if button.Type != string(schemes.CALLBACK) {
+				return fmt.Errorf("button %q is a %s button, not a callback", button.Text, button.Type)
+			}
+
+			h.Handler.HandleCallbackQuery(ctx, &schemes.MessageCallbackUpdate{
+				Update: schemes.Update{UpdateType: schemes.TypeMessageCallback, Timestamp: int(time.Now().Unix())},
+				Callback: schemes.Callback{
+					Timestamp:  time.Now().Unix(),
+					CallbackID: fmt.Sprintf("callback.%d", h.next()),
+					Payload:    button.Payload,
+					User:       schemes.User{UserId: u.ID, Name: u.Name},
+				},
+				Message: &schemes.Message{
+					Recipient: schemes.Recipient{ChatId: message.ChatID, ChatType: schemes.DIALOG},
+					Body:      schemes.MessageBody{Mid: message.ID, Text: message.Text},
+				},
+			})
+			return nil
+		},
+	}
+}
+
+// ExpectText checks that the user's latest message contains text.
+func ExpectText(u User, text string) Step {
+	text = strings.TrimSpace(text)
+	return Step{
+		Name: fmt.Sprintf("%s sees %q", u, text),
+		Run: func(ctx context.Context, h *Harness) error {
+			return h.eventually(ctx, func() error {
+				if msg, ok := h.API.Last(u.ID); ok && strings.Contains(msg.Text, text) {
+					return nil
+				}
+				return fmt.Errorf("last message does not contain %q:\n%s", text, h.screen(u))
+			})
+		},
+	}
+}
+
+// ExpectMessage checks that some message in the user's chat contains text,
+// for things sent alongside the current menu such as notifications.
+func ExpectMessage(u User, text string) Step {
+	text = strings.TrimSpace(text)
+	return Step{
+		Name: fmt.Sprintf("%s received %q", u, text),
+		Run: func(ctx context.Context, h *Harness) error {
+			return h.eventually(ctx, func() error {
+				for _, msg := range h.API.Chat(u.ID) {
+					if strings.Contains(msg.Text, text) {
+						return nil
+					}
+				}
+				return fmt.Errorf("no message contains %q:\n%s", text, h.screen(u))
+			})
+		},
+	}
+}
+
+// ExpectButtons checks that the user's latest message has a button
+// containing each of labels.
+func ExpectButtons(u User, labels ...string) Step {
+	return Step{
+		Name: fmt.Sprintf("%s sees buttons %q", u, labels),
+		Run: func(ctx context.Context, h *Harness) error {
+			return h.eventually(ctx, func() error {
+				msg, _ := h.API.Last(u.ID)
+				for _, label := range labels {
+					if _, ok := msg.Button(label); !ok {
+						return fmt.Errorf("no button %q:\n%s", label, h.screen(u))
+					}
+				}
+				return nil
+			})
+		},
+	}
+}
+
+// ExpectNoButton checks that the user's latest message has no button
+// containing label.
+func ExpectNoButton(u User, label string) Step {
+	return Step{
+		Name: fmt.Sprintf("%s does not see button %q", u, label),
+		Run: func(ctx context.Context, h *Harness) error {
+			return h.eventually(ctx, func() error {
+				msg, _ := h.API.Last(u.ID)
+				if _, ok := msg.Button(label); ok {
+					return fmt.Errorf("unexpected button %q:\n%s", label, h.screen(u))
+				}
+				return nil
+			})
+		},
+	}
+}
+
+// Check runs an assertion against the harness, typically on the fakes'
+// state, retrying it like the other expectations.
+func Check(name string, check func(h *Harness) error) Step {
+	return Step{
+		Name: name,
+		Run: func(ctx context.Context, h *Harness) error {
+			return h.eventually(ctx, func() error { return check(h) })
+		},
+	}
+}
+
+// Do changes the harness directly, for setup the bot has no screen for.
+func Do(name string, action func(h *Harness) error) Step {
+	return Step{
+		Name: name,
+		Run: func(_ context.Context, h *Harness) error {
+			return action(h)
+		},
+	}
+}
+
+// screen renders the user's latest message for failure reports.
+func (h *Harness) screen(u User) string {
+	msg, ok := h.API.Last(u.ID)
+	if !ok {
+		return "  (no messages)"
+	}
+
+	var builder strings.Builder
+	for _, line := range strings.Split(msg.Text, "\n") {
+		builder.WriteString("  | ")
+		builder.WriteString(line)
+		builder.WriteString("\n")
+	}
+	for _, row := range msg.Keyboard {
+		labels := make([]string, 0, len(row))
+		for _, button := range row {
+			labels = append(labels, "["+button.Text+"]")
+		}
+		builder.WriteString("  ")
+		builder.WriteString(strings.Join(labels, " "))
+		builder.WriteString("\n")
+	}
+	return strings.TrimRight(builder.String(), "\n")
+}
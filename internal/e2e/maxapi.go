@@ -0,0 +1,284 @@
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
+)
+
+// Button is a keyboard button as the fake API received it.
+type Button struct {
+	Type    string `json:"type"`
+	Text    string `json:"text"`
+	Payload string `json:"payload,omitempty"`
+	Intent  string `json:"intent,omitempty"`
+}
+
+// Message is a bot message as a chat shows it: edits replace the text and
+// keyboard in place.
+type Message struct {
+	ID       string
+	ChatID   int64
+	Text     string
+	Format   string
+	Keyboard [][]Button
+	Edits    int
+
+	// seq orders messages by their last send or edit.
+	seq int
+}
+
+// Buttons flattens the keyboard.
+func (m *Message) Buttons() []Button {
+	var buttons []Button
+	for _, row := range m.Keyboard {
+		buttons = append(buttons, row...)
+	}
+	return buttons
+}
+
+// Button finds the first button whose text contains label.
+func (m *Message) Button(label string) (Button, bool) {
+	for _, button := range m.Buttons() {
+		if strings.Contains(button.Text, label) {
+			return button, true
+		}
+	}
+	return Button{}, false
+}
+
+// CallbackAnswer is a recorded answer to a button press.
+type CallbackAnswer struct {
+	CallbackID   string
+	Notification string
+}
+
+// MaxAPI fakes the parts of the MAX Bot API the bot uses: sending and
+// editing messages and answering callbacks. Requests without the expected
+// token are refused.
+type MaxAPI struct {
+	Token string
+
+	server *httptest.Server
+
+	mu       sync.Mutex
+	seq      int
+	messages map[string]*Message
+	chats    map[int64][]*Message
+	answers  []CallbackAnswer
+}
+
+func StartMaxAPI(token string) *MaxAPI {
+	api := &MaxAPI{
+		Token:    token,
+		messages: make(map[string]*Message),
+		chats:    make(map[int64][]*Message),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /messages", api.handleSend)
+	mux.HandleFunc("PUT /messages", api.handleEdit)
+	mux.HandleFunc("POST /answers", api.handleAnswer)
+	api.server = httptest.NewServer(api.authorize(mux))
+
+	return api
+}
+
+// URL is the base URL to configure the bot with.
+func (a *MaxAPI) URL() string {
+	return a.server.URL + "/"
+}
+
+func (a *MaxAPI) Close() {
+	a.server.Close()
+}
+
+// Chat returns copies of the chat's messages in the order they were sent.
+func (a *MaxAPI) Chat(chatID int64) []Message {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	messages := make([]Message, 0, len(a.chats[chatID]))
+	for _, msg := range a.chats[chatID] {
+		messages = append(messages, cloneMessage(msg))
+	}
+	return messages
+}
+
+// Recent returns the chat's messages, the most recently sent or edited
+// first.
+func (a *MaxAPI) Recent(chatID int64) []Message {
+	messages := a.Chat(chatID)
+	slices.SortFunc(messages, func(x, y Message) int { return y.seq - x.seq })
+	return messages
+}
+
+// Last returns the chat's most recently sent or edited message.
+func (a *MaxAPI) Last(chatID int64) (Message, bool) {
+	messages := a.Recent(chatID)
+	if len(messages) == 0 {
+		return Message{}, false
+	}
+	return messages[0], true
+}
+
+// Answers returns the recorded callback answers.
+func (a *MaxAPI) Answers() []CallbackAnswer {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return slices.Clone(a.answers)
+}
+
+func cloneMessage(msg *Message) Message {
+	clone := *msg
+	clone.Keyboard = make([][]Button, 0, len(msg.Keyboard))
+	for _, row := range msg.Keyboard {
+		clone.Keyboard = append(clone.Keyboard, slices.Clone(row))
+	}
+	return clone
+}
+
+func (a *MaxAPI) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("access_token") != a.Token {
+			writeError(w, http.StatusUnauthorized, "verify.token", "Invalid access_token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// maxTextLength is the real API's limit on message text, in characters.
+const maxTextLength = 4000
+
+type messageBody struct {
+	Text        string `json:"text"`
+	Format      string `json:"format"`
+	Attachments []struct {
+		Type    string `json:"type"`
+		Payload struct {
+			Buttons [][]Button `json:"buttons"`
+		} `json:"payload"`
+	} `json:"attachments"`
+}
+
+// tooLong mirrors the real API, which refuses texts over the limit.
+func (b messageBody) tooLong() bool {
+	return utf8.RuneCountInString(b.Text) > maxTextLength
+}
+
+func (b messageBody) keyboard() [][]Button {
+	for _, attachment := range b.Attachments {
+		if attachment.Type == string(schemes.AttachmentKeyboard) {
+			return attachment.Payload.Buttons
+		}
+	}
+	return nil
+}
+
+func (a *MaxAPI) handleSend(w http.ResponseWriter, r *http.Request) {
+	var body messageBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "proto.payload", err.Error())
+		return
+	}
+	if body.tooLong() {
+		writeError(w, http.StatusBadRequest, "proto.payload", "text: size must be between 0 and 4000")
+		return
+	}
+
+	query := r.URL.Query()
+	chatID, _ := strconv.ParseInt(query.Get("chat_id"), 10, 64)
+	if chatID == 0 {
+		// Dialogs share their id with the user, see User.
+		chatID, _ = strconv.ParseInt(query.Get("user_id"), 10, 64)
+	}
+	if chatID == 0 {
+		writeError(w, http.StatusBadRequest, "proto.payload", "chat_id or user_id is required")
+		return
+	}
+
+	a.mu.Lock()
+	a.seq++
+	msg := &Message{
+		ID:       fmt.Sprintf("mid.%d", a.seq),
+		ChatID:   chatID,
+		Text:     body.Text,
+		Format:   body.Format,
+		Keyboard: body.keyboard(),
+		seq:      a.seq,
+	}
+	a.messages[msg.ID] = msg
+	a.chats[chatID] = append(a.chats[chatID], msg)
+	a.mu.Unlock()
+
+	writeJSON(w, map[string]any{
+		"message": schemes.Message{
+			Recipient: schemes.Recipient{ChatId: chatID, ChatType: schemes.DIALOG},
+			Body:      schemes.MessageBody{Mid: msg.ID, Text: msg.Text},
+		},
+	})
+}
+
+func (a *MaxAPI) handleEdit(w http.ResponseWriter, r *http.Request) {
+	var body messageBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "proto.payload", err.Error())
+		return
+	}
+	if body.tooLong() {
+		writeError(w, http.StatusBadRequest, "proto.payload", "text: size must be between 0 and 4000")
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	msg, ok := a.messages[r.URL.Query().Get("message_id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not.found", "Message not found")
+		return
+	}
+
+	a.seq++
+	msg.Text = body.Text
+	msg.Format = body.Format
+	msg.Keyboard = body.keyboard()
+	msg.Edits++
+	msg.seq = a.seq
+
+	writeJSON(w, schemes.SimpleQueryResult{Success: true})
+}
+
+func (a *MaxAPI) handleAnswer(w http.ResponseWriter, r *http.Request) {
+	var answer schemes.CallbackAnswer
+	if err := json.NewDecoder(r.Body).Decode(&answer); err != nil {
+		writeError(w, http.StatusBadRequest, "proto.payload", err.Error())
+		return
+	}
+
+	a.mu.Lock()
+	a.answers = append(a.answers, CallbackAnswer{CallbackID: r.URL.Query().Get("callback_id"), Notification: answer.Notification})
+	a.mu.Unlock()
+
+	writeJSON(w, schemes.SimpleQueryResult{Success: true})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"code": code, "message": message})
+}
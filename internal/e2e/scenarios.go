@@ -0,0 +1,487 @@
+package e2e
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	userpb "DobrikaDev/max-bot/internal/generated/userpb"
+	"DobrikaDev/max-bot/internal/locales"
+)
+
+var (
+	alice = User{ID: 1001, Name: "Алиса"}
+	bob   = User{ID: 1002, Name: "Борис"}
+	carol = User{ID: 1003, Name: "Карина"}
+)
+
+// All lists the scenarios the e2e command runs.
+var All = []Scenario{
+	Registration,
+	CustomerCreation,
+	TaskCreation,
+	TaskApproval,
+	AdminModeration,
+	AccountSuspension,
+	Languages,
+	LocaleOverrides,
+	UserContent,
+}
+
+var Registration = Scenario{
+	Name: "registration",
+	Script: func(m locales.Messages) []Step {
+		return Steps(
+			register(m, alice),
+			[]Step{
+				ExpectButtons(alice, m.MainMenuButtons[0], m.MainMenuButtons[1]),
+				Check("user is stored with the answers", func(h *Harness) error {
+					user := h.Services.Users.User(alice.MaxID())
+					if user == nil {
+						return fmt.Errorf("user %s was not created", alice.MaxID())
+					}
+					if user.GetAge() != 29 {
+						return fmt.Errorf("age is %d, want 29", user.GetAge())
+					}
+					return nil
+				}),
+			},
+		)
+	},
+}
+
+var CustomerCreation = Scenario{
+	Name: "customer creation",
+	Script: func(m locales.Messages) []Step {
+		return Steps(
+			register(m, alice),
+			becomeCustomer(m, alice, "Алиса Иванова", "Помогаю соседям"),
+			[]Step{
+				ExpectButtons(alice, m.CustomerManageCreateTaskButton, m.CustomerManageTasksButton),
+				Check("customer is stored", func(h *Harness) error {
+					customer := h.Services.Customers.Customer(alice.MaxID())
+					if customer == nil {
+						return fmt.Errorf("customer %s was not created", alice.MaxID())
+					}
+					if customer.GetName() != "Алиса Иванова" {
+						return fmt.Errorf("name is %q", customer.GetName())
+					}
+					return nil
+				}),
+			},
+		)
+	},
+}
+
+var TaskCreation = Scenario{
+	Name: "task creation",
+	Script: func(m locales.Messages) []Step {
+		return Steps(
+			register(m, alice),
+			becomeCustomer(m, alice, "Алиса Иванова", "Помогаю соседям"),
+			createTask(m, alice, "Выгулять собаку", "Нужно погулять с собакой в субботу утром"),
+			[]Step{
+				Check("task is stored for the customer", func(h *Harness) error {
+					tasks := h.Services.Tasks.Tasks(alice.MaxID())
+					if len(tasks) != 1 {
+						return fmt.Errorf("customer has %d tasks, want 1", len(tasks))
+					}
+					if tasks[0].GetName() != "Выгулять собаку" {
+						return fmt.Errorf("task name is %q", tasks[0].GetName())
+					}
+					return nil
+				}),
+			},
+		)
+	},
+}
+
+// TaskApproval covers a task from publishing to the volunteer's reward: bob
+// joins alice's task and reports it done, alice approves and bob is paid.
+var TaskApproval = Scenario{
+	Name: "task approval",
+	Script: func(m locales.Messages) []Step {
+		const taskName = "Выгулять собаку"
+
+		return Steps(
+			register(m, alice),
+			becomeCustomer(m, alice, "Алиса Иванова", "Помогаю соседям"),
+			createTask(m, alice, taskName, "Нужно погулять с собакой в субботу утром"),
+
+			register(m, bob),
+			[]Step{
+				Press(bob, m.MainMenuButtons[0]),
+				Press(bob, m.VolunteerMenuOnDemandButton),
+				Press(bob, taskName),
+				Press(bob, m.VolunteerTaskJoinButton),
+				ExpectText(bob, firstLine(m.VolunteerTaskJoinSuccessText)),
+				Press(bob, m.VolunteerTaskConfirmButton),
+				ExpectText(bob, firstLine(m.VolunteerTaskConfirmSuccessText)),
+
+				// Publishing leaves alice on her task list.
+				Press(alice, taskName),
+				Press(alice, bob.Name),
+				Press(alice, m.CustomerTaskApproveButton),
+				ExpectText(alice, firstLine(m.CustomerTaskApproveSuccessText)),
+
+				ExpectMessage(bob, render(m.VolunteerTaskRewardNotification, map[string]any{"TaskName": taskName, "Amount": 50})),
+				Check("volunteer is credited", func(h *Harness) error {
+					if balance := h.Services.Users.Balance(bob.MaxID()); balance != 50 {
+						return fmt.Errorf("balance is %d, want 50", balance)
+					}
+					return nil
+				}),
+				Check("assignment is completed", func(h *Harness) error {
+					tasks := h.Services.Tasks.Tasks(alice.MaxID())
+					if len(tasks) != 1 {
+						return fmt.Errorf("customer has %d tasks, want 1", len(tasks))
+					}
+					if status := h.Services.Tasks.AssignmentStatus(tasks[0].GetId(), bob.MaxID()); status != StatusCompleted {
+						return fmt.Errorf("assignment status is %q, want %q", status, StatusCompleted)
+					}
+					return nil
+				}),
+			},
+		)
+	},
+}
+
+// AdminModeration has bob, an admin, credit alice, block her account and
+// take her task down, then find all of it in the audit log.
+var AdminModeration = Scenario{
+	Name: "admin moderation",
+	Script: func(m locales.Messages) []Step {
+		const (
+			taskName = "Выгулять собаку"
+			reason   = "Бонус за помощь на субботнике"
+		)
+
+		return Steps(
+			register(m, alice),
+			becomeCustomer(m, alice, "Алиса Иванова", "Помогаю соседям"),
+			createTask(m, alice, taskName, "Нужно погулять с собакой в субботу утром"),
+			register(m, bob),
+			[]Step{
+				Do("bob is made an admin", func(h *Harness) error {
+					return h.Services.Users.SetRole(bob.MaxID(), userpb.Role_ROLE_ADMIN)
+				}),
+				Send(bob, "/start"),
+				Press(bob, m.AdminMenuButton),
+				ExpectText(bob, firstLine(m.AdminMenuTitle)),
+
+				Press(bob, m.AdminUsersButton),
+				Press(bob, alice.Name),
+				Press(bob, m.AdminCoinsDepositButton),
+				ExpectText(bob, firstLine(m.AdminCoinsDepositPrompt)),
+				Send(bob, "много"),
+				ExpectText(bob, firstLine(m.AdminCoinsAmountInvalidText)),
+				Send(bob, "30"),
+				ExpectText(bob, firstLine(m.AdminCoinsReasonPrompt)),
+				Send(bob, reason),
+				Press(bob, m.AdminCoinsConfirmButton),
+				ExpectText(bob, m.AdminCoinsSuccessText),
+				Check("alice is credited", func(h *Harness) error {
+					if balance := h.Services.Users.Balance(alice.MaxID()); balance != 30 {
+						return fmt.Errorf("balance is %d, want 30", balance)
+					}
+					return nil
+				}),
+
+				Press(bob, m.AdminUserDeactivateButton),
+				ExpectText(bob, m.AdminUserDeactivatedText),
+				ExpectButtons(bob, m.AdminUserActivateButton),
+				Check("alice is blocked", func(h *Harness) error {
+					if status := h.Services.Users.User(alice.MaxID()).GetStatus(); status != userpb.Status_STATUS_INACTIVE {
+						return fmt.Errorf("status is %s", status)
+					}
+					return nil
+				}),
+
+				Press(bob, m.AdminUsersBackButton),
+				Press(bob, m.AdminBackButton),
+				Press(bob, m.AdminTasksButton),
+				Press(bob, taskName),
+				Press(bob, m.AdminTaskDeleteButton),
+				Press(bob, m.AdminTaskDeleteConfirmButton),
+				ExpectText(bob, firstLine(m.AdminTaskDeletedTemplate)),
+				ExpectMessage(alice, firstLine(m.AdminTaskDeletedNotification)),
+				Check("task is deleted", func(h *Harness) error {
+					if tasks := h.Services.Tasks.Tasks(alice.MaxID()); len(tasks) != 0 {
+						return fmt.Errorf("customer still has %d tasks", len(tasks))
+					}
+					return nil
+				}),
+
+				Press(bob, m.AdminBackButton),
+				Press(bob, m.AdminAuditButton),
+				ExpectText(bob, reason),
+				ExpectText(bob, taskName),
+			},
+		)
+	},
+}
+
+// AccountSuspension has bob suspend alice while her active status is still
+// cached: her very next click and message hit the suspended screen, and she
+// is let back in as soon as bob restores the account.
+var AccountSuspension = Scenario{
+	Name: "account suspension",
+	Script: func(m locales.Messages) []Step {
+		return Steps(
+			register(m, alice),
+			register(m, bob),
+			[]Step{
+				Do("bob is made an admin", func(h *Harness) error {
+					return h.Services.Users.SetRole(bob.MaxID(), userpb.Role_ROLE_ADMIN)
+				}),
+				Send(alice, "/start"),
+				ExpectText(alice, firstLine(m.MainMenuText)),
+
+				Send(bob, "/admin"),
+				Press(bob, m.AdminUsersButton),
+				Press(bob, alice.Name),
+				Press(bob, m.AdminUserDeactivateButton),
+				ExpectText(bob, m.AdminUserDeactivatedText),
+
+				Press(alice, m.MainMenuButtons[3]),
+				ExpectText(alice, firstLine(m.AccountSuspendedText)),
+				ExpectButtons(alice, m.AccountSuspendedSupportButton),
+				Send(alice, "/start"),
+				ExpectText(alice, firstLine(m.AccountSuspendedText)),
+
+				Press(bob, m.AdminUserActivateButton),
+				ExpectText(bob, m.AdminUserActivatedText),
+				Send(alice, "/start"),
+				ExpectText(alice, firstLine(m.MainMenuText)),
+			},
+		)
+	},
+}
+
+// Languages has alice switch to English in her profile and carol arrive
+// with an English MAX locale, so she registers in English. Bob arrives with a
+// Tatar MAX locale: his main menu is in Tatar and the screens that have no
+// Tatar translation yet fall back to Russian. When bob cancels a task alice
+// joined, she is told in English.
+var Languages = Scenario{
+	Name: "languages",
+	Script: func(m locales.Messages) []Step {
+		const taskName = "Выгулять собаку"
+
+		en := locales.For("en")
+		tt := locales.For("tt")
+
+		return Steps(
+			register(m, alice),
+			[]Step{
+				Press(alice, m.MainMenuButtons[0]),
+				Press(alice, m.VolunteerMenuProfileButton),
+				Press(alice, m.ProfileLanguageButton),
+				ExpectText(alice, firstLine(m.LanguageSelectText)),
+				Press(alice, en.LanguageName),
+				ExpectText(alice, en.LanguageChangedText),
+				ExpectButtons(alice, en.ProfileLanguageButton),
+				Send(alice, "/start"),
+				ExpectText(alice, firstLine(en.MainMenuText)),
+				ExpectButtons(alice, en.MainMenuButtons[0], en.MainMenuButtons[1]),
+
+				Do("carol's MAX locale is English", func(h *Harness) error {
+					h.Handler.RememberUserLocale(carol.ID, "en-GB")
+					return nil
+				}),
+			},
+			register(en, carol),
+			[]Step{
+				ExpectButtons(carol, en.MainMenuButtons[0], en.MainMenuButtons[1]),
+
+				Do("bob's MAX locale is Tatar", func(h *Harness) error {
+					h.Handler.RememberUserLocale(bob.ID, "tt-RU")
+					return nil
+				}),
+			},
+			register(m, bob),
+			[]Step{
+				ExpectButtons(bob, tt.MainMenuButtons[0], tt.MainMenuButtons[1]),
+			},
+
+			// A notification is worded for its recipient, not for whoever
+			// caused it.
+			becomeCustomer(tt, bob, "Борис Петров", "Помогаю соседям"),
+			createTask(tt, bob, taskName, "Нужно погулять с собакой в субботу утром"),
+			[]Step{
+				Press(alice, en.MainMenuButtons[0]),
+				Press(alice, en.VolunteerMenuOnDemandButton),
+				Press(alice, taskName),
+				Press(alice, en.VolunteerTaskJoinButton),
+				ExpectText(alice, firstLine(en.VolunteerTaskJoinSuccessText)),
+
+				Press(bob, taskName),
+				Press(bob, tt.CustomerTaskCancelButton),
+				Press(bob, tt.TaskCancelConfirmButton),
+				ExpectMessage(alice, firstLine(en.TaskCancelledNotification)),
+			},
+		)
+	},
+}
+
+// LocaleOverrides changes wording through the override directory while the
+// bot runs, then breaks the override and checks the last good text stays.
+var LocaleOverrides = Scenario{
+	Name: "locale overrides",
+	Script: func(m locales.Messages) []Step {
+		const (
+			menuText  = "Главное меню, версия из ConfigMap"
+			broken    = `{"main_menu_text": "Главное меню на %s"}`
+			reloadGap = 2 * time.Second
+		)
+
+		override := func(name, content string) Step {
+			return Do(name, func(h *Harness) error {
+				if err := os.WriteFile(filepath.Join(h.LocaleDir, "ru.json"), []byte(content), 0o644); err != nil {
+					return err
+				}
+				// The bot reloads after the file events settle.
+				time.Sleep(reloadGap)
+				return nil
+			})
+		}
+
+		return Steps(
+			register(m, alice),
+			[]Step{
+				override("ops reword the main menu", fmt.Sprintf(`{"main_menu_text": %q}`, menuText)),
+				Send(alice, "/start"),
+				ExpectText(alice, menuText),
+
+				override("ops add a placeholder the code does not fill", broken),
+				Send(alice, "/start"),
+				ExpectText(alice, menuText),
+			},
+		)
+	},
+}
+
+// UserContent has alice name a task with markdown characters and describe
+// it at length: the name shows as typed and screens over the MAX text limit
+// come as several messages, which the fake API would otherwise refuse.
+var UserContent = Scenario{
+	Name: "user content",
+	Script: func(m locales.Messages) []Step {
+		const (
+			taskName = "Выгулять [собаку]*"
+			escaped  = `Выгулять \[собаку\]\*`
+		)
+		description := strings.TrimSpace(strings.Repeat("Погулять с собакой по_кличке *Шарик* в парке у дома.\n", 70))
+
+		return Steps(
+			register(m, alice),
+			becomeCustomer(m, alice, "Алиса_Иванова", "Помогаю соседям"),
+			createTask(m, alice, taskName, description),
+			[]Step{
+				ExpectMessage(alice, escaped),
+				Press(alice, taskName),
+				ExpectMessage(alice, fmt.Sprintf(m.CustomerTaskDetailTitle, escaped)),
+				ExpectButtons(alice, m.CustomerManageBackButton),
+				Check("long screens are split within the limit", func(h *Harness) error {
+					parts := 0
+					for _, msg := range h.API.Chat(alice.ID) {
+						if n := utf8.RuneCountInString(msg.Text); n > maxTextLength {
+							return fmt.Errorf("message of %d characters was accepted", n)
+						}
+						if strings.Contains(msg.Text, `по\_кличке \*Шарик\*`) {
+							parts++
+						}
+					}
+					if parts < 2 {
+						return fmt.Errorf("description is in %d messages, want it split", parts)
+					}
+					return nil
+				}),
+			},
+		)
+	},
+}
+
+func register(m locales.Messages, u User) []Step {
+	return []Step{
+		Send(u, "/start"),
+		ExpectText(u, firstLine(m.NewUserWelcomeText)),
+		Press(u, m.NewUserJoinButton),
+		ExpectText(u, firstLine(m.RegistrationStartText)),
+		Press(u, m.RegistrationAge25_34Button),
+		ExpectText(u, firstLine(m.RegistrationSexPrompt)),
+		Press(u, m.RegistrationSexFemaleText),
+		ExpectText(u, firstLine(m.RegistrationLocationPrompt)),
+		Press(u, m.RegistrationLocationSkipButton),
+		ExpectText(u, firstLine(m.RegistrationAboutPrompt)),
+		Press(u, m.RegistrationAboutOptions[0]),
+		Press(u, m.RegistrationAboutConfirmButton),
+		ExpectMessage(u, firstLine(m.RegistrationCompleteText)),
+	}
+}
+
+func becomeCustomer(m locales.Messages, u User, name, about string) []Step {
+	return []Step{
+		Press(u, m.MainMenuButtons[1]),
+		ExpectText(u, firstLine(m.CustomerTypePrompt)),
+		Press(u, m.CustomerTypeIndividualButton),
+		ExpectText(u, firstLine(m.CustomerNamePromptIndividual)),
+		Send(u, name),
+		ExpectText(u, firstLine(m.CustomerAboutPromptIndividual)),
+		Send(u, about),
+		ExpectMessage(u, firstLine(m.CustomerCreateSuccessText)),
+	}
+}
+
+func createTask(m locales.Messages, u User, name, description string) []Step {
+	return []Step{
+		Press(u, m.CustomerManageCreateTaskButton),
+		ExpectText(u, firstLine(m.TaskCreateNamePrompt)),
+		Send(u, name),
+		ExpectText(u, firstLine(m.TaskCreateDescriptionPrompt)),
+		Send(u, description),
+		Press(u, m.TaskCreateFormatOnlineButton),
+		Press(u, m.TaskCreateMembersSkipButton),
+		Press(u, m.TaskCreateReviewConfirmButton),
+		ExpectMessage(u, firstLine(fmt.Sprintf(m.TaskCreateSuccessText, escapeMarkdown(name)))),
+	}
+}
+
+// firstLine keeps expectations to the stable head of a locale text, before
+// any placeholders or formatting-sensitive tails.
+func firstLine(text string) string {
+	text = strings.TrimSpace(text)
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	if i := strings.IndexAny(text, "%{"); i >= 0 {
+		text = text[:i]
+	}
+	return strings.TrimSpace(text)
+}
+
+// render fills a Russian locale text with named placeholders the way the
+// bot does.
+func render(text string, data any) string {
+	out, err := locales.FormatterFor(locales.DefaultLanguage).Render(text, data)
+	if err != nil {
+		panic(fmt.Sprintf("render %q: %v", text, err))
+	}
+	return out
+}
+
+// escapeMarkdown writes user content the way the bot puts it into a
+// markdown text.
+func escapeMarkdown(text string) string {
+	var builder strings.Builder
+	for _, r := range text {
+		if strings.ContainsRune("\\*_~+`[]", r) {
+			builder.WriteByte('\\')
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
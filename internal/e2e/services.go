@@ -0,0 +1,766 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	customerpb "DobrikaDev/max-bot/internal/generated/customerpb"
+	taskpb "DobrikaDev/max-bot/internal/generated/taskpb"
+	userpb "DobrikaDev/max-bot/internal/generated/userpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	bufconnSize = 1 << 20
+
+	// ServiceTarget is the address the handler dials; any value works since
+	// the bufconn dialer ignores it.
+	ServiceTarget = "bufnet"
+
+	// assignmentsMetaKey holds a task's volunteers as a JSON array of
+	// {"user_id","status"} objects, one of the shapes the bot parses.
+	assignmentsMetaKey = "assignments"
+)
+
+// Assignment statuses as the fake task service stores them: joining puts a
+// volunteer in progress, confirming marks the work done and the customer's
+// approval completes it.
+const (
+	StatusInProgress = "in_progress"
+	StatusConfirmed  = "confirmed"
+	StatusCompleted  = "completed"
+	StatusRejected   = "rejected"
+)
+
+// Services runs in-memory user, customer and task services on a single
+// gRPC server listening on a bufconn.
+type Services struct {
+	Users     *UserService
+	Customers *CustomerService
+	Tasks     *TaskService
+
+	listener *bufconn.Listener
+	server   *grpc.Server
+}
+
+func StartServices() *Services {
+	s := &Services{
+		Users:     newUserService(),
+		Customers: newCustomerService(),
+		Tasks:     newTaskService(),
+		listener:  bufconn.Listen(bufconnSize),
+		server:    grpc.NewServer(),
+	}
+
+	userpb.RegisterUserServiceServer(s.server, s.Users)
+	customerpb.RegisterCustomerServiceServer(s.server, s.Customers)
+	taskpb.RegisterTaskServiceServer(s.server, s.Tasks)
+
+	go s.server.Serve(s.listener)
+
+	return s
+}
+
+// DialOption routes every connection to the in-memory server.
+func (s *Services) DialOption() grpc.DialOption {
+	return grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return s.listener.DialContext(ctx)
+	})
+}
+
+func (s *Services) Close() {
+	s.server.Stop()
+	s.listener.Close()
+}
+
+func now() int32 {
+	return int32(time.Now().Unix())
+}
+
+// UserService keeps users and their balances.
+type UserService struct {
+	userpb.UnimplementedUserServiceServer
+
+	mu         sync.Mutex
+	users      map[string]*userpb.User
+	operations map[string][]*userpb.BalanceOperation
+	nextOpID   int
+}
+
+func newUserService() *UserService {
+	return &UserService{
+		users:      make(map[string]*userpb.User),
+		operations: make(map[string][]*userpb.BalanceOperation),
+	}
+}
+
+func userError(code userpb.ErrorCode, format string, args ...any) *userpb.Error {
+	return &userpb.Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// User returns a copy of the stored user, or nil.
+func (s *UserService) User(maxID string) *userpb.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user, ok := s.users[maxID]; ok {
+		return proto.Clone(user).(*userpb.User)
+	}
+	return nil
+}
+
+// SetRole changes a stored user's role, for scenarios that need an admin.
+func (s *UserService) SetRole(maxID string, role userpb.Role) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[maxID]
+	if !ok {
+		return fmt.Errorf("user %s not found", maxID)
+	}
+	user.Role = role
+	return nil
+}
+
+// Balance sums the user's operations.
+func (s *UserService) Balance(maxID string) int32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.balanceLocked(maxID)
+}
+
+func (s *UserService) balanceLocked(maxID string) int32 {
+	var balance int32
+	for _, op := range s.operations[maxID] {
+		if op.GetType() == userpb.BalanceOperationType_BALANCE_OPERATION_TYPE_WITHDRAW {
+			balance -= op.GetAmount()
+		} else {
+			balance += op.GetAmount()
+		}
+	}
+	return balance
+}
+
+func (s *UserService) CreateUser(_ context.Context, req *userpb.CreateUserRequest) (*userpb.CreateUserResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user := req.GetUser()
+	if user.GetMaxId() == "" {
+		return &userpb.CreateUserResponse{Error: userError(userpb.ErrorCode_ERROR_CODE_VALIDATION, "max_id is required")}, nil
+	}
+	if _, ok := s.users[user.GetMaxId()]; ok {
+		return &userpb.CreateUserResponse{Error: userError(userpb.ErrorCode_ERROR_CODE_ALREADY_EXISTS, "user %s already exists", user.GetMaxId())}, nil
+	}
+
+	stored := proto.Clone(user).(*userpb.User)
+	if stored.GetRole() == userpb.Role_ROLE_UNSPECIFIED {
+		stored.Role = userpb.Role_ROLE_USER
+	}
+	if stored.GetStatus() == userpb.Status_STATUS_UNSPECIFIED {
+		stored.Status = userpb.Status_STATUS_ACTIVE
+	}
+	s.users[stored.GetMaxId()] = stored
+
+	return &userpb.CreateUserResponse{User: proto.Clone(stored).(*userpb.User)}, nil
+}
+
+func (s *UserService) GetUsers(_ context.Context, req *userpb.GetUsersRequest) (*userpb.GetUsersResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var users []*userpb.User
+	for _, user := range s.users {
+		if req.GetMaxId() != "" && user.GetMaxId() != req.GetMaxId() {
+			continue
+		}
+		if req.GetStatus() != userpb.Status_STATUS_UNSPECIFIED && user.GetStatus() != req.GetStatus() {
+			continue
+		}
+		if req.GetRole() != userpb.Role_ROLE_UNSPECIFIED && user.GetRole() != req.GetRole() {
+			continue
+		}
+		users = append(users, proto.Clone(user).(*userpb.User))
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].GetMaxId() < users[j].GetMaxId() })
+
+	total := int32(len(users))
+	return &userpb.GetUsersResponse{Users: page(users, req.GetOffset(), req.GetLimit()), Total: total}, nil
+}
+
+func (s *UserService) GetUserByMaxID(_ context.Context, req *userpb.GetUserByMaxIDRequest) (*userpb.GetUserByMaxIDResponse, error) {
+	if user := s.User(req.GetMaxId()); user != nil {
+		return &userpb.GetUserByMaxIDResponse{User: user}, nil
+	}
+	return &userpb.GetUserByMaxIDResponse{Error: userError(userpb.ErrorCode_ERROR_CODE_NOT_FOUND, "user %s not found", req.GetMaxId())}, nil
+}
+
+func (s *UserService) UpdateUser(_ context.Context, req *userpb.UpdateUserRequest) (*userpb.UpdateUserResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user := req.GetUser()
+	stored, ok := s.users[user.GetMaxId()]
+	if !ok {
+		return &userpb.UpdateUserResponse{Error: userError(userpb.ErrorCode_ERROR_CODE_NOT_FOUND, "user %s not found", user.GetMaxId())}, nil
+	}
+
+	// The bot sends partial users, so only the fields that are set change.
+	proto.Merge(stored, user)
+	return &userpb.UpdateUserResponse{User: proto.Clone(stored).(*userpb.User)}, nil
+}
+
+func (s *UserService) DeleteUser(_ context.Context, req *userpb.DeleteUserRequest) (*userpb.DeleteUserResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[req.GetMaxId()]; !ok {
+		return &userpb.DeleteUserResponse{Error: userError(userpb.ErrorCode_ERROR_CODE_NOT_FOUND, "user %s not found", req.GetMaxId())}, nil
+	}
+	delete(s.users, req.GetMaxId())
+	return &userpb.DeleteUserResponse{MaxId: req.GetMaxId()}, nil
+}
+
+var reputationGroups = []*userpb.ReputationGroup{
+	{Id: 1, Name: "Новичок", Description: "Первые добрые дела", Coefficient: 1, ReputationNeed: 0},
+	{Id: 2, Name: "Помощник", Description: "Уже помог не раз", Coefficient: 1.2, ReputationNeed: 100},
+}
+
+func (s *UserService) GetReputationGroups(context.Context, *userpb.GetReputationGroupsRequest) (*userpb.GetReputationGroupsResponse, error) {
+	groups := make([]*userpb.ReputationGroup, 0, len(reputationGroups))
+	for _, group := range reputationGroups {
+		groups = append(groups, proto.Clone(group).(*userpb.ReputationGroup))
+	}
+	return &userpb.GetReputationGroupsResponse{ReputationGroups: groups}, nil
+}
+
+func (s *UserService) GetReputationGroupByID(_ context.Context, req *userpb.GetReputationGroupByIDRequest) (*userpb.GetReputationGroupByIDResponse, error) {
+	for _, group := range reputationGroups {
+		if group.GetId() == req.GetId() {
+			return &userpb.GetReputationGroupByIDResponse{ReputationGroup: proto.Clone(group).(*userpb.ReputationGroup)}, nil
+		}
+	}
+	return &userpb.GetReputationGroupByIDResponse{Error: userError(userpb.ErrorCode_ERROR_CODE_NOT_FOUND, "reputation group %d not found", req.GetId())}, nil
+}
+
+func (s *UserService) GetBalance(_ context.Context, req *userpb.GetBalanceRequest) (*userpb.GetBalanceResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[req.GetMaxId()]; !ok {
+		return &userpb.GetBalanceResponse{Error: userError(userpb.ErrorCode_ERROR_CODE_NOT_FOUND, "user %s not found", req.GetMaxId())}, nil
+	}
+	return &userpb.GetBalanceResponse{Balance: s.balanceLocked(req.GetMaxId())}, nil
+}
+
+// GetBalanceOperations lists operations newest first.
+func (s *UserService) GetBalanceOperations(_ context.Context, req *userpb.GetBalanceOperationsRequest) (*userpb.GetBalanceOperationsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := s.operations[req.GetMaxId()]
+	ops := make([]*userpb.BalanceOperation, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		ops = append(ops, proto.Clone(stored[i]).(*userpb.BalanceOperation))
+	}
+
+	total := int32(len(ops))
+	return &userpb.GetBalanceOperationsResponse{Operations: page(ops, req.GetOffset(), req.GetLimit()), Total: total}, nil
+}
+
+func (s *UserService) CreateOperation(_ context.Context, req *userpb.CreateOperationRequest) (*userpb.CreateOperationResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[req.GetMaxId()]; !ok {
+		return &userpb.CreateOperationResponse{Error: userError(userpb.ErrorCode_ERROR_CODE_NOT_FOUND, "user %s not found", req.GetMaxId())}, nil
+	}
+	if req.GetAmount() <= 0 {
+		return &userpb.CreateOperationResponse{Error: userError(userpb.ErrorCode_ERROR_CODE_VALIDATION, "amount must be positive")}, nil
+	}
+	if req.GetType() == userpb.BalanceOperationType_BALANCE_OPERATION_TYPE_WITHDRAW && s.balanceLocked(req.GetMaxId()) < req.GetAmount() {
+		return &userpb.CreateOperationResponse{Error: userError(userpb.ErrorCode_ERROR_CODE_NOT_ENOUGH, "not enough balance")}, nil
+	}
+
+	s.nextOpID++
+	op := &userpb.BalanceOperation{
+		Id:          fmt.Sprintf("op-%d", s.nextOpID),
+		BalanceId:   req.GetMaxId(),
+		Amount:      req.GetAmount(),
+		Type:        req.GetType(),
+		Description: req.GetDescription(),
+		CreatedAt:   now(),
+	}
+	s.operations[req.GetMaxId()] = append(s.operations[req.GetMaxId()], op)
+
+	return &userpb.CreateOperationResponse{Operation: proto.Clone(op).(*userpb.BalanceOperation)}, nil
+}
+
+// CustomerService keeps customer profiles and feedback.
+type CustomerService struct {
+	customerpb.UnimplementedCustomerServiceServer
+
+	mu         sync.Mutex
+	customers  map[string]*customerpb.Customer
+	feedbacks  []*customerpb.Feedback
+	nextFeedID int
+}
+
+func newCustomerService() *CustomerService {
+	return &CustomerService{customers: make(map[string]*customerpb.Customer)}
+}
+
+func customerError(code customerpb.ErrorCode, format string, args ...any) *customerpb.Error {
+	return &customerpb.Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Customer returns a copy of the stored customer, or nil.
+func (s *CustomerService) Customer(maxID string) *customerpb.Customer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if customer, ok := s.customers[maxID]; ok {
+		return proto.Clone(customer).(*customerpb.Customer)
+	}
+	return nil
+}
+
+func (s *CustomerService) CreateCustomer(_ context.Context, req *customerpb.CreateCustomerRequest) (*customerpb.CreateCustomerResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	customer := req.GetCustomer()
+	if customer.GetMaxId() == "" {
+		return &customerpb.CreateCustomerResponse{Error: customerError(customerpb.ErrorCode_ERROR_CODE_VALIDATION, "max_id is required")}, nil
+	}
+	if _, ok := s.customers[customer.GetMaxId()]; ok {
+		return &customerpb.CreateCustomerResponse{Error: customerError(customerpb.ErrorCode_ERROR_CODE_ALREADY_EXISTS, "customer %s already exists", customer.GetMaxId())}, nil
+	}
+
+	stored := proto.Clone(customer).(*customerpb.Customer)
+	stored.CreatedAt = now()
+	stored.UpdatedAt = stored.CreatedAt
+	s.customers[stored.GetMaxId()] = stored
+
+	return &customerpb.CreateCustomerResponse{Customer: proto.Clone(stored).(*customerpb.Customer)}, nil
+}
+
+func (s *CustomerService) GetCustomers(_ context.Context, req *customerpb.GetCustomersRequest) (*customerpb.GetCustomersResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var customers []*customerpb.Customer
+	for _, customer := range s.customers {
+		if req.GetMaxId() != "" && customer.GetMaxId() != req.GetMaxId() {
+			continue
+		}
+		customers = append(customers, proto.Clone(customer).(*customerpb.Customer))
+	}
+	sort.Slice(customers, func(i, j int) bool { return customers[i].GetMaxId() < customers[j].GetMaxId() })
+
+	total := int32(len(customers))
+	return &customerpb.GetCustomersResponse{Customers: page(customers, req.GetOffset(), req.GetLimit()), Total: total}, nil
+}
+
+func (s *CustomerService) GetCustomerByMaxID(_ context.Context, req *customerpb.GetCustomerByMaxIDRequest) (*customerpb.GetCustomerByMaxIDResponse, error) {
+	if customer := s.Customer(req.GetMaxId()); customer != nil {
+		return &customerpb.GetCustomerByMaxIDResponse{Customer: customer}, nil
+	}
+	return &customerpb.GetCustomerByMaxIDResponse{Error: customerError(customerpb.ErrorCode_ERROR_CODE_NOT_FOUND, "customer %s not found", req.GetMaxId())}, nil
+}
+
+func (s *CustomerService) UpdateCustomer(_ context.Context, req *customerpb.UpdateCustomerRequest) (*customerpb.UpdateCustomerResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	customer := req.GetCustomer()
+	existing, ok := s.customers[customer.GetMaxId()]
+	if !ok {
+		return &customerpb.UpdateCustomerResponse{Error: customerError(customerpb.ErrorCode_ERROR_CODE_NOT_FOUND, "customer %s not found", customer.GetMaxId())}, nil
+	}
+
+	stored := proto.Clone(customer).(*customerpb.Customer)
+	stored.CreatedAt = existing.GetCreatedAt()
+	stored.UpdatedAt = now()
+	s.customers[stored.GetMaxId()] = stored
+
+	return &customerpb.UpdateCustomerResponse{Customer: proto.Clone(stored).(*customerpb.Customer)}, nil
+}
+
+func (s *CustomerService) DeleteCustomer(_ context.Context, req *customerpb.DeleteCustomerRequest) (*customerpb.DeleteCustomerResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.customers[req.GetMaxId()]; !ok {
+		return &customerpb.DeleteCustomerResponse{Error: customerError(customerpb.ErrorCode_ERROR_CODE_NOT_FOUND, "customer %s not found", req.GetMaxId())}, nil
+	}
+	delete(s.customers, req.GetMaxId())
+	return &customerpb.DeleteCustomerResponse{MaxId: req.GetMaxId()}, nil
+}
+
+func (s *CustomerService) CreateFeedback(_ context.Context, req *customerpb.CreateFeedbackRequest) (*customerpb.CreateFeedbackResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	feedback := proto.Clone(req.GetFeedback()).(*customerpb.Feedback)
+	for _, existing := range s.feedbacks {
+		if existing.GetTaskId() == feedback.GetTaskId() && existing.GetUserId() == feedback.GetUserId() {
+			return &customerpb.CreateFeedbackResponse{Error: customerError(customerpb.ErrorCode_ERROR_CODE_ALREADY_EXISTS, "feedback already exists")}, nil
+		}
+	}
+
+	s.nextFeedID++
+	feedback.Id = fmt.Sprintf("feedback-%d", s.nextFeedID)
+	feedback.CreatedAt = now()
+	feedback.UpdatedAt = feedback.CreatedAt
+	s.feedbacks = append(s.feedbacks, feedback)
+
+	return &customerpb.CreateFeedbackResponse{Feedback: proto.Clone(feedback).(*customerpb.Feedback)}, nil
+}
+
+func (s *CustomerService) matchFeedbacks(taskID, userID string) []*customerpb.Feedback {
+	var result []*customerpb.Feedback
+	for _, feedback := range s.feedbacks {
+		if taskID != "" && feedback.GetTaskId() != taskID {
+			continue
+		}
+		if userID != "" && feedback.GetUserId() != userID {
+			continue
+		}
+		result = append(result, proto.Clone(feedback).(*customerpb.Feedback))
+	}
+	return result
+}
+
+func (s *CustomerService) GetFeedbacks(_ context.Context, req *customerpb.GetFeedbacksRequest) (*customerpb.GetFeedbacksResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	feedbacks := s.matchFeedbacks(req.GetTaskId(), req.GetUserId())
+	total := int32(len(feedbacks))
+	return &customerpb.GetFeedbacksResponse{Feedbacks: page(feedbacks, req.GetOffset(), req.GetLimit()), Total: total}, nil
+}
+
+func (s *CustomerService) CountFeedbacks(_ context.Context, req *customerpb.CountFeedbacksRequest) (*customerpb.CountFeedbacksResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return &customerpb.CountFeedbacksResponse{Total: int32(len(s.matchFeedbacks(req.GetTaskId(), req.GetUserId())))}, nil
+}
+
+func (s *CustomerService) GetFeedbackByID(_ context.Context, req *customerpb.GetFeedbackByIDRequest) (*customerpb.GetFeedbackByIDResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, feedback := range s.feedbacks {
+		if feedback.GetId() == req.GetId() {
+			return &customerpb.GetFeedbackByIDResponse{Feedback: proto.Clone(feedback).(*customerpb.Feedback)}, nil
+		}
+	}
+	return &customerpb.GetFeedbackByIDResponse{Error: customerError(customerpb.ErrorCode_ERROR_CODE_NOT_FOUND, "feedback %s not found", req.GetId())}, nil
+}
+
+// TaskService keeps tasks in creation order with their volunteers in the
+// assignments meta entry.
+type TaskService struct {
+	taskpb.UnimplementedTaskServiceServer
+
+	mu     sync.Mutex
+	tasks  []*taskpb.Task
+	nextID int
+}
+
+func newTaskService() *TaskService {
+	return &TaskService{}
+}
+
+func taskError(code taskpb.ErrorCode, format string, args ...any) *taskpb.Error {
+	return &taskpb.Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Task returns a copy of the stored task, or nil.
+func (s *TaskService) Task(id string) *taskpb.Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if task := s.findLocked(id); task != nil {
+		return proto.Clone(task).(*taskpb.Task)
+	}
+	return nil
+}
+
+// Tasks returns copies of the customer's tasks, all of them when customerID
+// is empty.
+func (s *TaskService) Tasks(customerID string) []*taskpb.Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.listLocked(customerID)
+}
+
+// AssignmentStatus reports the volunteer's status on the task, empty when
+// they have not joined it.
+func (s *TaskService) AssignmentStatus(taskID, userID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task := s.findLocked(taskID)
+	if task == nil {
+		return ""
+	}
+	for _, assignment := range assignments(task) {
+		if assignment.UserID == userID {
+			return assignment.Status
+		}
+	}
+	return ""
+}
+
+func (s *TaskService) findLocked(id string) *taskpb.Task {
+	for _, task := range s.tasks {
+		if task.GetId() == id {
+			return task
+		}
+	}
+	return nil
+}
+
+func (s *TaskService) listLocked(customerID string) []*taskpb.Task {
+	var tasks []*taskpb.Task
+	for _, task := range s.tasks {
+		if customerID != "" && task.GetCustomerId() != customerID {
+			continue
+		}
+		tasks = append(tasks, proto.Clone(task).(*taskpb.Task))
+	}
+	return tasks
+}
+
+type assignment struct {
+	UserID string `json:"user_id"`
+	Status string `json:"status"`
+}
+
+func assignments(task *taskpb.Task) []assignment {
+	for _, meta := range task.GetMeta() {
+		if meta.GetKey() != assignmentsMetaKey {
+			continue
+		}
+		var result []assignment
+		if err := json.Unmarshal([]byte(meta.GetValue()), &result); err == nil {
+			return result
+		}
+	}
+	return nil
+}
+
+func setAssignments(task *taskpb.Task, list []assignment) {
+	meta := make([]*taskpb.Meta, 0, len(task.GetMeta())+1)
+	for _, entry := range task.GetMeta() {
+		if entry.GetKey() != assignmentsMetaKey {
+			meta = append(meta, entry)
+		}
+	}
+	if len(list) > 0 {
+		data, _ := json.Marshal(list)
+		meta = append(meta, &taskpb.Meta{Key: assignmentsMetaKey, Value: string(data)})
+	}
+	task.Meta = meta
+	task.UpdatedAt = now()
+}
+
+func (s *TaskService) CreateTask(_ context.Context, req *taskpb.CreateTaskRequest) (*taskpb.CreateTaskResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task := proto.Clone(req.GetTask()).(*taskpb.Task)
+	if task.GetCustomerId() == "" || strings.TrimSpace(task.GetName()) == "" {
+		return &taskpb.CreateTaskResponse{Error: taskError(taskpb.ErrorCode_ERROR_CODE_VALIDATION, "customer_id and name are required")}, nil
+	}
+
+	s.nextID++
+	task.Id = fmt.Sprintf("task-%d", s.nextID)
+	task.CreatedAt = now()
+	task.UpdatedAt = task.CreatedAt
+	s.tasks = append(s.tasks, task)
+
+	return &taskpb.CreateTaskResponse{Task: proto.Clone(task).(*taskpb.Task)}, nil
+}
+
+func (s *TaskService) GetTasks(_ context.Context, req *taskpb.GetTasksRequest) (*taskpb.GetTasksResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks := s.listLocked(req.GetCustomerId())
+	total := int32(len(tasks))
+	return &taskpb.GetTasksResponse{Tasks: page(tasks, req.GetOffset(), req.GetLimit()), Total: total}, nil
+}
+
+func (s *TaskService) GetTaskByID(_ context.Context, req *taskpb.GetTaskByIDRequest) (*taskpb.GetTaskByIDResponse, error) {
+	if task := s.Task(req.GetId()); task != nil {
+		return &taskpb.GetTaskByIDResponse{Task: task}, nil
+	}
+	return &taskpb.GetTaskByIDResponse{Error: taskError(taskpb.ErrorCode_ERROR_CODE_NOT_FOUND, "task %s not found", req.GetId())}, nil
+}
+
+// UpdateTask replaces the task's fields. Volunteers are kept unless the
+// update carries its own assignments entry.
+func (s *TaskService) UpdateTask(_ context.Context, req *taskpb.UpdateTaskRequest) (*taskpb.UpdateTaskResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing := s.findLocked(req.GetTask().GetId())
+	if existing == nil {
+		return &taskpb.UpdateTaskResponse{Error: taskError(taskpb.ErrorCode_ERROR_CODE_NOT_FOUND, "task %s not found", req.GetTask().GetId())}, nil
+	}
+
+	updated := proto.Clone(req.GetTask()).(*taskpb.Task)
+	if assignments(updated) == nil {
+		setAssignments(updated, assignments(existing))
+	}
+	updated.CustomerId = existing.GetCustomerId()
+	updated.CreatedAt = existing.GetCreatedAt()
+	updated.UpdatedAt = now()
+	proto.Reset(existing)
+	proto.Merge(existing, updated)
+
+	return &taskpb.UpdateTaskResponse{Task: proto.Clone(existing).(*taskpb.Task)}, nil
+}
+
+func (s *TaskService) DeleteTask(_ context.Context, req *taskpb.DeleteTaskRequest) (*taskpb.DeleteTaskResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, task := range s.tasks {
+		if task.GetId() == req.GetId() {
+			s.tasks = append(s.tasks[:i], s.tasks[i+1:]...)
+			return &taskpb.DeleteTaskResponse{Id: req.GetId()}, nil
+		}
+	}
+	return &taskpb.DeleteTaskResponse{Error: taskError(taskpb.ErrorCode_ERROR_CODE_NOT_FOUND, "task %s not found", req.GetId())}, nil
+}
+
+// SearchTasks ignores the query and returns every task, which is enough for
+// the geo list to have something to filter.
+func (s *TaskService) SearchTasks(context.Context, *taskpb.SearchTasksRequest) (*taskpb.SearchTasksResponse, error) {
+	return &taskpb.SearchTasksResponse{Tasks: s.Tasks("")}, nil
+}
+
+func (s *TaskService) UserJoinTask(_ context.Context, req *taskpb.UserJoinTaskRequest) (*taskpb.UserJoinTaskResponse, error) {
+	err := s.updateAssignment(req.GetTaskId(), req.GetUserId(), func(task *taskpb.Task, current *assignment, list []assignment) ([]assignment, *taskpb.Error) {
+		if current != nil && current.Status != StatusRejected {
+			return nil, taskError(taskpb.ErrorCode_ERROR_CODE_ALREADY_EXISTS, "user %s already joined task %s", req.GetUserId(), req.GetTaskId())
+		}
+		if current != nil {
+			current.Status = StatusInProgress
+			return list, nil
+		}
+
+		active := 0
+		for _, item := range list {
+			if item.Status != StatusRejected {
+				active++
+			}
+		}
+		if task.GetMembersCount() > 0 && int32(active) >= task.GetMembersCount() {
+			return nil, taskError(taskpb.ErrorCode_ERROR_CODE_VALIDATION, "task %s has no free places", req.GetTaskId())
+		}
+		return append(list, assignment{UserID: req.GetUserId(), Status: StatusInProgress}), nil
+	})
+	return &taskpb.UserJoinTaskResponse{Error: err}, nil
+}
+
+func (s *TaskService) UserLeaveTask(_ context.Context, req *taskpb.UserLeaveTaskRequest) (*taskpb.UserLeaveTaskResponse, error) {
+	err := s.updateAssignment(req.GetTaskId(), req.GetUserId(), func(_ *taskpb.Task, current *assignment, list []assignment) ([]assignment, *taskpb.Error) {
+		if current == nil {
+			return nil, taskError(taskpb.ErrorCode_ERROR_CODE_NOT_FOUND, "user %s has not joined task %s", req.GetUserId(), req.GetTaskId())
+		}
+		result := list[:0]
+		for _, item := range list {
+			if item.UserID != req.GetUserId() {
+				result = append(result, item)
+			}
+		}
+		return result, nil
+	})
+	return &taskpb.UserLeaveTaskResponse{Error: err}, nil
+}
+
+func (s *TaskService) UserConfirmTask(_ context.Context, req *taskpb.UserConfirmTaskRequest) (*taskpb.UserConfirmTaskResponse, error) {
+	err := s.setStatus(req.GetTaskId(), req.GetUserId(), StatusConfirmed, StatusInProgress)
+	return &taskpb.UserConfirmTaskResponse{Error: err}, nil
+}
+
+func (s *TaskService) ApproveTask(_ context.Context, req *taskpb.ApproveTaskRequest) (*taskpb.ApproveTaskResponse, error) {
+	err := s.setStatus(req.GetTaskId(), req.GetUserId(), StatusCompleted, StatusInProgress, StatusConfirmed, StatusCompleted)
+	return &taskpb.ApproveTaskResponse{Error: err}, nil
+}
+
+func (s *TaskService) RejectTask(_ context.Context, req *taskpb.RejectTaskRequest) (*taskpb.RejectTaskResponse, error) {
+	err := s.setStatus(req.GetTaskId(), req.GetUserId(), StatusRejected)
+	return &taskpb.RejectTaskResponse{Error: err}, nil
+}
+
+// setStatus moves the volunteer to status, provided they are currently in
+// one of from; an empty from allows any status.
+func (s *TaskService) setStatus(taskID, userID, status string, from ...string) *taskpb.Error {
+	return s.updateAssignment(taskID, userID, func(_ *taskpb.Task, current *assignment, list []assignment) ([]assignment, *taskpb.Error) {
+		if current == nil {
+			return nil, taskError(taskpb.ErrorCode_ERROR_CODE_NOT_FOUND, "user %s has not joined task %s", userID, taskID)
+		}
+		if len(from) > 0 && !slices.Contains(from, current.Status) {
+			return nil, taskError(taskpb.ErrorCode_ERROR_CODE_VALIDATION, "cannot move user %s from %q to %q", userID, current.Status, status)
+		}
+		current.Status = status
+		return list, nil
+	})
+}
+
+func (s *TaskService) updateAssignment(taskID, userID string, update func(task *taskpb.Task, current *assignment, list []assignment) ([]assignment, *taskpb.Error)) *taskpb.Error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task := s.findLocked(taskID)
+	if task == nil {
+		return taskError(taskpb.ErrorCode_ERROR_CODE_NOT_FOUND, "task %s not found", taskID)
+	}
+
+	list := assignments(task)
+	var current *assignment
+	for i := range list {
+		if list[i].UserID == userID {
+			current = &list[i]
+			break
+		}
+	}
+
+	list, err := update(task, current, list)
+	if err != nil {
+		return err
+	}
+	setAssignments(task, list)
+	return nil
+}
+
+func page[T any](items []T, offset, limit int32) []T {
+	if offset < 0 {
+		offset = 0
+	}
+	if int(offset) >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit > 0 && int(limit) < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
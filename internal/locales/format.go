@@ -0,0 +1,200 @@
+package locales
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Formatter writes numbers, dates and plurals the way one language does and
+// fills locale texts that use named placeholders, e.g.
+//
+//	Награда: {{number .Amount}} {{plural .Amount "добрик" "добрика" "добриков"}}
+//
+// plural takes one form per CLDR plural category of the text's language, in
+// CLDR order: one, few, many for Russian, one, other for English. Keys a
+// translation leaves out fall back to Russian, so when the number of forms
+// does not fit the language, the text is taken to be Russian.
+type Formatter struct {
+	lang   string
+	format localeFormat
+}
+
+// localeFormat is what a language needs from CLDR: the digit group
+// separator, a date and time layout and the plural rule.
+type localeFormat struct {
+	group  string
+	date   string
+	plural pluralRule
+}
+
+// pluralRule picks the CLDR category of an integer as an index into the
+// forms of that language.
+type pluralRule struct {
+	forms int
+	pick  func(n int64) int
+}
+
+var (
+	// pluralRussian is one (1, 21, 101), few (2-4, 22-24) and many (0,
+	// 5-20, 25-30).
+	pluralRussian = pluralRule{forms: 3, pick: func(n int64) int {
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return 0
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return 1
+		default:
+			return 2
+		}
+	}}
+
+	pluralEnglish = pluralRule{forms: 2, pick: func(n int64) int {
+		if n == 1 {
+			return 0
+		}
+		return 1
+	}}
+
+	// pluralOther is for languages that do not inflect nouns after
+	// numerals, such as Tatar and Bashkir.
+	pluralOther = pluralRule{forms: 1, pick: func(int64) int { return 0 }}
+)
+
+var localeFormats = map[string]localeFormat{
+	"ru": {group: "\u00a0", date: "02.01.2006 15:04", plural: pluralRussian},
+	"en": {group: ",", date: "Jan 2, 2006, 3:04 PM", plural: pluralEnglish},
+	"tt": {group: "\u00a0", date: "02.01.2006 15:04", plural: pluralOther},
+	"ba": {group: "\u00a0", date: "02.01.2006 15:04", plural: pluralOther},
+}
+
+// FormatterFor returns the formatter for lang, or the Russian one for a
+// language without its own rules.
+func FormatterFor(lang string) Formatter {
+	format, ok := localeFormats[lang]
+	if !ok {
+		lang, format = DefaultLanguage, localeFormats[DefaultLanguage]
+	}
+	return Formatter{lang: lang, format: format}
+}
+
+// Number writes n with digit groups, e.g. "12 345" in Russian.
+func (f Formatter) Number(n int64) string {
+	digits := strconv.FormatInt(n, 10)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	if len(digits) <= 3 {
+		return sign + digits
+	}
+
+	var builder strings.Builder
+	builder.WriteString(sign)
+	head := len(digits) % 3
+	if head > 0 {
+		builder.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if i > 0 {
+			builder.WriteString(f.format.group)
+		}
+		builder.WriteString(digits[i : i+3])
+	}
+	return builder.String()
+}
+
+// Date writes t as a date and time, in t's location.
+func (f Formatter) Date(t time.Time) string {
+	return t.Format(f.format.date)
+}
+
+// Plural picks the form of forms that goes with n.
+func (f Formatter) Plural(n int64, forms ...string) string {
+	if len(forms) == 0 {
+		return ""
+	}
+	rule := f.format.plural
+	if len(forms) != rule.forms {
+		rule = localeFormats[DefaultLanguage].plural
+	}
+	if n < 0 {
+		n = -n
+	}
+	idx := rule.pick(n)
+	if idx >= len(forms) {
+		idx = len(forms) - 1
+	}
+	return forms[idx]
+}
+
+// PluralForms is how many forms plural takes in lang.
+func PluralForms(lang string) int {
+	return FormatterFor(lang).format.plural.forms
+}
+
+// Render fills text with data. Texts are parsed once per language and
+// cached.
+func (f Formatter) Render(text string, data any) (string, error) {
+	tmpl, err := f.template(text)
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+	if err := tmpl.Execute(&builder, data); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+type templateKey struct {
+	lang string
+	text string
+}
+
+var templates sync.Map
+
+func (f Formatter) template(text string) (*template.Template, error) {
+	key := templateKey{lang: f.lang, text: text}
+	if cached, ok := templates.Load(key); ok {
+		return cached.(*template.Template), nil
+	}
+
+	tmpl, err := template.New(f.lang).Option("missingkey=error").Funcs(f.funcs()).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	templates.Store(key, tmpl)
+	return tmpl, nil
+}
+
+func (f Formatter) funcs() template.FuncMap {
+	return template.FuncMap{
+		"number": func(v any) (string, error) {
+			n, err := toInt(v)
+			return f.Number(n), err
+		},
+		"plural": func(v any, forms ...string) (string, error) {
+			n, err := toInt(v)
+			return f.Plural(n, forms...), err
+		},
+		"date": f.Date,
+	}
+}
+
+func toInt(v any) (int64, error) {
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(value.Uint()), nil
+	default:
+		return 0, fmt.Errorf("want an integer, got %T", v)
+	}
+}
@@ -0,0 +1,71 @@
+package locales
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// LoadOverrides returns the embedded bundles with the files in dir laid on
+// top, so wording can change without a rebuild. A file is named after its
+// language, e.g. ru.json, and only needs the keys it changes; a Russian
+// override also reaches every language that takes the key from Russian.
+//
+// Each file is checked like the embedded ones before anything is merged.
+// When there are problems no bundles are returned, so the caller keeps the
+// texts it has. The error is for a directory that cannot be read.
+func LoadOverrides(dir string) (map[string]Messages, []Problem, error) {
+	once.Do(load)
+
+	fsys := os.DirFS(dir)
+	names, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+
+	overrides := make(map[string][]byte, len(names))
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		overrides[strings.TrimSuffix(name, ".json")] = data
+	}
+
+	var problems []Problem
+	overlay := func(lang string, base Messages) Messages {
+		data, ok := overrides[lang]
+		if !ok {
+			return base
+		}
+		found, messages := validateBundle(lang, data, base, false)
+		problems = append(problems, found...)
+		return mergeMessages(base, messages)
+	}
+
+	base := overlay(DefaultLanguage, bundles[DefaultLanguage])
+	all := map[string]Messages{DefaultLanguage: base}
+	for _, lang := range languages {
+		if lang == DefaultLanguage {
+			continue
+		}
+		embedded, err := readBundle(lang)
+		if err != nil {
+			continue
+		}
+		all[lang] = overlay(lang, mergeMessages(base, embedded))
+	}
+
+	for _, name := range names {
+		lang := strings.TrimSuffix(name, ".json")
+		if _, ok := all[lang]; !ok {
+			problems = append(problems, Problem{Language: lang, Message: "no embedded bundle for this language"})
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, problems, nil
+	}
+	return all, nil, nil
+}
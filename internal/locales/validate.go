@@ -0,0 +1,327 @@
+package locales
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"text/template/parse"
+	"unicode"
+)
+
+// Problem is one thing wrong with a locale file.
+type Problem struct {
+	Language string
+	Key      string
+	Message  string
+}
+
+func (p Problem) String() string {
+	if p.Key == "" {
+		return fmt.Sprintf("%s.json: %s", p.Language, p.Message)
+	}
+	return fmt.Sprintf("%s.json: %s: %s", p.Language, p.Key, p.Message)
+}
+
+// Validate checks the embedded locale files and returns what it finds, or
+// nil when they are fine. ru.json must have every key; other languages may
+// leave keys out and fall back to Russian. Every file is checked for
+// unknown keys, values of the wrong type, printf verbs and named
+// placeholders that differ from the text they replace, plural forms that do
+// not fit the language and unbalanced markdown.
+func Validate() []Problem {
+	return validate(files)
+}
+
+func validate(fsys fs.FS) []Problem {
+	var problems []Problem
+
+	base := defaultMessages()
+	if data, err := fs.ReadFile(fsys, DefaultLanguage+".json"); err != nil {
+		problems = append(problems, Problem{Language: DefaultLanguage, Message: err.Error()})
+	} else {
+		found, overrides := validateBundle(DefaultLanguage, data, base, true)
+		problems = append(problems, found...)
+		base = mergeMessages(base, overrides)
+	}
+
+	names, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return append(problems, Problem{Language: "*", Message: err.Error()})
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		lang := strings.TrimSuffix(name, ".json")
+		if lang == DefaultLanguage {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			problems = append(problems, Problem{Language: lang, Message: err.Error()})
+			continue
+		}
+		found, _ := validateBundle(lang, data, base, false)
+		problems = append(problems, found...)
+	}
+	return problems
+}
+
+// validateBundle checks one file against reference, the bundle its values
+// replace, and returns the problems along with the values it could read.
+// complete requires the file to set every key.
+func validateBundle(lang string, data []byte, reference Messages, complete bool) ([]Problem, Messages) {
+	var (
+		problems  []Problem
+		overrides Messages
+	)
+	report := func(key, format string, args ...any) {
+		problems = append(problems, Problem{Language: lang, Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		report("", "invalid JSON: %v", err)
+		return problems, overrides
+	}
+
+	known := make(map[string]bool, len(messageFields))
+	dst := reflect.ValueOf(&overrides).Elem()
+	ref := reflect.ValueOf(reference)
+
+	for _, field := range messageFields {
+		known[field.key] = true
+
+		value, ok := raw[field.key]
+		if !ok {
+			if complete {
+				report(field.key, "missing")
+			}
+			continue
+		}
+		if err := json.Unmarshal(value, dst.Field(field.index).Addr().Interface()); err != nil {
+			kind := "a string"
+			if field.list {
+				kind = "a list of strings"
+			}
+			report(field.key, "want %s", kind)
+			continue
+		}
+
+		if field.list {
+			items := dst.Field(field.index).Interface().([]string)
+			want := ref.Field(field.index).Len()
+			if want > 0 && len(items) != want {
+				report(field.key, "has %d items, want %d", len(items), want)
+			}
+			for i, item := range items {
+				if msg := checkMarkdown(item); msg != "" {
+					report(fmt.Sprintf("%s[%d]", field.key, i), "%s", msg)
+				}
+			}
+			continue
+		}
+
+		text := dst.Field(field.index).String()
+		if want := ref.Field(field.index).String(); want != "" && text != "" {
+			if got, expected := printfVerbs(text), printfVerbs(want); !slices.Equal(got, expected) {
+				report(field.key, "printf verbs %s, want %s", formatList(got), formatList(expected))
+			}
+		}
+		for _, msg := range checkTemplate(lang, text, ref.Field(field.index).String()) {
+			report(field.key, "%s", msg)
+		}
+		if msg := checkMarkdown(text); msg != "" {
+			report(field.key, "%s", msg)
+		}
+	}
+
+	var unknown []string
+	for key := range raw {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		report(key, "unknown key")
+	}
+
+	return problems, overrides
+}
+
+// printfVerbs returns the verb each argument of format is printed with, in
+// argument order, so "%[2]s %[1]d" and "%d %s" compare equal.
+func printfVerbs(format string) []string {
+	var verbs []string
+	arg := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		for i < len(format) && strings.IndexByte("+-# 0", format[i]) >= 0 {
+			i++
+		}
+		if i < len(format) && format[i] == '[' {
+			end := strings.IndexByte(format[i:], ']')
+			if end < 0 {
+				break
+			}
+			var n int
+			if _, err := fmt.Sscanf(format[i+1:i+end], "%d", &n); err == nil && n > 0 {
+				arg = n - 1
+			}
+			i += end + 1
+		}
+		for i < len(format) && (format[i] >= '0' && format[i] <= '9' || format[i] == '.') {
+			i++
+		}
+		if i >= len(format) || format[i] == '%' {
+			continue
+		}
+
+		for len(verbs) <= arg {
+			verbs = append(verbs, "")
+		}
+		verbs[arg] = "%" + string(format[i])
+		arg++
+	}
+	return verbs
+}
+
+func formatList(verbs []string) string {
+	if len(verbs) == 0 {
+		return "none"
+	}
+	return strings.Join(verbs, " ")
+}
+
+// checkTemplate compares the named placeholders of text with those of want,
+// the text it replaces, and checks that every plural call has as many forms
+// as lang has plural categories.
+func checkTemplate(lang, text, want string) []string {
+	got, err := inspectTemplate(text)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var problems []string
+	if expected, err := inspectTemplate(want); err == nil && want != "" && text != "" && !slices.Equal(got.fields, expected.fields) {
+		problems = append(problems, fmt.Sprintf("placeholders %s, want %s", formatList(got.fields), formatList(expected.fields)))
+	}
+	for _, forms := range got.plurals {
+		if want := PluralForms(lang); forms != want {
+			problems = append(problems, fmt.Sprintf("plural has %d forms, want %d", forms, want))
+		}
+	}
+	return problems
+}
+
+// templateUse is what a text does with named placeholders: the fields it
+// reads, sorted, and the number of forms of each plural call.
+type templateUse struct {
+	fields  []string
+	plurals []int
+}
+
+func inspectTemplate(text string) (templateUse, error) {
+	var use templateUse
+	if !strings.Contains(text, "{{") {
+		return use, nil
+	}
+
+	trees, err := parse.Parse("text", text, "", "", map[string]any(FormatterFor(DefaultLanguage).funcs()))
+	if err != nil {
+		return use, err
+	}
+	for _, tree := range trees {
+		use.walk(tree.Root)
+	}
+	sort.Strings(use.fields)
+	use.fields = slices.Compact(use.fields)
+	return use, nil
+}
+
+func (u *templateUse) walk(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			u.walk(child)
+		}
+	case *parse.ActionNode:
+		u.walk(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			u.walk(cmd)
+		}
+	case *parse.CommandNode:
+		if ident, ok := n.Args[0].(*parse.IdentifierNode); ok && ident.Ident == "plural" {
+			u.plurals = append(u.plurals, len(n.Args)-2)
+		}
+		for _, arg := range n.Args {
+			u.walk(arg)
+		}
+	case *parse.FieldNode:
+		u.fields = append(u.fields, "."+strings.Join(n.Ident, "."))
+	case *parse.IfNode:
+		u.walkBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		u.walkBranch(&n.BranchNode)
+	case *parse.WithNode:
+		u.walkBranch(&n.BranchNode)
+	}
+}
+
+func (u *templateUse) walkBranch(n *parse.BranchNode) {
+	u.walk(n.Pipe)
+	u.walk(n.List)
+	u.walk(n.ElseList)
+}
+
+// checkMarkdown describes the first unbalanced markdown marker in text, or
+// returns "" when there is none. An underscore inside a word, as in
+// @dobrika_support, is not a marker.
+func checkMarkdown(text string) string {
+	for _, marker := range []string{"~~", "++", "`"} {
+		if strings.Count(text, marker)%2 != 0 {
+			return fmt.Sprintf("unbalanced markdown: odd number of %s", marker)
+		}
+	}
+	if strings.Count(text, "*")%2 != 0 {
+		return "unbalanced markdown: odd number of *"
+	}
+
+	runes := []rune(text)
+	underscores := 0
+	for i, r := range runes {
+		if r != '_' {
+			continue
+		}
+		inWord := i > 0 && i < len(runes)-1 && isWordRune(runes[i-1]) && isWordRune(runes[i+1])
+		if !inWord {
+			underscores++
+		}
+	}
+	if underscores%2 != 0 {
+		return "unbalanced markdown: odd number of _"
+	}
+
+	if strings.Count(text, "[") != strings.Count(text, "]") {
+		return "unbalanced markdown: [ and ] do not match"
+	}
+	return ""
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,67 @@
+package locales
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// TestEmbeddedBundles fails on any problem in the locale files the bot ships
+// with, which it otherwise only logs at start.
+func TestEmbeddedBundles(t *testing.T) {
+	all, err := LoadAll()
+	if err != nil {
+		t.Fatalf("load bundles: %v", err)
+	}
+	for _, lang := range Languages() {
+		if _, ok := all[lang]; !ok {
+			t.Errorf("%s is offered but has no bundle", lang)
+		}
+	}
+
+	for _, problem := range Validate() {
+		t.Error(problem)
+	}
+}
+
+// TestValidateFindsProblems makes sure the check above would catch broken
+// files, so a silent validator cannot pass it.
+func TestValidateFindsProblems(t *testing.T) {
+	ru, err := files.ReadFile(DefaultLanguage + ".json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		en   string
+		want string
+	}{
+		{"invalid JSON", `{`, "invalid JSON"},
+		{"unknown key", `{"no_such_key": "x"}`, "no_such_key: unknown key"},
+		{"wrong type", `{"main_menu_text": ["x"]}`, "main_menu_text: want a string"},
+		{"list length", `{"main_menu_buttons": ["x"]}`, "main_menu_buttons: has 1 items"},
+		{"printf verbs", `{"admin_coins_deposit_prompt": "How many coins for %d?"}`, "admin_coins_deposit_prompt: printf verbs"},
+		{"placeholder", `{"customer_tasks_page_footer": "Page {{.Number}}"}`, "customer_tasks_page_footer: placeholders .Number, want .Page .Pages"},
+		{"plural forms", `{"customer_task_detail_reward": "{{plural .Amount \"coin\" \"coins\" \"x\"}}"}`, "customer_task_detail_reward: plural has 3 forms, want 2"},
+		{"markdown", `{"main_menu_text": "*bold"}`, "main_menu_text: unbalanced markdown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := validate(fstest.MapFS{
+				"ru.json": {Data: ru},
+				"en.json": {Data: []byte(tt.en)},
+			})
+
+			var found []string
+			for _, problem := range problems {
+				if problem.Language == "en" && strings.Contains(problem.String(), tt.want) {
+					return
+				}
+				found = append(found, problem.String())
+			}
+			t.Fatalf("no problem mentions %q, got %q", tt.want, found)
+		})
+	}
+}
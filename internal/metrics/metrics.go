@@ -0,0 +1,192 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+const namespace = "maxbot"
+
+const (
+	MenuEdit     = "edit"
+	MenuSend     = "send"
+	MenuFallback = "send_fallback"
+	MenuDeferred = "deferred"
+	MenuFailed   = "failed"
+)
+
+const (
+	OutboxDropFailed     = "failed"
+	OutboxDropAttempts   = "attempts"
+	OutboxDropExpired    = "expired"
+	OutboxDropOverflow   = "overflow"
+	OutboxDropSuperseded = "superseded"
+)
+
+const (
+	PayoutCredited   = "credited"
+	PayoutReconciled = "reconciled"
+	PayoutRetried    = "retried"
+	PayoutStuck      = "stuck"
+	PayoutReverted   = "reverted"
+)
+
+const (
+	LocaleReloadApplied  = "applied"
+	LocaleReloadRejected = "rejected"
+)
+
+var (
+	updatesProcessed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "updates_processed_total",
+		Help:      "Updates handled, by update type.",
+	}, []string{"type"})
+
+	callbackPayloads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "callback_payloads_total",
+		Help:      "Callback queries received, by payload prefix.",
+	}, []string{"prefix"})
+
+	handlerDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "handler_duration_seconds",
+		Help:      "Time spent handling a single update, by update type.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"type"})
+
+	grpcErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_errors_total",
+		Help:      "Failed gRPC calls, by method and kind (transport or service).",
+	}, []string{"method", "kind"})
+
+	callbacksRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "callbacks_rejected_total",
+		Help:      "Callback tokens rejected before dispatch, by reason.",
+	}, []string{"reason"})
+
+	menuRenders = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "menu_renders_total",
+		Help:      "Menu renders, by outcome: edit, send, send_fallback, deferred or failed.",
+	}, []string{"result"})
+
+	outboxDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "outbox_depth",
+		Help:      "Outbound messages waiting to be sent.",
+	})
+
+	outboxRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "outbox_retries_total",
+		Help:      "Outbound requests scheduled for a retry, by kind (send or edit).",
+	}, []string{"kind"})
+
+	outboxDrops = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "outbox_drops_total",
+		Help:      "Outbound messages given up on, by reason: failed, attempts, expired, overflow or superseded.",
+	}, []string{"reason"})
+
+	payouts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "payouts_total",
+		Help:      "Reward payout steps, by result: credited, reconciled, retried, stuck or reverted.",
+	}, []string{"result"})
+
+	localeReloads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "locale_reloads_total",
+		Help:      "Locale override reloads, by result: applied or rejected.",
+	}, []string{"result"})
+)
+
+func ObserveUpdate(updateType string, started time.Time) {
+	updatesProcessed.WithLabelValues(updateType).Inc()
+	handlerDuration.WithLabelValues(updateType).Observe(time.Since(started).Seconds())
+}
+
+// ObserveCallback counts a callback by the first two segments of its payload
+// ("volunteer:task:view:<id>" becomes "volunteer:task") so ids never end up
+// in label values.
+func ObserveCallback(payload string) {
+	callbackPayloads.WithLabelValues(CallbackPrefix(payload)).Inc()
+}
+
+func CallbackPrefix(payload string) string {
+	parts := strings.SplitN(strings.TrimSpace(payload), ":", 3)
+	switch len(parts) {
+	case 0:
+		return ""
+	case 1:
+		return parts[0]
+	default:
+		return parts[0] + ":" + parts[1]
+	}
+}
+
+func ObserveCallbackRejected(reason string) {
+	callbacksRejected.WithLabelValues(reason).Inc()
+}
+
+func ObserveMenuRender(result string) {
+	menuRenders.WithLabelValues(result).Inc()
+}
+
+func SetOutboxDepth(depth int) {
+	outboxDepth.Set(float64(depth))
+}
+
+func ObserveOutboxRetry(kind string) {
+	outboxRetries.WithLabelValues(kind).Inc()
+}
+
+func ObserveOutboxDrop(reason string) {
+	outboxDrops.WithLabelValues(reason).Inc()
+}
+
+func ObservePayout(result string) {
+	payouts.WithLabelValues(result).Inc()
+}
+
+func ObserveLocaleReload(result string) {
+	localeReloads.WithLabelValues(result).Inc()
+}
+
+// UnaryClientInterceptor counts transport failures as well as responses that
+// carry a service-level error in their "error" field.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if err != nil {
+			grpcErrors.WithLabelValues(method, "transport").Inc()
+			return err
+		}
+
+		if hasServiceError(reply) {
+			grpcErrors.WithLabelValues(method, "service").Inc()
+		}
+		return nil
+	}
+}
+
+func hasServiceError(reply any) bool {
+	msg, ok := reply.(proto.Message)
+	if !ok {
+		return false
+	}
+
+	m := msg.ProtoReflect()
+	field := m.Descriptor().Fields().ByName("error")
+	return field != nil && m.Has(field)
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// SessionStats reports the number of live entries per storage bucket.
+type SessionStats interface {
+	Stats() (map[string]int, error)
+}
+
+type sessionCollector struct {
+	stats SessionStats
+	desc  *prometheus.Desc
+}
+
+// RegisterSessionStore exposes active session counts per store, read from
+// the backend at scrape time.
+func RegisterSessionStore(stats SessionStats) error {
+	return prometheus.Register(&sessionCollector{
+		stats: stats,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "active_sessions"),
+			"Active (not expired) sessions, by store.",
+			[]string{"store"}, nil,
+		),
+	})
+}
+
+func (c *sessionCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *sessionCollector) Collect(ch chan<- prometheus.Metric) {
+	counts, err := c.stats.Stats()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+
+	for store, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), store)
+	}
+}
@@ -0,0 +1,540 @@
+// Package outbox delivers outbound bot messages in order, one chat at a time,
+// retrying failed requests with exponential backoff.
+//
+// Every queued message is written to a spool in the session store and removed
+// once it is delivered or given up on, so queued messages survive a restart
+// when the store is persistent. Spool writes happen outside the outbox lock,
+// so a slow store only holds up the chat being written. Messages for one chat leave in the
+// order they were queued; a message that keeps failing holds back the ones
+// behind it until it is delivered or dropped. A queued edit that has not been
+// sent yet is replaced by a newer edit of the same message.
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	mrand "math/rand/v2"
+	"strconv"
+	"sync"
+	"time"
+
+	"DobrikaDev/max-bot/internal/metrics"
+	"DobrikaDev/max-bot/internal/storage"
+
+	"go.uber.org/zap"
+)
+
+const (
+	bucketQueue = "outbox"
+	bucketIndex = "outbox_index"
+	indexKey    = "pending"
+
+	defaultBaseDelay      = time.Second
+	defaultMaxDelay       = 5 * time.Minute
+	defaultMaxAttempts    = 10
+	defaultMaxAge         = time.Hour
+	defaultChatLimit      = 100
+	defaultWait           = 5 * time.Second
+	defaultAttemptTimeout = 15 * time.Second
+
+	spoolStripes = 64
+)
+
+var (
+	// ErrDeferred means the message was not delivered yet but stays queued
+	// and will be retried.
+	ErrDeferred = errors.New("outbox: message deferred")
+	// ErrQueueFull means the chat already has too many queued messages.
+	ErrQueueFull = errors.New("outbox: chat queue is full")
+	// ErrClosed means the outbox is shut down.
+	ErrClosed = errors.New("outbox: closed")
+)
+
+// Kind is the request a message turns into.
+type Kind string
+
+const (
+	KindSend Kind = "send"
+	KindEdit Kind = "edit"
+)
+
+// Message is one queued request. Body is the JSON request body and is stored
+// as is. Tag is free-form and lets the caller recognise its own messages when
+// they are delivered after a retry.
+type Message struct {
+	ID        string          `json:"id"`
+	Kind      Kind            `json:"kind"`
+	ChatID    int64           `json:"chat_id"`
+	UserID    int64           `json:"user_id,omitempty"`
+	MessageID string          `json:"message_id,omitempty"`
+	Tag       string          `json:"tag,omitempty"`
+	Body      json.RawMessage `json:"body"`
+
+	CreatedAt   time.Time `json:"created_at"`
+	Attempts    int       `json:"attempts"`
+	NextAttempt time.Time `json:"next_attempt"`
+}
+
+// Transport performs the request and returns the id of the sent message.
+type Transport func(ctx context.Context, msg *Message) (string, error)
+
+// Classifier tells whether a failed request may be retried and how long the
+// server asked to wait before the next attempt, if it did.
+type Classifier func(err error) (retry bool, after time.Duration)
+
+// Options configure an Outbox. Zero values fall back to the defaults.
+type Options struct {
+	Transport Transport
+	Classify  Classifier
+	// Delivered is called for a message delivered after its sender stopped
+	// waiting, so the sender can still record the new message id.
+	Delivered func(msg *Message, messageID string)
+
+	BaseDelay      time.Duration
+	MaxDelay       time.Duration
+	MaxAttempts    int
+	MaxAge         time.Duration
+	ChatLimit      int
+	Wait           time.Duration
+	AttemptTimeout time.Duration
+}
+
+type result struct {
+	messageID string
+	err       error
+}
+
+type chatQueue struct {
+	pending []*Message
+	running bool
+	timer   *time.Timer
+}
+
+// Outbox is safe for concurrent use.
+type Outbox struct {
+	opts   Options
+	store  storage.Store
+	index  storage.Index
+	logger *zap.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	chats   map[int64]*chatQueue
+	waiters map[string]chan result
+	depth   int
+	closed  bool
+
+	// spool serialises the spool writes of a chat, striped by chat id.
+	// indexMu serialises updates of the shared index. Neither is taken
+	// while holding mu.
+	spool   [spoolStripes]sync.Mutex
+	indexMu sync.Mutex
+
+	now func() time.Time
+}
+
+// New builds an outbox spooling to store. Call Resume to pick up messages
+// left over from a previous run.
+func New(store storage.Store, opts Options, logger *zap.Logger) *Outbox {
+	if opts.BaseDelay <= 0 {
+		opts.BaseDelay = defaultBaseDelay
+	}
+	if opts.MaxDelay <= 0 {
+		opts.MaxDelay = defaultMaxDelay
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+	if opts.MaxAge <= 0 {
+		opts.MaxAge = defaultMaxAge
+	}
+	if opts.ChatLimit <= 0 {
+		opts.ChatLimit = defaultChatLimit
+	}
+	if opts.Wait <= 0 {
+		opts.Wait = defaultWait
+	}
+	if opts.AttemptTimeout <= 0 {
+		opts.AttemptTimeout = defaultAttemptTimeout
+	}
+	if opts.Classify == nil {
+		opts.Classify = func(error) (bool, time.Duration) { return false, 0 }
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Outbox{
+		opts:    opts,
+		store:   store,
+		index:   storage.NewIndex(store, bucketIndex, indexKey),
+		logger:  logger,
+		ctx:     ctx,
+		cancel:  cancel,
+		chats:   make(map[int64]*chatQueue),
+		waiters: make(map[string]chan result),
+		now:     time.Now,
+	}
+}
+
+// Send queues msg and waits until its first attempt finishes, the wait
+// limit passes or ctx is done. It returns the sent message id, the
+// permanent error that made the message drop, or an error wrapping
+// ErrDeferred when the message is still queued.
+func (o *Outbox) Send(ctx context.Context, msg *Message) (string, error) {
+	done := make(chan result, 1)
+
+	o.mu.Lock()
+	if o.closed {
+		o.mu.Unlock()
+		return "", ErrClosed
+	}
+
+	msg.ID = newID()
+	msg.CreatedAt = o.now()
+	msg.Attempts = 0
+	msg.NextAttempt = time.Time{}
+
+	queue := o.chat(msg.ChatID)
+	if !o.supersede(queue, msg) {
+		if len(queue.pending) >= o.opts.ChatLimit {
+			o.mu.Unlock()
+			metrics.ObserveOutboxDrop(metrics.OutboxDropOverflow)
+			return "", ErrQueueFull
+		}
+		queue.pending = append(queue.pending, msg)
+		o.setDepth(o.depth + 1)
+	}
+	o.waiters[msg.ID] = done
+	o.kick(msg.ChatID, queue)
+	o.mu.Unlock()
+
+	o.flush(msg.ChatID)
+
+	wait := time.NewTimer(o.opts.Wait)
+	defer wait.Stop()
+
+	select {
+	case res := <-done:
+		return res.messageID, res.err
+	case <-wait.C:
+	case <-ctx.Done():
+	}
+
+	o.mu.Lock()
+	delete(o.waiters, msg.ID)
+	o.mu.Unlock()
+
+	// The result may have arrived while the waiter was being removed.
+	select {
+	case res := <-done:
+		return res.messageID, res.err
+	default:
+		return "", fmt.Errorf("%w: still queued behind earlier messages", ErrDeferred)
+	}
+}
+
+// Resume schedules the messages spooled by a previous run.
+func (o *Outbox) Resume() {
+	for _, chatID := range o.loadIndex() {
+		pending := o.loadQueue(chatID)
+		if len(pending) == 0 {
+			o.updateIndex(chatID, false)
+			continue
+		}
+
+		o.mu.Lock()
+		queue := o.chat(chatID)
+		queue.pending = append(pending, queue.pending...)
+		o.setDepth(o.depth + len(pending))
+		o.kick(chatID, queue)
+		o.mu.Unlock()
+	}
+}
+
+// Close stops delivery and waits for in-flight requests to return. Queued
+// messages stay in the spool.
+func (o *Outbox) Close() {
+	o.mu.Lock()
+	o.closed = true
+	for _, queue := range o.chats {
+		if queue.timer != nil {
+			queue.timer.Stop()
+		}
+	}
+	o.mu.Unlock()
+
+	o.cancel()
+	o.wg.Wait()
+}
+
+// Depth is the number of queued messages across all chats.
+func (o *Outbox) Depth() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.depth
+}
+
+func (o *Outbox) chat(chatID int64) *chatQueue {
+	queue, ok := o.chats[chatID]
+	if !ok {
+		queue = &chatQueue{}
+		o.chats[chatID] = queue
+	}
+	return queue
+}
+
+// supersede replaces a queued, not yet attempted edit of the same message.
+// The head of the queue is left alone since it may be in flight.
+func (o *Outbox) supersede(queue *chatQueue, msg *Message) bool {
+	if msg.Kind != KindEdit || msg.MessageID == "" {
+		return false
+	}
+
+	for i := len(queue.pending) - 1; i >= 1; i-- {
+		old := queue.pending[i]
+		if old.Kind != KindEdit || old.MessageID != msg.MessageID {
+			continue
+		}
+
+		queue.pending[i] = msg
+		o.finish(old, result{err: fmt.Errorf("%w: replaced by a newer edit", ErrDeferred)})
+		metrics.ObserveOutboxDrop(metrics.OutboxDropSuperseded)
+		return true
+	}
+	return false
+}
+
+// kick starts the chat's worker unless it is running or waiting for a retry.
+func (o *Outbox) kick(chatID int64, queue *chatQueue) {
+	if o.closed || queue.running || len(queue.pending) == 0 {
+		return
+	}
+	if queue.timer != nil {
+		queue.timer.Stop()
+		queue.timer = nil
+	}
+
+	queue.running = true
+	o.wg.Add(1)
+	go o.drain(chatID)
+}
+
+// drain delivers the chat's messages in order until the queue is empty or
+// the head has to wait for a retry.
+func (o *Outbox) drain(chatID int64) {
+	defer o.wg.Done()
+
+	for {
+		o.mu.Lock()
+		queue := o.chats[chatID]
+		if o.closed || len(queue.pending) == 0 {
+			queue.running = false
+			if len(queue.pending) == 0 {
+				delete(o.chats, chatID)
+			}
+			o.mu.Unlock()
+			return
+		}
+
+		msg := queue.pending[0]
+		if wait := msg.NextAttempt.Sub(o.now()); wait > 0 {
+			queue.running = false
+			queue.timer = time.AfterFunc(wait, func() {
+				o.mu.Lock()
+				defer o.mu.Unlock()
+				if q, ok := o.chats[chatID]; ok {
+					q.timer = nil
+					o.kick(chatID, q)
+				}
+			})
+			o.mu.Unlock()
+			return
+		}
+		o.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(o.ctx, o.opts.AttemptTimeout)
+		messageID, err := o.opts.Transport(ctx, msg)
+		cancel()
+
+		o.mu.Lock()
+		if err != nil && o.ctx.Err() != nil {
+			// Shutting down: keep the message for the next run.
+			queue.running = false
+			o.mu.Unlock()
+			return
+		}
+		o.settle(chatID, queue, msg, messageID, err)
+		o.mu.Unlock()
+
+		o.flush(chatID)
+	}
+}
+
+// settle records the outcome of an attempt at the head of the queue.
+func (o *Outbox) settle(chatID int64, queue *chatQueue, msg *Message, messageID string, err error) {
+	if err == nil {
+		o.pop(queue)
+		if !o.finish(msg, result{messageID: messageID}) && o.opts.Delivered != nil {
+			o.opts.Delivered(msg, messageID)
+		}
+		return
+	}
+
+	msg.Attempts++
+	retry, after := o.opts.Classify(err)
+	logger := o.logger.With(zap.Error(err), zap.Int64("chat_id", chatID), zap.String("kind", string(msg.Kind)), zap.Int("attempts", msg.Attempts))
+
+	var reason string
+	switch {
+	case !retry:
+		reason = metrics.OutboxDropFailed
+	case msg.Attempts >= o.opts.MaxAttempts:
+		reason = metrics.OutboxDropAttempts
+	case o.now().Sub(msg.CreatedAt) >= o.opts.MaxAge:
+		reason = metrics.OutboxDropExpired
+	}
+	if reason != "" {
+		logger.Warn("dropping outbound message", zap.String("reason", reason))
+		metrics.ObserveOutboxDrop(reason)
+		o.pop(queue)
+		o.finish(msg, result{err: err})
+		return
+	}
+
+	delay := max(o.backoff(msg.Attempts), after)
+	msg.NextAttempt = o.now().Add(delay)
+	logger.Info("outbound message deferred", zap.Duration("retry_in", delay))
+	metrics.ObserveOutboxRetry(string(msg.Kind))
+	o.finish(msg, result{err: fmt.Errorf("%w: %w", ErrDeferred, err)})
+}
+
+// backoff doubles from BaseDelay up to MaxDelay, with ±20% jitter so chats
+// that failed together do not retry together.
+func (o *Outbox) backoff(attempts int) time.Duration {
+	delay := o.opts.BaseDelay
+	for i := 1; i < attempts && delay < o.opts.MaxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, o.opts.MaxDelay)
+
+	jitter := time.Duration(float64(delay) * 0.2 * (2*mrand.Float64() - 1))
+	return delay + jitter
+}
+
+func (o *Outbox) pop(queue *chatQueue) {
+	queue.pending[0] = nil
+	queue.pending = queue.pending[1:]
+	o.setDepth(o.depth - 1)
+}
+
+// finish hands the result to the sender if it is still waiting.
+func (o *Outbox) finish(msg *Message, res result) bool {
+	done, ok := o.waiters[msg.ID]
+	if !ok {
+		return false
+	}
+	delete(o.waiters, msg.ID)
+	done <- res
+	return true
+}
+
+func (o *Outbox) setDepth(depth int) {
+	o.depth = depth
+	metrics.SetOutboxDepth(depth)
+}
+
+// flush writes the chat's current queue to the spool. It must be called
+// without holding mu, after every change to the queue. The queue is encoded
+// under mu once the chat's spool lock is held, so the last write always
+// carries the latest state. A store failure is logged and delivery goes on
+// from memory.
+func (o *Outbox) flush(chatID int64) {
+	lock := &o.spool[uint64(chatID)%spoolStripes]
+	lock.Lock()
+	defer lock.Unlock()
+
+	var (
+		data []byte
+		err  error
+	)
+	o.mu.Lock()
+	queue, ok := o.chats[chatID]
+	if ok && len(queue.pending) > 0 {
+		data, err = json.Marshal(queue.pending)
+	}
+	o.mu.Unlock()
+
+	key := strconv.FormatInt(chatID, 10)
+	if err != nil {
+		o.logger.Error("failed to encode outbox spool", zap.Error(err), zap.Int64("chat_id", chatID))
+		return
+	}
+	if data == nil {
+		if err := o.store.Delete(bucketQueue, key); err != nil {
+			o.logger.Warn("failed to clear outbox spool", zap.Error(err), zap.Int64("chat_id", chatID))
+		}
+		o.updateIndex(chatID, false)
+		return
+	}
+	if err := o.store.Set(bucketQueue, key, data, 0); err != nil {
+		o.logger.Warn("failed to write outbox spool", zap.Error(err), zap.Int64("chat_id", chatID))
+		return
+	}
+	o.updateIndex(chatID, true)
+}
+
+func (o *Outbox) loadQueue(chatID int64) []*Message {
+	data, ok, err := o.store.Get(bucketQueue, strconv.FormatInt(chatID, 10))
+	if err != nil || !ok {
+		if err != nil {
+			o.logger.Warn("failed to read outbox spool", zap.Error(err), zap.Int64("chat_id", chatID))
+		}
+		return nil
+	}
+
+	var pending []*Message
+	if err := json.Unmarshal(data, &pending); err != nil {
+		o.logger.Warn("failed to decode outbox spool", zap.Error(err), zap.Int64("chat_id", chatID))
+		return nil
+	}
+	return pending
+}
+
+// The index lists chats with spooled messages, since the store cannot
+// enumerate a bucket.
+func (o *Outbox) loadIndex() []int64 {
+	keys, err := o.index.List()
+	if err != nil {
+		o.logger.Warn("failed to read outbox index", zap.Error(err))
+		return nil
+	}
+
+	ids := make([]int64, 0, len(keys))
+	for _, key := range keys {
+		if id, err := strconv.ParseInt(key, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (o *Outbox) updateIndex(chatID int64, pending bool) {
+	o.indexMu.Lock()
+	defer o.indexMu.Unlock()
+
+	if err := o.index.Set(strconv.FormatInt(chatID, 10), pending); err != nil {
+		o.logger.Warn("failed to write outbox index", zap.Error(err))
+	}
+}
+
+func newID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
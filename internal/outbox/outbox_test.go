@@ -0,0 +1,380 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"DobrikaDev/max-bot/internal/storage"
+
+	"go.uber.org/zap"
+)
+
+var (
+	errTemporary   = errors.New("temporary failure")
+	errRateLimited = errors.New("rate limited")
+)
+
+// transport records the tags of attempted messages. Messages tagged with a
+// key of hold block until the channel is closed; fail decides the outcome of
+// each attempt.
+type transport struct {
+	mu       sync.Mutex
+	attempts []attempt
+	hold     map[string]chan struct{}
+	fail     func(tag string, n int) error
+}
+
+type attempt struct {
+	chatID int64
+	tag    string
+	at     time.Time
+}
+
+func (tr *transport) send(ctx context.Context, msg *Message) (string, error) {
+	tr.mu.Lock()
+	n := 0
+	for _, a := range tr.attempts {
+		if a.tag == msg.Tag {
+			n++
+		}
+	}
+	tr.attempts = append(tr.attempts, attempt{chatID: msg.ChatID, tag: msg.Tag, at: time.Now()})
+	gate := tr.hold[msg.Tag]
+	tr.mu.Unlock()
+
+	if gate != nil {
+		select {
+		case <-gate:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	if tr.fail != nil {
+		if err := tr.fail(msg.Tag, n); err != nil {
+			return "", err
+		}
+	}
+	return "id-" + msg.Tag, nil
+}
+
+func (tr *transport) tags(chatID int64) []string {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	var tags []string
+	for _, a := range tr.attempts {
+		if a.chatID == chatID {
+			tags = append(tags, a.tag)
+		}
+	}
+	return tags
+}
+
+func (tr *transport) times(tag string) []time.Time {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	var times []time.Time
+	for _, a := range tr.attempts {
+		if a.tag == tag {
+			times = append(times, a.at)
+		}
+	}
+	return times
+}
+
+// delivered collects the messages delivered after their sender stopped
+// waiting.
+type delivered struct {
+	mu   sync.Mutex
+	tags []string
+}
+
+func (d *delivered) record(msg *Message, _ string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.tags = append(d.tags, msg.Tag)
+}
+
+func (d *delivered) list() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return slices.Clone(d.tags)
+}
+
+func newTestOutbox(t *testing.T, store storage.Store, opts Options) *Outbox {
+	t.Helper()
+
+	o := New(store, opts, zap.NewNop())
+	t.Cleanup(o.Close)
+	return o
+}
+
+func message(chatID int64, tag string) *Message {
+	return &Message{Kind: KindSend, ChatID: chatID, Tag: tag, Body: []byte(`{}`)}
+}
+
+func edit(chatID int64, messageID, tag string) *Message {
+	return &Message{Kind: KindEdit, ChatID: chatID, MessageID: messageID, Tag: tag, Body: []byte(`{}`)}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func spooled(t *testing.T, store storage.Store) []string {
+	t.Helper()
+
+	keys, err := storage.NewIndex(store, bucketIndex, indexKey).List()
+	if err != nil {
+		t.Fatalf("list index: %v", err)
+	}
+	return keys
+}
+
+func TestChatOrder(t *testing.T) {
+	store := storage.NewMemoryStore(0)
+	defer store.Close()
+
+	gate := make(chan struct{})
+	tr := &transport{hold: map[string]chan struct{}{"first": gate}}
+	late := &delivered{}
+	o := newTestOutbox(t, store, Options{Transport: tr.send, Delivered: late.record, Wait: 20 * time.Millisecond})
+	ctx := context.Background()
+
+	for _, tag := range []string{"first", "second", "third"} {
+		if _, err := o.Send(ctx, message(1, tag)); !errors.Is(err, ErrDeferred) {
+			t.Fatalf("send %s: got %v, want ErrDeferred", tag, err)
+		}
+	}
+	if got := o.Depth(); got != 3 {
+		t.Fatalf("depth: got %d, want 3", got)
+	}
+	if got := spooled(t, store); !slices.Equal(got, []string{"1"}) {
+		t.Fatalf("spooled chats: got %v, want [1]", got)
+	}
+
+	// Another chat is not held back by the blocked one.
+	id, err := o.Send(ctx, message(2, "other"))
+	if err != nil || id != "id-other" {
+		t.Fatalf("send to other chat: got %q, %v", id, err)
+	}
+
+	close(gate)
+	waitFor(t, "queued messages", func() bool { return len(late.list()) == 3 })
+
+	want := []string{"first", "second", "third"}
+	if got := tr.tags(1); !slices.Equal(got, want) {
+		t.Fatalf("attempts: got %v, want %v", got, want)
+	}
+	if got := late.list(); !slices.Equal(got, want) {
+		t.Fatalf("delivered: got %v, want %v", got, want)
+	}
+	waitFor(t, "empty spool", func() bool { return len(spooled(t, store)) == 0 })
+	if _, ok, _ := store.Get(bucketQueue, "1"); ok {
+		t.Fatal("spool of chat 1 is still stored")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	o := newTestOutbox(t, storage.NewMemoryStore(0), Options{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second})
+
+	want := []time.Duration{100, 200, 400, 800, 1000, 1000}
+	for i, base := range want {
+		base *= time.Millisecond
+		for range 20 {
+			got := o.backoff(i + 1)
+			if got < base*8/10 || got > base*12/10 {
+				t.Fatalf("backoff(%d): got %v, want %v ±20%%", i+1, got, base)
+			}
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	store := storage.NewMemoryStore(0)
+	defer store.Close()
+
+	tr := &transport{fail: func(_ string, n int) error {
+		if n < 2 {
+			return errTemporary
+		}
+		return nil
+	}}
+	late := &delivered{}
+	o := newTestOutbox(t, store, Options{
+		Transport: tr.send,
+		Classify:  func(error) (bool, time.Duration) { return true, 0 },
+		Delivered: late.record,
+		BaseDelay: 40 * time.Millisecond,
+		Wait:      time.Second,
+	})
+
+	_, err := o.Send(context.Background(), message(1, "retried"))
+	if !errors.Is(err, ErrDeferred) || !errors.Is(err, errTemporary) {
+		t.Fatalf("send: got %v, want ErrDeferred wrapping the failure", err)
+	}
+	waitFor(t, "delivery", func() bool { return len(late.list()) == 1 })
+
+	times := tr.times("retried")
+	if len(times) != 3 {
+		t.Fatalf("attempts: got %d, want 3", len(times))
+	}
+	// Backoff doubles: about 40ms, then about 80ms, less the jitter.
+	if gap := times[1].Sub(times[0]); gap < 32*time.Millisecond {
+		t.Fatalf("first retry after %v, want at least 32ms", gap)
+	}
+	if gap := times[2].Sub(times[1]); gap < 64*time.Millisecond {
+		t.Fatalf("second retry after %v, want at least 64ms", gap)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	store := storage.NewMemoryStore(0)
+	defer store.Close()
+
+	tr := &transport{fail: func(_ string, n int) error {
+		if n == 0 {
+			return errRateLimited
+		}
+		return nil
+	}}
+	late := &delivered{}
+	o := newTestOutbox(t, store, Options{
+		Transport: tr.send,
+		Classify: func(err error) (bool, time.Duration) {
+			if errors.Is(err, errRateLimited) {
+				return true, 150 * time.Millisecond
+			}
+			return true, 0
+		},
+		Delivered: late.record,
+		BaseDelay: time.Millisecond,
+		Wait:      time.Second,
+	})
+
+	if _, err := o.Send(context.Background(), message(1, "limited")); !errors.Is(err, ErrDeferred) {
+		t.Fatalf("send: got %v, want ErrDeferred", err)
+	}
+	waitFor(t, "delivery", func() bool { return len(late.list()) == 1 })
+
+	times := tr.times("limited")
+	if gap := times[1].Sub(times[0]); gap < 150*time.Millisecond {
+		t.Fatalf("retried after %v, want at least the 150ms the server asked for", gap)
+	}
+}
+
+func TestPermanentFailure(t *testing.T) {
+	store := storage.NewMemoryStore(0)
+	defer store.Close()
+
+	tr := &transport{fail: func(string, int) error { return errTemporary }}
+	o := newTestOutbox(t, store, Options{Transport: tr.send, Wait: time.Second})
+
+	_, err := o.Send(context.Background(), message(1, "broken"))
+	if !errors.Is(err, errTemporary) || errors.Is(err, ErrDeferred) {
+		t.Fatalf("send: got %v, want the permanent error", err)
+	}
+	if got := len(tr.times("broken")); got != 1 {
+		t.Fatalf("attempts: got %d, want 1", got)
+	}
+	if got := o.Depth(); got != 0 {
+		t.Fatalf("depth: got %d, want 0", got)
+	}
+	if got := spooled(t, store); len(got) != 0 {
+		t.Fatalf("spooled chats: got %v, want none", got)
+	}
+}
+
+func TestEditSupersede(t *testing.T) {
+	store := storage.NewMemoryStore(0)
+	defer store.Close()
+
+	gate := make(chan struct{})
+	tr := &transport{hold: map[string]chan struct{}{"head": gate}}
+	late := &delivered{}
+	o := newTestOutbox(t, store, Options{Transport: tr.send, Delivered: late.record, Wait: 2 * time.Second})
+	ctx := context.Background()
+
+	go o.Send(ctx, message(1, "head"))
+	waitFor(t, "head in flight", func() bool { return len(tr.tags(1)) == 1 })
+
+	older := make(chan error, 1)
+	go func() {
+		_, err := o.Send(ctx, edit(1, "m1", "older"))
+		older <- err
+	}()
+	waitFor(t, "older edit queued", func() bool { return o.Depth() == 2 })
+
+	newer := make(chan error, 1)
+	go func() {
+		_, err := o.Send(ctx, edit(1, "m1", "newer"))
+		newer <- err
+	}()
+
+	select {
+	case err := <-older:
+		if !errors.Is(err, ErrDeferred) {
+			t.Fatalf("older edit: got %v, want ErrDeferred", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("older edit was not replaced")
+	}
+	if got := o.Depth(); got != 2 {
+		t.Fatalf("depth after supersede: got %d, want 2", got)
+	}
+
+	close(gate)
+	if err := <-newer; err != nil {
+		t.Fatalf("newer edit: %v", err)
+	}
+	if got, want := tr.tags(1), []string{"head", "newer"}; !slices.Equal(got, want) {
+		t.Fatalf("attempts: got %v, want %v", got, want)
+	}
+}
+
+func TestResume(t *testing.T) {
+	store := storage.NewMemoryStore(0)
+	defer store.Close()
+
+	// The first run never gets a message through and shuts down with both
+	// still queued.
+	stuck := &transport{hold: map[string]chan struct{}{"a": make(chan struct{})}}
+	first := New(store, Options{Transport: stuck.send, Wait: 20 * time.Millisecond}, zap.NewNop())
+	for _, tag := range []string{"a", "b"} {
+		if _, err := first.Send(context.Background(), message(7, tag)); !errors.Is(err, ErrDeferred) {
+			t.Fatalf("send %s: got %v, want ErrDeferred", tag, err)
+		}
+	}
+	first.Close()
+
+	if got := spooled(t, store); !slices.Equal(got, []string{"7"}) {
+		t.Fatalf("spooled chats: got %v, want [7]", got)
+	}
+
+	tr := &transport{}
+	late := &delivered{}
+	second := newTestOutbox(t, store, Options{Transport: tr.send, Delivered: late.record})
+	second.Resume()
+
+	waitFor(t, "resumed delivery", func() bool { return len(late.list()) == 2 })
+	if got, want := late.list(), []string{"a", "b"}; !slices.Equal(got, want) {
+		t.Fatalf("delivered: got %v, want %v", got, want)
+	}
+	waitFor(t, "empty spool", func() bool { return len(spooled(t, store)) == 0 })
+	if _, ok, _ := store.Get(bucketQueue, "7"); ok {
+		t.Fatal("spool of chat 7 is still stored")
+	}
+}
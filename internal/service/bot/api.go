@@ -0,0 +1,28 @@
+package bot
+
+import (
+	"DobrikaDev/max-bot/utils/config"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+)
+
+// NewAPI creates the MAX client. The default endpoint is used unless the
+// config points the bot at another one, such as a local fake.
+func NewAPI(cfg *config.Config) (*maxbot.Api, error) {
+	return maxbot.NewWithConfig(apiConfig{cfg: cfg})
+}
+
+// apiConfig adapts the bot config to the client's configuration interface.
+// Debug mode only makes the client keep each update's raw JSON, which the
+// bot reads user_locale from; nothing is logged or sent because of it.
+type apiConfig struct {
+	cfg *config.Config
+}
+
+func (c apiConfig) GetHttpBotAPIUrl() string        { return c.cfg.MaxAPIURL }
+func (c apiConfig) GetHttpBotAPITimeOut() int       { return 0 }
+func (c apiConfig) GetHttpBotAPIVersion() string    { return "" }
+func (c apiConfig) BotTokenCheckInInputSteam() bool { return false }
+func (c apiConfig) BotTokenCheckString() string     { return c.cfg.MaxToken }
+func (c apiConfig) GetDebugLogMode() bool           { return true }
+func (c apiConfig) GetDebugLogChat() int64          { return 0 }
@@ -1,9 +1,14 @@
 package bot
 
 import (
+	"DobrikaDev/max-bot/internal/metrics"
 	"DobrikaDev/max-bot/internal/service/bot/handlers"
+	"DobrikaDev/max-bot/internal/storage"
 	"DobrikaDev/max-bot/utils/config"
 	"context"
+	"errors"
+	"sync/atomic"
+	"time"
 
 	maxbot "github.com/max-messenger/max-bot-api-client-go"
 	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
@@ -16,27 +21,84 @@ type Bot struct {
 	logger         *zap.Logger
 	api            *maxbot.Api
 	messageHandler *handlers.MessageHandler
+	dispatcher     *dispatcher
+	apiCheck       apiCheck
+
+	started atomic.Bool
+	done    chan struct{}
 }
 
-func NewBot(ctx context.Context, cfg *config.Config, logger *zap.Logger) *Bot {
+func NewBot(ctx context.Context, cfg *config.Config, logger *zap.Logger, store storage.Store) *Bot {
 	logger.Info("Creating bot API")
-	api, err := maxbot.New(cfg.MaxToken)
+	api, err := NewAPI(cfg)
 	if err != nil {
 		logger.Panic("failed to create bot API", zap.Error(err))
 	}
 
-	return &Bot{ctx: ctx, cfg: cfg, api: api, logger: logger, messageHandler: handlers.NewMessageHandler(api, cfg, logger)}
+	b := &Bot{ctx: ctx, cfg: cfg, api: api, logger: logger, messageHandler: handlers.NewMessageHandler(api, cfg, logger, store), done: make(chan struct{})}
+	b.dispatcher = newDispatcher(ctx, logger, cfg.DispatcherWorkers, cfg.DispatcherQueueSize, cfg.UpdateTimeout, b.handleUpdate)
+
+	return b
 }
 
+// Start consumes updates until the bot context is cancelled. It returns once
+// the update source is closed; updates still queued are finished by Close.
 func (b *Bot) Start() {
-	u := b.api.GetUpdates(b.ctx)
+	b.started.Store(true)
+	defer close(b.done)
+
+	var u <-chan schemes.UpdateInterface
+
+	switch mode := b.updateMode(); mode {
+	case updateModePolling:
+		u = b.api.GetUpdates(b.ctx)
+	case updateModeWebhook:
+		updates, err := b.startWebhook()
+		if err != nil {
+			b.logger.Panic("failed to start webhook mode", zap.Error(err))
+		}
+		u = updates
+	default:
+		b.logger.Panic("unknown update mode", zap.String("mode", mode))
+	}
 
 	for update := range u {
-		switch update := update.(type) {
-		case *schemes.MessageCreatedUpdate:
-			b.messageHandler.HandleMessage(b.ctx, update)
-		case *schemes.MessageCallbackUpdate:
-			b.messageHandler.HandleCallbackQuery(b.ctx, update)
+		b.dispatcher.submit(update)
+	}
+}
+
+// Close waits for the update source to stop, drains in-flight updates until
+// ctx expires and closes the service connections.
+func (b *Bot) Close(ctx context.Context) error {
+	var errs []error
+
+	if b.started.Load() {
+		select {
+		case <-b.done:
+		case <-ctx.Done():
+			return errors.Join(errors.New("update source did not stop in time"), b.messageHandler.Close())
 		}
 	}
+
+	if err := b.dispatcher.drain(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	if err := b.messageHandler.Close(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (b *Bot) handleUpdate(ctx context.Context, update schemes.UpdateInterface) {
+	defer metrics.ObserveUpdate(string(update.GetUpdateType()), time.Now())
+
+	b.rememberUserLocale(update)
+
+	switch update := update.(type) {
+	case *schemes.MessageCreatedUpdate:
+		b.messageHandler.HandleMessage(ctx, update)
+	case *schemes.MessageCallbackUpdate:
+		b.messageHandler.HandleCallbackQuery(ctx, update)
+	}
 }
@@ -0,0 +1,162 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultDispatcherWorkers   = 16
+	defaultDispatcherQueueSize = 64
+	defaultUpdateTimeout       = 30 * time.Second
+)
+
+type updateFunc func(ctx context.Context, update schemes.UpdateInterface)
+
+// dispatcher spreads updates across a fixed set of workers. Every update of a
+// user lands on the same worker, so a user's clicks and messages are handled
+// strictly in the order they arrived while other users are served in
+// parallel. A full worker queue blocks submit, which in turn slows down the
+// update source instead of buffering without bound.
+//
+// Handlers run on a context that is detached from the bot context, so a
+// shutdown signal stops the update source without aborting requests that are
+// already in flight; drain cancels them only once its deadline has passed.
+type dispatcher struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	logger  *zap.Logger
+	handle  updateFunc
+	timeout time.Duration
+
+	queues []chan schemes.UpdateInterface
+	wg     sync.WaitGroup
+}
+
+func newDispatcher(ctx context.Context, logger *zap.Logger, workers, queueSize int, timeout time.Duration, handle updateFunc) *dispatcher {
+	if workers <= 0 {
+		workers = defaultDispatcherWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = defaultDispatcherQueueSize
+	}
+	if timeout <= 0 {
+		timeout = defaultUpdateTimeout
+	}
+
+	handlerCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+
+	d := &dispatcher{
+		ctx:     handlerCtx,
+		cancel:  cancel,
+		logger:  logger,
+		handle:  handle,
+		timeout: timeout,
+		queues:  make([]chan schemes.UpdateInterface, workers),
+	}
+
+	for i := range d.queues {
+		d.queues[i] = make(chan schemes.UpdateInterface, queueSize)
+		d.wg.Add(1)
+		go d.run(d.queues[i])
+	}
+
+	return d
+}
+
+func (d *dispatcher) submit(update schemes.UpdateInterface) {
+	queue := d.queues[d.shard(update)]
+
+	select {
+	case queue <- update:
+		return
+	default:
+	}
+
+	d.logger.Debug("dispatcher queue is full, waiting", zap.Int64("key", updateKey(update)))
+	queue <- update
+}
+
+// drain closes the queues and waits until the workers finish what was
+// already submitted. When ctx expires first, the remaining handlers are
+// cancelled and an error is returned. drain must be called once, after the
+// last submit.
+func (d *dispatcher) drain(ctx context.Context) error {
+	for _, queue := range d.queues {
+		close(queue)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-done
+		return fmt.Errorf("drain updates: %w", ctx.Err())
+	}
+}
+
+func (d *dispatcher) run(queue <-chan schemes.UpdateInterface) {
+	defer d.wg.Done()
+
+	for update := range queue {
+		d.process(update)
+	}
+}
+
+func (d *dispatcher) process(update schemes.UpdateInterface) {
+	ctx, cancel := context.WithTimeout(d.ctx, d.timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			d.logger.Error("panic while handling update",
+				zap.Any("panic", r),
+				zap.Int64("key", updateKey(update)),
+				zap.String("update_type", string(update.GetUpdateType())),
+				zap.ByteString("stack", debug.Stack()),
+			)
+		}
+	}()
+
+	d.handle(ctx, update)
+}
+
+func (d *dispatcher) shard(update schemes.UpdateInterface) int {
+	key := updateKey(update)
+	if key < 0 {
+		key = -key
+	}
+	return int(key % int64(len(d.queues)))
+}
+
+func updateKey(update schemes.UpdateInterface) int64 {
+	switch update := update.(type) {
+	case *schemes.MessageCreatedUpdate:
+		if update.Message.Sender.UserId != 0 {
+			return update.Message.Sender.UserId
+		}
+		return update.Message.Recipient.ChatId
+	case *schemes.MessageCallbackUpdate:
+		if update.Callback.User.UserId != 0 {
+			return update.Callback.User.UserId
+		}
+		if update.Message != nil {
+			return update.Message.Recipient.ChatId
+		}
+	}
+	return 0
+}
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	userpb "DobrikaDev/max-bot/internal/generated/userpb"
+
+	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultAccountStatusTTL is used when the config leaves the cache
+	// lifetime unset, e.g. in a config file without account_status_ttl.
+	defaultAccountStatusTTL = 30 * time.Second
+
+	// accountCacheSweepSize is how many entries the cache holds before a
+	// write also drops the expired ones.
+	accountCacheSweepSize = 1024
+)
+
+// accountState is the part of a profile the status gate needs.
+type accountState struct {
+	Status  userpb.Status
+	expires time.Time
+}
+
+// suspended reports whether the account was deactivated. An unspecified
+// status is what older profiles carry and counts as active.
+func (s accountState) suspended() bool {
+	return s.Status == userpb.Status_STATUS_INACTIVE
+}
+
+// accountCache remembers recent profile lookups so a burst of clicks costs
+// one GetUserByMaxID. Only registered users are cached: somebody who is
+// still registering is looked up again on the next update.
+//
+// The cache is per process. A suspension made through one replica clears
+// only that replica's entry, so the others keep letting the user in for up
+// to account_status_ttl. Checks that move coins skip the cache, see
+// accountSuspended.
+type accountCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[int64]accountState
+}
+
+func newAccountCache(ttl time.Duration) *accountCache {
+	if ttl <= 0 {
+		ttl = defaultAccountStatusTTL
+	}
+	return &accountCache{ttl: ttl, entries: make(map[int64]accountState)}
+}
+
+func (c *accountCache) get(userID int64) (accountState, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state, ok := c.entries[userID]
+	if !ok {
+		return accountState{}, false
+	}
+	if time.Now().After(state.expires) {
+		delete(c.entries, userID)
+		return accountState{}, false
+	}
+	return state, true
+}
+
+func (c *accountCache) set(userID int64, user *userpb.User) accountState {
+	now := time.Now()
+	state := accountState{Status: user.GetStatus(), expires: now.Add(c.ttl)}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= accountCacheSweepSize {
+		for id, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, id)
+			}
+		}
+	}
+	c.entries[userID] = state
+	return state
+}
+
+func (c *accountCache) invalidate(userID int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, userID)
+}
+
+// lookupAccount returns the cached state of a registered user, asking the
+// user service on a miss. found is false for users without a profile.
+func (h *MessageHandler) lookupAccount(ctx context.Context, userID int64) (state accountState, found bool, err error) {
+	if state, ok := h.accounts.get(userID); ok {
+		return state, true, nil
+	}
+	return h.fetchAccount(ctx, userID)
+}
+
+// fetchAccount asks the user service for the state and caches it.
+func (h *MessageHandler) fetchAccount(ctx context.Context, userID int64) (state accountState, found bool, err error) {
+	if h.user == nil {
+		return accountState{}, false, fmt.Errorf("user service client is not configured")
+	}
+
+	resp, err := h.user.GetUserByMaxID(ctx, &userpb.GetUserByMaxIDRequest{MaxId: strconv.FormatInt(userID, 10)})
+	if err != nil {
+		return accountState{}, false, err
+	}
+	if resp.GetError() != nil {
+		if resp.GetError().GetCode() == userpb.ErrorCode_ERROR_CODE_NOT_FOUND {
+			return accountState{}, false, nil
+		}
+		return accountState{}, false, fmt.Errorf("user service error: %s", resp.GetError().GetMessage())
+	}
+
+	return h.accounts.set(userID, resp.GetUser()), true, nil
+}
+
+// invalidateAccount drops the cached state after the profile changed, so
+// the next update from that user sees the change at once.
+func (h *MessageHandler) invalidateAccount(maxID string) {
+	userID, err := strconv.ParseInt(maxID, 10, 64)
+	if err != nil {
+		return
+	}
+	h.accounts.invalidate(userID)
+}
+
+// accountSuspended reports whether somebody else's account, e.g. a
+// volunteer about to be paid, is suspended. It always asks the user
+// service, since another replica may have suspended the account after this
+// one cached it. Unknown users and failed lookups count as not suspended.
+func (h *MessageHandler) accountSuspended(ctx context.Context, maxID string) bool {
+	userID, err := strconv.ParseInt(maxID, 10, 64)
+	if err != nil || h.user == nil {
+		return false
+	}
+
+	state, found, err := h.fetchAccount(ctx, userID)
+	if err != nil {
+		h.logger.Warn("failed to check account status", zap.Error(err), zap.String("max_id", maxID))
+		return false
+	}
+	return found && state.suspended()
+}
+
+// accountGate runs before both message and callback handling and stops
+// updates from suspended users, who get the suspended screen instead. A
+// failed lookup lets the update through, like ensureUserContext does, so a
+// user service outage does not lock everybody out.
+func (h *MessageHandler) accountGate(ctx context.Context, chatID, userID int64, callbackID string) bool {
+	if h.user == nil || userID == 0 {
+		return true
+	}
+
+	state, found, err := h.lookupAccount(ctx, userID)
+	if err != nil {
+		h.logger.Warn("failed to check account status", zap.Error(err), zap.Int64("user_id", userID))
+		return true
+	}
+	if !found || !state.suspended() {
+		return true
+	}
+
+	h.logger.Info("update from suspended account dropped", zap.Int64("user_id", userID))
+
+	if callbackID != "" {
+		answer := &schemes.CallbackAnswer{Notification: h.accountSuspendedCallbackText()}
+		if _, err := h.api.Messages.AnswerOnCallback(ctx, callbackID, answer); err != nil && !isBenignAPIError(err) {
+			h.logger.Warn("failed to answer suspended callback", zap.Error(err), zap.String("callback_id", callbackID))
+		}
+	} else {
+		// A typed message gets a fresh screen under it, as /start does.
+		h.menus.delete(chatID)
+	}
+	h.showAccountSuspended(ctx, chatID, userID)
+	return false
+}
+
+// showAccountSuspended replaces whatever flow the user was in with the
+// suspended screen. Their sessions are dropped so nothing half-done picks up
+// again once the account is restored.
+func (h *MessageHandler) showAccountSuspended(ctx context.Context, chatID, userID int64) {
+	h.sessions.delete(userID)
+	h.customerSessions.delete(userID)
+	h.taskSessions.delete(userID)
+	h.feedbackSessions.delete(userID)
+	h.adminSessions.delete(userID)
+
+	keyboard := h.api.Messages.NewKeyboardBuilder()
+	if link := h.accountSuspendedSupportLink(); link != "" {
+		keyboard.AddRow().
+			AddLink(h.accountSuspendedSupportButton(), schemes.POSITIVE, link)
+	}
+
+	h.renderMenu(ctx, chatID, userID, h.accountSuspendedText(), keyboard)
+}
+
+func (h *MessageHandler) accountSuspendedText() string {
+	if text := strings.TrimSpace(h.messages.AccountSuspendedText); text != "" {
+		return text
+	}
+	return "⛔ *Аккаунт приостановлен*\n\nСейчас ты не можешь откликаться на задачи, создавать их и получать монеты. Если считаешь, что это ошибка, напиши в поддержку."
+}
+
+func (h *MessageHandler) accountSuspendedSupportButton() string {
+	if text := strings.TrimSpace(h.messages.AccountSuspendedSupportButton); text != "" {
+		return text
+	}
+	return "💬 Написать в поддержку"
+}
+
+func (h *MessageHandler) accountSuspendedSupportLink() string {
+	return strings.TrimSpace(h.messages.AccountSuspendedSupportLink)
+}
+
+func (h *MessageHandler) accountSuspendedCallbackText() string {
+	if text := strings.TrimSpace(h.messages.AccountSuspendedCallbackText); text != "" {
+		return text
+	}
+	return "Аккаунт приостановлен."
+}
+
+func (h *MessageHandler) customerTaskVolunteerSuspendedText() string {
+	if text := strings.TrimSpace(h.messages.CustomerTaskVolunteerSuspendedText); text != "" {
+		return text
+	}
+	return "Аккаунт этого волонтёра приостановлен, поэтому одобрить отклик и начислить монеты сейчас нельзя."
+}
@@ -0,0 +1,1310 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	taskpb "DobrikaDev/max-bot/internal/generated/taskpb"
+	userpb "DobrikaDev/max-bot/internal/generated/userpb"
+	"DobrikaDev/max-bot/internal/storage"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
+	"go.uber.org/zap"
+)
+
+const (
+	adminCommand = "/admin"
+
+	adminUsersPageSize        = 8
+	adminTasksPageSize        = 8
+	adminAuditListLimit       = 15
+	adminCoinsMaxAmount       = 100000
+	adminCoinsReasonMaxLength = 200
+
+	adminFilterAll      = "all"
+	adminStatusActive   = "active"
+	adminStatusInactive = "inactive"
+	adminRoleUser       = "user"
+	adminRoleAdmin      = "admin"
+
+	adminCoinsDeposit  = "deposit"
+	adminCoinsWithdraw = "withdraw"
+)
+
+// Filters cycle in this order when their button is pressed.
+var (
+	adminStatusFilters = []string{adminFilterAll, adminStatusActive, adminStatusInactive}
+	adminRoleFilters   = []string{adminFilterAll, adminRoleUser, adminRoleAdmin}
+)
+
+type adminStep string
+
+const (
+	adminStepSearch       adminStep = "search"
+	adminStepCoinsAmount  adminStep = "coins_amount"
+	adminStepCoinsReason  adminStep = "coins_reason"
+	adminStepCoinsConfirm adminStep = "coins_confirm"
+)
+
+// adminSession holds admin input that is typed rather than pressed: a user
+// id to look up or a coin adjustment being put together.
+type adminSession struct {
+	UserID    int64
+	ChatID    int64
+	Step      adminStep
+	TargetID  string
+	Direction string
+	Amount    int32
+	Reason    string
+}
+
+type adminSessionStore struct {
+	table sessionTable[adminSession]
+}
+
+func newAdminSessionStore(store storage.Store, ttl time.Duration, logger *zap.Logger) *adminSessionStore {
+	return &adminSessionStore{table: newSessionTable[adminSession](store, sessionBucketAdmin, ttl, logger)}
+}
+
+func (s *adminSessionStore) get(userID int64) (*adminSession, bool) {
+	return s.table.load(userID)
+}
+
+func (s *adminSessionStore) upsert(session *adminSession) {
+	s.table.save(session.UserID, session)
+}
+
+func (s *adminSessionStore) delete(userID int64) {
+	s.table.remove(userID)
+}
+
+func (h *MessageHandler) isAdminCommand(message *schemes.MessageCreatedUpdate) bool {
+	return strings.TrimSpace(strings.ToLower(message.GetText())) == adminCommand
+}
+
+func (h *MessageHandler) showAdminMenu(ctx context.Context, chatID, userID int64) {
+	h.adminSessions.delete(userID)
+
+	keyboard := h.api.Messages.NewKeyboardBuilder()
+	keyboard.AddRow().
+		AddCallback(h.adminUsersButton(), schemes.DEFAULT, adminUsersPayload(adminFilterAll, adminFilterAll, 0))
+	keyboard.AddRow().
+		AddCallback(h.adminTasksButton(), schemes.DEFAULT, fmt.Sprintf("%s:%d", callbackAdminTasks, 0))
+	keyboard.AddRow().
+		AddCallback(h.adminPayoutsButton(), schemes.DEFAULT, callbackAdminPayouts)
+	keyboard.AddRow().
+		AddCallback(h.adminAuditButton(), schemes.DEFAULT, callbackAdminAudit)
+	keyboard.AddRow().
+		AddCallback(h.messages.ProfileBackButton, schemes.DEFAULT, callbackProfileBack)
+
+	h.renderMenu(ctx, chatID, userID, h.adminMenuTitle(), keyboard)
+}
+
+func (h *MessageHandler) adminBackKeyboard() *maxbot.Keyboard {
+	return h.singleButtonKeyboard(h.adminBackButton(), callbackAdminMenu)
+}
+
+func adminUsersPayload(status, role string, page int) string {
+	return fmt.Sprintf("%s:%s:%s:%d", callbackAdminUsers, status, role, page)
+}
+
+func adminStatusValue(filter string) userpb.Status {
+	switch filter {
+	case adminStatusActive:
+		return userpb.Status_STATUS_ACTIVE
+	case adminStatusInactive:
+		return userpb.Status_STATUS_INACTIVE
+	default:
+		return userpb.Status_STATUS_UNSPECIFIED
+	}
+}
+
+func adminRoleValue(filter string) userpb.Role {
+	switch filter {
+	case adminRoleUser:
+		return userpb.Role_ROLE_USER
+	case adminRoleAdmin:
+		return userpb.Role_ROLE_ADMIN
+	default:
+		return userpb.Role_ROLE_UNSPECIFIED
+	}
+}
+
+// nextAdminFilter returns the filter after current, starting over from
+// "all" for values it does not know.
+func nextAdminFilter(filters []string, current string) string {
+	for idx, filter := range filters {
+		if filter == current {
+			return filters[(idx+1)%len(filters)]
+		}
+	}
+	return filters[0]
+}
+
+func normalizeAdminFilter(filters []string, value string) string {
+	for _, filter := range filters {
+		if filter == value {
+			return value
+		}
+	}
+	return adminFilterAll
+}
+
+func (h *MessageHandler) handleAdminUsers(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, status, role, pageValue string) {
+	if callbackQuery.Message == nil {
+		return
+	}
+
+	page, err := strconv.Atoi(pageValue)
+	if err != nil || page < 0 {
+		page = 0
+	}
+	h.showAdminUsers(ctx, callbackQuery.Message.Recipient.ChatId, callbackQuery.Callback.User.UserId, status, role, page)
+}
+
+func (h *MessageHandler) showAdminUsers(ctx context.Context, chatID, userID int64, status, role string, page int, intro ...string) {
+	h.adminSessions.delete(userID)
+
+	status = normalizeAdminFilter(adminStatusFilters, status)
+	role = normalizeAdminFilter(adminRoleFilters, role)
+
+	text, keyboard := h.buildAdminUsersView(ctx, status, role, page)
+	if len(intro) > 0 && strings.TrimSpace(intro[0]) != "" {
+		text = strings.TrimSpace(intro[0]) + "\n\n" + text
+	}
+	h.renderMenu(ctx, chatID, userID, text, keyboard)
+}
+
+func (h *MessageHandler) buildAdminUsersView(ctx context.Context, status, role string, page int) (string, *maxbot.Keyboard) {
+	if h.user == nil {
+		return h.adminUsersErrorText(), h.adminBackKeyboard()
+	}
+
+	users, total, err := h.fetchAdminUsers(ctx, status, role, page)
+	if err == nil && len(users) == 0 && total > 0 && page > 0 {
+		page = (total - 1) / adminUsersPageSize
+		users, total, err = h.fetchAdminUsers(ctx, status, role, page)
+	}
+	if err != nil {
+		h.logger.Error("failed to fetch users for admin", zap.Error(err), zap.String("status", status), zap.String("role", role))
+		return h.adminUsersErrorText(), h.adminBackKeyboard()
+	}
+
+	var builder strings.Builder
+	builder.WriteString(h.adminUsersTitle())
+	builder.WriteString("\n\n")
+	if total == 0 {
+		builder.WriteString(h.adminUsersEmptyText())
+	} else {
+		builder.WriteString(fmt.Sprintf(h.adminUsersSummaryTemplate(), total))
+	}
+
+	totalPages := (total + adminUsersPageSize - 1) / adminUsersPageSize
+	if totalPages > 1 {
+		builder.WriteString("\n")
+		builder.WriteString(h.pageFooter(h.messages.AdminPageFooter, page, totalPages))
+	}
+
+	keyboard := h.api.Messages.NewKeyboardBuilder()
+	keyboard.AddRow().
+		AddCallback(fmt.Sprintf(h.adminUsersStatusFilterButton(), h.adminStatusFilterLabel(status)), schemes.DEFAULT, adminUsersPayload(nextAdminFilter(adminStatusFilters, status), role, 0)).
+		AddCallback(fmt.Sprintf(h.adminUsersRoleFilterButton(), h.adminRoleFilterLabel(role)), schemes.DEFAULT, adminUsersPayload(status, nextAdminFilter(adminRoleFilters, role), 0))
+
+	for _, user := range users {
+		keyboard.AddRow().
+			AddCallback(truncateLabel(h.adminUserLabel(user), 45), schemes.DEFAULT, fmt.Sprintf("%s:%s", callbackAdminUser, user.GetMaxId()))
+	}
+
+	hasPrev := page > 0
+	hasNext := (page+1)*adminUsersPageSize < total
+	if hasPrev || hasNext {
+		row := keyboard.AddRow()
+		if hasPrev {
+			row.AddCallback(h.adminPrevButton(), schemes.DEFAULT, adminUsersPayload(status, role, page-1))
+		}
+		if hasNext {
+			row.AddCallback(h.adminNextButton(), schemes.DEFAULT, adminUsersPayload(status, role, page+1))
+		}
+	}
+
+	keyboard.AddRow().
+		AddCallback(h.adminUsersSearchButton(), schemes.DEFAULT, callbackAdminUsersSearch)
+	keyboard.AddRow().
+		AddCallback(h.adminBackButton(), schemes.DEFAULT, callbackAdminMenu)
+
+	return builder.String(), keyboard
+}
+
+func (h *MessageHandler) fetchAdminUsers(ctx context.Context, status, role string, page int) ([]*userpb.User, int, error) {
+	resp, err := h.user.GetUsers(ctx, &userpb.GetUsersRequest{
+		Status: adminStatusValue(status),
+		Role:   adminRoleValue(role),
+		Limit:  adminUsersPageSize,
+		Offset: int32(page * adminUsersPageSize),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.GetError() != nil {
+		return nil, 0, fmt.Errorf("user service error: %s", resp.GetError().GetMessage())
+	}
+	return resp.GetUsers(), int(resp.GetTotal()), nil
+}
+
+func (h *MessageHandler) adminUserLabel(user *userpb.User) string {
+	label := fmt.Sprintf("%s · %s", adminUserName(user), user.GetMaxId())
+	switch {
+	case user.GetStatus() == userpb.Status_STATUS_INACTIVE:
+		return "⛔ " + label
+	case user.GetRole() == userpb.Role_ROLE_ADMIN:
+		return "🛡 " + label
+	default:
+		return label
+	}
+}
+
+func adminUserName(user *userpb.User) string {
+	if name := strings.TrimSpace(user.GetName()); name != "" {
+		return name
+	}
+	return fmt.Sprintf("Пользователь %s", user.GetMaxId())
+}
+
+func (h *MessageHandler) startAdminUserSearch(ctx context.Context, chatID, userID int64) {
+	h.adminSessions.upsert(&adminSession{UserID: userID, ChatID: chatID, Step: adminStepSearch})
+	h.renderMenu(ctx, chatID, userID, h.adminUsersSearchPrompt(), h.singleButtonKeyboard(h.adminCancelButton(), adminUsersPayload(adminFilterAll, adminFilterAll, 0)))
+}
+
+func (h *MessageHandler) handleAdminUserView(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, maxID string) {
+	if callbackQuery.Message == nil {
+		return
+	}
+	h.showAdminUser(ctx, callbackQuery.Message.Recipient.ChatId, callbackQuery.Callback.User.UserId, maxID)
+}
+
+func (h *MessageHandler) showAdminUser(ctx context.Context, chatID, adminID int64, maxID string, intro ...string) {
+	h.adminSessions.delete(adminID)
+
+	user, err := h.fetchAdminUser(ctx, maxID)
+	if err != nil {
+		h.logger.Error("failed to fetch user for admin", zap.Error(err), zap.String("max_id", maxID))
+		h.renderMenu(ctx, chatID, adminID, h.adminUsersErrorText(), h.adminBackKeyboard())
+		return
+	}
+	if user == nil {
+		h.showAdminUsers(ctx, chatID, adminID, adminFilterAll, adminFilterAll, 0, h.adminUsersNotFoundText())
+		return
+	}
+
+	balance := int32(0)
+	if resp, err := h.user.GetBalance(ctx, &userpb.GetBalanceRequest{MaxId: maxID}); err != nil {
+		h.logger.Warn("failed to fetch balance for admin", zap.Error(err), zap.String("max_id", maxID))
+	} else if resp.GetError() != nil {
+		h.logger.Warn("balance response error for admin", zap.String("message", resp.GetError().GetMessage()), zap.String("max_id", maxID))
+	} else {
+		balance = resp.GetBalance()
+	}
+
+	text := fmt.Sprintf(h.adminUserDetailTemplate(),
+		escapeMarkdown(adminUserName(user)),
+		user.GetMaxId(),
+		h.adminRoleLabel(user.GetRole()),
+		h.adminStatusLabel(user.GetStatus()),
+		balance,
+	)
+	if len(intro) > 0 && strings.TrimSpace(intro[0]) != "" {
+		text = strings.TrimSpace(intro[0]) + "\n\n" + text
+	}
+
+	keyboard := h.api.Messages.NewKeyboardBuilder()
+	keyboard.AddRow().
+		AddCallback(h.adminCoinsDepositButton(), schemes.POSITIVE, fmt.Sprintf("%s:%s:%s", callbackAdminCoins, maxID, adminCoinsDeposit)).
+		AddCallback(h.adminCoinsWithdrawButton(), schemes.DEFAULT, fmt.Sprintf("%s:%s:%s", callbackAdminCoins, maxID, adminCoinsWithdraw))
+	switch {
+	case user.GetStatus() == userpb.Status_STATUS_INACTIVE:
+		keyboard.AddRow().
+			AddCallback(h.adminUserActivateButton(), schemes.POSITIVE, fmt.Sprintf("%s:%s:%s", callbackAdminUserStatus, maxID, adminStatusActive))
+	case maxID != strconv.FormatInt(adminID, 10):
+		keyboard.AddRow().
+			AddCallback(h.adminUserDeactivateButton(), schemes.NEGATIVE, fmt.Sprintf("%s:%s:%s", callbackAdminUserStatus, maxID, adminStatusInactive))
+	}
+	keyboard.AddRow().
+		AddCallback(h.adminUsersBackButton(), schemes.DEFAULT, adminUsersPayload(adminFilterAll, adminFilterAll, 0))
+
+	h.renderMenu(ctx, chatID, adminID, text, keyboard)
+}
+
+// fetchAdminUser returns nil without an error when there is no such user.
+func (h *MessageHandler) fetchAdminUser(ctx context.Context, maxID string) (*userpb.User, error) {
+	if h.user == nil {
+		return nil, fmt.Errorf("user service client is not configured")
+	}
+
+	resp, err := h.user.GetUserByMaxID(ctx, &userpb.GetUserByMaxIDRequest{MaxId: maxID})
+	if err != nil {
+		return nil, err
+	}
+	if resp.GetError() != nil {
+		if resp.GetError().GetCode() == userpb.ErrorCode_ERROR_CODE_NOT_FOUND {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("user service error: %s", resp.GetError().GetMessage())
+	}
+	return resp.GetUser(), nil
+}
+
+func (h *MessageHandler) handleAdminUserStatus(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, maxID, status string) {
+	if callbackQuery.Message == nil {
+		return
+	}
+
+	chatID := callbackQuery.Message.Recipient.ChatId
+	adminID := callbackQuery.Callback.User.UserId
+
+	var (
+		value   userpb.Status
+		action  string
+		summary string
+		done    string
+	)
+	switch status {
+	case adminStatusActive:
+		value, action, summary, done = userpb.Status_STATUS_ACTIVE, adminActionUserActivate, h.adminAuditUserActivated(), h.adminUserActivatedText()
+	case adminStatusInactive:
+		if maxID == strconv.FormatInt(adminID, 10) {
+			h.showAdminUser(ctx, chatID, adminID, maxID, h.adminUserSelfText())
+			return
+		}
+		value, action, summary, done = userpb.Status_STATUS_INACTIVE, adminActionUserDeactivate, h.adminAuditUserDeactivated(), h.adminUserDeactivatedText()
+	default:
+		h.showAdminUser(ctx, chatID, adminID, maxID)
+		return
+	}
+
+	resp, err := h.user.UpdateUser(ctx, &userpb.UpdateUserRequest{User: &userpb.User{MaxId: maxID, Status: value}})
+	if err == nil && resp.GetError() != nil {
+		err = fmt.Errorf("user service error: %s", resp.GetError().GetMessage())
+	}
+	if err != nil {
+		h.logger.Error("failed to update user status", zap.Error(err), zap.String("max_id", maxID), zap.String("status", status))
+		h.showAdminUser(ctx, chatID, adminID, maxID, h.adminUserUpdateErrorText())
+		return
+	}
+
+	h.invalidateAccount(maxID)
+	h.auditAdmin(adminID, action, maxID, fmt.Sprintf(summary, h.adminTargetName(ctx, maxID)))
+	h.showAdminUser(ctx, chatID, adminID, maxID, done)
+}
+
+// adminTargetName names a user in the audit trail by name and id, since
+// names are neither unique nor permanent.
+func (h *MessageHandler) adminTargetName(ctx context.Context, maxID string) string {
+	return fmt.Sprintf("%s (%s)", h.lookupUserName(ctx, maxID), maxID)
+}
+
+func (h *MessageHandler) handleAdminCoinsStart(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, maxID, direction string) {
+	if callbackQuery.Message == nil {
+		return
+	}
+	if direction != adminCoinsDeposit && direction != adminCoinsWithdraw {
+		h.showAdminUser(ctx, callbackQuery.Message.Recipient.ChatId, callbackQuery.Callback.User.UserId, maxID)
+		return
+	}
+
+	session := &adminSession{
+		UserID:    callbackQuery.Callback.User.UserId,
+		ChatID:    callbackQuery.Message.Recipient.ChatId,
+		Step:      adminStepCoinsAmount,
+		TargetID:  maxID,
+		Direction: direction,
+	}
+	h.adminSessions.upsert(session)
+	h.renderAdminCoinsStep(ctx, session)
+}
+
+// renderAdminCoinsStep shows the prompt for the session's step, after an
+// optional note such as a validation error.
+func (h *MessageHandler) renderAdminCoinsStep(ctx context.Context, session *adminSession, note ...string) {
+	cancel := h.singleButtonKeyboard(h.adminCancelButton(), fmt.Sprintf("%s:%s", callbackAdminUser, session.TargetID))
+
+	var (
+		text     string
+		keyboard = cancel
+	)
+	switch session.Step {
+	case adminStepCoinsAmount:
+		prompt := h.adminCoinsDepositPrompt()
+		if session.Direction == adminCoinsWithdraw {
+			prompt = h.adminCoinsWithdrawPrompt()
+		}
+		text = fmt.Sprintf(prompt, escapeMarkdown(h.lookupUserName(ctx, session.TargetID)))
+	case adminStepCoinsReason:
+		text = h.adminCoinsReasonPrompt()
+	default:
+		template := h.adminCoinsDepositConfirmTemplate()
+		if session.Direction == adminCoinsWithdraw {
+			template = h.adminCoinsWithdrawConfirmTemplate()
+		}
+		text = fmt.Sprintf(template, session.Amount, escapeMarkdown(h.lookupUserName(ctx, session.TargetID)), escapeMarkdown(session.Reason))
+
+		keyboard = h.api.Messages.NewKeyboardBuilder()
+		keyboard.AddRow().
+			AddCallback(h.adminCoinsConfirmButton(), schemes.POSITIVE, callbackAdminCoinsConfirm)
+		keyboard.AddRow().
+			AddCallback(h.adminCancelButton(), schemes.DEFAULT, fmt.Sprintf("%s:%s", callbackAdminUser, session.TargetID))
+	}
+
+	if len(note) > 0 && strings.TrimSpace(note[0]) != "" {
+		text = strings.TrimSpace(note[0]) + "\n\n" + text
+	}
+	h.renderMenu(ctx, session.ChatID, session.UserID, text, keyboard)
+}
+
+// tryHandleAdminMessage takes the text an admin screen asked for. The role
+// is checked again because the session may outlive it.
+func (h *MessageHandler) tryHandleAdminMessage(ctx context.Context, update *schemes.MessageCreatedUpdate) bool {
+	session, ok := h.adminSessions.get(update.Message.Sender.UserId)
+	if !ok {
+		return false
+	}
+
+	if h.isStartCommand(update) || !h.isAdmin(ctx, session.UserID) {
+		h.adminSessions.delete(session.UserID)
+		return false
+	}
+
+	text := strings.TrimSpace(update.GetText())
+
+	switch session.Step {
+	case adminStepSearch:
+		if text == "" {
+			h.startAdminUserSearch(ctx, session.ChatID, session.UserID)
+			return true
+		}
+		h.showAdminUser(ctx, session.ChatID, session.UserID, text)
+	case adminStepCoinsAmount:
+		amount, err := strconv.Atoi(text)
+		if err != nil || amount <= 0 || amount > adminCoinsMaxAmount {
+			h.renderAdminCoinsStep(ctx, session, fmt.Sprintf(h.adminCoinsAmountInvalidText(), adminCoinsMaxAmount))
+			return true
+		}
+		session.Amount = int32(amount)
+		session.Step = adminStepCoinsReason
+		h.adminSessions.upsert(session)
+		h.renderAdminCoinsStep(ctx, session)
+	case adminStepCoinsReason:
+		if text == "" || utf8.RuneCountInString(text) > adminCoinsReasonMaxLength {
+			h.renderAdminCoinsStep(ctx, session, fmt.Sprintf(h.adminCoinsReasonInvalidText(), adminCoinsReasonMaxLength))
+			return true
+		}
+		session.Reason = text
+		session.Step = adminStepCoinsConfirm
+		h.adminSessions.upsert(session)
+		h.renderAdminCoinsStep(ctx, session)
+	default:
+		h.renderAdminCoinsStep(ctx, session)
+	}
+
+	return true
+}
+
+func (h *MessageHandler) handleAdminCoinsConfirm(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate) {
+	if callbackQuery.Message == nil {
+		return
+	}
+
+	chatID := callbackQuery.Message.Recipient.ChatId
+	adminID := callbackQuery.Callback.User.UserId
+
+	session, ok := h.adminSessions.get(adminID)
+	if !ok || session.Step != adminStepCoinsConfirm {
+		h.showAdminMenu(ctx, chatID, adminID)
+		return
+	}
+	// Dropped before the call so a second press cannot repeat the operation.
+	h.adminSessions.delete(adminID)
+
+	opType := userpb.BalanceOperationType_BALANCE_OPERATION_TYPE_DEPOSIT
+	action, summary := adminActionCoinsDeposit, h.adminAuditCoinsDeposit()
+	if session.Direction == adminCoinsWithdraw {
+		opType = userpb.BalanceOperationType_BALANCE_OPERATION_TYPE_WITHDRAW
+		action, summary = adminActionCoinsWithdraw, h.adminAuditCoinsWithdraw()
+	}
+
+	resp, err := h.user.CreateOperation(ctx, &userpb.CreateOperationRequest{
+		MaxId:       session.TargetID,
+		Amount:      session.Amount,
+		Type:        opType,
+		Description: fmt.Sprintf(h.adminCoinsDescriptionTemplate(), session.Reason),
+	})
+	if err != nil {
+		h.logger.Error("failed to create admin balance operation", zap.Error(err), zap.String("max_id", session.TargetID))
+		h.showAdminUser(ctx, chatID, adminID, session.TargetID, h.adminCoinsErrorText())
+		return
+	}
+	if resp.GetError() != nil {
+		note := h.adminCoinsErrorText()
+		if resp.GetError().GetCode() == userpb.ErrorCode_ERROR_CODE_NOT_ENOUGH {
+			note = h.adminCoinsNotEnoughText()
+		}
+		h.logger.Warn("user service rejected admin balance operation", zap.String("message", resp.GetError().GetMessage()), zap.String("max_id", session.TargetID))
+		h.showAdminUser(ctx, chatID, adminID, session.TargetID, note)
+		return
+	}
+
+	h.auditAdmin(adminID, action, session.TargetID,
+		fmt.Sprintf(summary, session.Amount, h.adminTargetName(ctx, session.TargetID), session.Reason),
+		zap.Int32("amount", session.Amount),
+		zap.String("operation_id", resp.GetOperation().GetId()),
+	)
+	h.showAdminUser(ctx, chatID, adminID, session.TargetID, h.adminCoinsSuccessText())
+}
+
+func (h *MessageHandler) handleAdminTasks(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, pageValue string) {
+	if callbackQuery.Message == nil {
+		return
+	}
+
+	page, err := strconv.Atoi(pageValue)
+	if err != nil || page < 0 {
+		page = 0
+	}
+	h.showAdminTasks(ctx, callbackQuery.Message.Recipient.ChatId, callbackQuery.Callback.User.UserId, page)
+}
+
+func (h *MessageHandler) showAdminTasks(ctx context.Context, chatID, userID int64, page int, intro ...string) {
+	text, keyboard := h.buildAdminTasksView(ctx, page)
+	if len(intro) > 0 && strings.TrimSpace(intro[0]) != "" {
+		text = strings.TrimSpace(intro[0]) + "\n\n" + text
+	}
+	h.renderMenu(ctx, chatID, userID, text, keyboard)
+}
+
+func (h *MessageHandler) buildAdminTasksView(ctx context.Context, page int) (string, *maxbot.Keyboard) {
+	if h.task == nil {
+		return h.adminTasksErrorText(), h.adminBackKeyboard()
+	}
+
+	tasks, total, err := h.fetchAdminTasks(ctx, page)
+	if err == nil && len(tasks) == 0 && total > 0 && page > 0 {
+		page = (total - 1) / adminTasksPageSize
+		tasks, total, err = h.fetchAdminTasks(ctx, page)
+	}
+	if err != nil {
+		h.logger.Error("failed to fetch tasks for admin", zap.Error(err))
+		return h.adminTasksErrorText(), h.adminBackKeyboard()
+	}
+
+	var builder strings.Builder
+	builder.WriteString(h.adminTasksTitle())
+	builder.WriteString("\n\n")
+	if total == 0 {
+		builder.WriteString(h.adminTasksEmptyText())
+	} else {
+		builder.WriteString(fmt.Sprintf(h.adminTasksSummaryTemplate(), total))
+	}
+
+	totalPages := (total + adminTasksPageSize - 1) / adminTasksPageSize
+	if totalPages > 1 {
+		builder.WriteString("\n")
+		builder.WriteString(h.pageFooter(h.messages.AdminPageFooter, page, totalPages))
+	}
+
+	keyboard := h.api.Messages.NewKeyboardBuilder()
+	for idx, task := range tasks {
+		label := fmt.Sprintf("%d. %s", page*adminTasksPageSize+idx+1, strings.TrimSpace(task.GetName()))
+		keyboard.AddRow().
+			AddCallback(truncateLabel(label, 45), schemes.DEFAULT, fmt.Sprintf("%s:%s", callbackAdminTask, task.GetId()))
+	}
+
+	hasPrev := page > 0
+	hasNext := (page+1)*adminTasksPageSize < total
+	if hasPrev || hasNext {
+		row := keyboard.AddRow()
+		if hasPrev {
+			row.AddCallback(h.adminPrevButton(), schemes.DEFAULT, fmt.Sprintf("%s:%d", callbackAdminTasks, page-1))
+		}
+		if hasNext {
+			row.AddCallback(h.adminNextButton(), schemes.DEFAULT, fmt.Sprintf("%s:%d", callbackAdminTasks, page+1))
+		}
+	}
+
+	keyboard.AddRow().
+		AddCallback(h.adminBackButton(), schemes.DEFAULT, callbackAdminMenu)
+
+	return builder.String(), keyboard
+}
+
+func (h *MessageHandler) fetchAdminTasks(ctx context.Context, page int) ([]*taskpb.Task, int, error) {
+	resp, err := h.task.GetTasks(ctx, &taskpb.GetTasksRequest{
+		Limit:  adminTasksPageSize,
+		Offset: int32(page * adminTasksPageSize),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.GetError() != nil {
+		return nil, 0, fmt.Errorf("task service error: %s", resp.GetError().GetMessage())
+	}
+	return resp.GetTasks(), int(resp.GetTotal()), nil
+}
+
+func (h *MessageHandler) handleAdminTaskView(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, taskID string) {
+	if callbackQuery.Message == nil {
+		return
+	}
+	h.showAdminTask(ctx, callbackQuery.Message.Recipient.ChatId, callbackQuery.Callback.User.UserId, taskID)
+}
+
+func (h *MessageHandler) showAdminTask(ctx context.Context, chatID, userID int64, taskID string, intro ...string) {
+	task, err := h.getTaskByID(ctx, taskID)
+	if err != nil {
+		h.logger.Error("failed to fetch task for admin", zap.Error(err), zap.String("task_id", taskID))
+		h.renderMenu(ctx, chatID, userID, h.adminTasksErrorText(), h.adminBackKeyboard())
+		return
+	}
+	if task == nil {
+		h.showAdminTasks(ctx, chatID, userID, 0)
+		return
+	}
+
+	description := escapeMarkdown(strings.TrimSpace(task.GetDescription()))
+	if description == "" {
+		description = "—"
+	}
+	createdAt := "—"
+	if task.GetCreatedAt() > 0 {
+		createdAt = formatPayoutTime(time.Unix(int64(task.GetCreatedAt()), 0))
+	}
+
+	text := fmt.Sprintf(h.adminTaskDetailTemplate(),
+		escapeMarkdown(strings.TrimSpace(task.GetName())),
+		description,
+		escapeMarkdown(h.adminTargetName(ctx, task.GetCustomerId())),
+		task.GetCost(),
+		len(parseTaskAssignments(task)),
+		createdAt,
+	)
+	if len(intro) > 0 && strings.TrimSpace(intro[0]) != "" {
+		text = strings.TrimSpace(intro[0]) + "\n\n" + text
+	}
+
+	keyboard := h.api.Messages.NewKeyboardBuilder()
+	keyboard.AddRow().
+		AddCallback(h.adminTaskDeleteButton(), schemes.NEGATIVE, fmt.Sprintf("%s:%s", callbackAdminTaskDeleteAsk, taskID))
+	keyboard.AddRow().
+		AddCallback(h.adminTasksBackButton(), schemes.DEFAULT, fmt.Sprintf("%s:%d", callbackAdminTasks, 0))
+
+	h.renderMenu(ctx, chatID, userID, text, keyboard)
+}
+
+func (h *MessageHandler) handleAdminTaskDeleteAsk(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, taskID string) {
+	if callbackQuery.Message == nil {
+		return
+	}
+
+	chatID := callbackQuery.Message.Recipient.ChatId
+	userID := callbackQuery.Callback.User.UserId
+
+	task, err := h.getTaskByID(ctx, taskID)
+	if err != nil || task == nil {
+		h.showAdminTask(ctx, chatID, userID, taskID)
+		return
+	}
+
+	keyboard := h.api.Messages.NewKeyboardBuilder()
+	keyboard.AddRow().
+		AddCallback(h.adminTaskDeleteConfirmButton(), schemes.NEGATIVE, fmt.Sprintf("%s:%s", callbackAdminTaskDelete, taskID))
+	keyboard.AddRow().
+		AddCallback(h.adminCancelButton(), schemes.DEFAULT, fmt.Sprintf("%s:%s", callbackAdminTask, taskID))
+
+	h.renderMenu(ctx, chatID, userID, fmt.Sprintf(h.adminTaskDeleteAskTemplate(), escapeMarkdown(strings.TrimSpace(task.GetName()))), keyboard)
+}
+
+// handleAdminTaskDelete removes any task. The owner and everyone who
+// responded and was not turned down are told it was taken down.
+func (h *MessageHandler) handleAdminTaskDelete(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, taskID string) {
+	if callbackQuery.Message == nil {
+		return
+	}
+
+	chatID := callbackQuery.Message.Recipient.ChatId
+	adminID := callbackQuery.Callback.User.UserId
+
+	task, err := h.getTaskByID(ctx, taskID)
+	if err != nil {
+		h.logger.Error("failed to load task for admin deletion", zap.Error(err), zap.String("task_id", taskID))
+		h.showAdminTask(ctx, chatID, adminID, taskID, h.adminTaskDeleteErrorText())
+		return
+	}
+	if task == nil {
+		h.showAdminTasks(ctx, chatID, adminID, 0)
+		return
+	}
+
+	resp, err := h.task.DeleteTask(ctx, &taskpb.DeleteTaskRequest{Id: taskID})
+	if err == nil && resp.GetError() != nil {
+		err = fmt.Errorf("task service error: %s", resp.GetError().GetMessage())
+	}
+	if err != nil {
+		h.logger.Error("failed to delete task as admin", zap.Error(err), zap.String("task_id", taskID))
+		h.showAdminTask(ctx, chatID, adminID, taskID, h.adminTaskDeleteErrorText())
+		return
+	}
+
+	name := strings.TrimSpace(task.GetName())
+	h.auditAdmin(adminID, adminActionTaskDelete, taskID, fmt.Sprintf(h.adminAuditTaskDeleted(), name), zap.String("customer_id", task.GetCustomerId()))
+
+	for _, recipient := range append([]string{strings.TrimSpace(task.GetCustomerId())}, taskCancelRecipients(task)...) {
+		if recipient == "" || recipient == strconv.FormatInt(adminID, 10) {
+			continue
+		}
+		h.notifyTaskVolunteer(ctx, recipient, taskID, fmt.Sprintf(h.forMaxID(recipient).adminTaskDeletedNotification(), escapeMarkdown(name)))
+	}
+
+	h.showAdminTasks(ctx, chatID, adminID, 0, fmt.Sprintf(h.adminTaskDeletedTemplate(), escapeMarkdown(name)))
+}
+
+func (h *MessageHandler) showAdminAudit(ctx context.Context, chatID, userID int64) {
+	entries := h.adminAudit.recent(adminAuditListLimit)
+
+	var builder strings.Builder
+	builder.WriteString(h.adminAuditTitle())
+	builder.WriteString("\n\n")
+	if len(entries) == 0 {
+		builder.WriteString(h.adminAuditEmptyText())
+	}
+
+	names := make(map[int64]string)
+	for idx, entry := range entries {
+		name, ok := names[entry.AdminID]
+		if !ok {
+			name = h.lookupUserName(ctx, strconv.FormatInt(entry.AdminID, 10))
+			names[entry.AdminID] = name
+		}
+		if idx > 0 {
+			builder.WriteString("\n\n")
+		}
+		// Summaries are kept as plain text, the way they go to the log.
+		builder.WriteString(fmt.Sprintf(h.adminAuditItemTemplate(), formatPayoutTime(entry.At), escapeMarkdown(name), escapeMarkdown(entry.Summary)))
+	}
+
+	h.renderMenu(ctx, chatID, userID, builder.String(), h.adminBackKeyboard())
+}
+
+func (h *MessageHandler) adminStatusLabel(status userpb.Status) string {
+	if status == userpb.Status_STATUS_INACTIVE {
+		return h.adminStatusInactiveLabel()
+	}
+	return h.adminStatusActiveLabel()
+}
+
+func (h *MessageHandler) adminRoleLabel(role userpb.Role) string {
+	if role == userpb.Role_ROLE_ADMIN {
+		return h.adminRoleAdminLabel()
+	}
+	return h.adminRoleUserLabel()
+}
+
+func (h *MessageHandler) adminStatusFilterLabel(filter string) string {
+	switch filter {
+	case adminStatusActive:
+		return h.adminStatusActiveLabel()
+	case adminStatusInactive:
+		return h.adminStatusInactiveLabel()
+	default:
+		return h.adminFilterAllLabel()
+	}
+}
+
+func (h *MessageHandler) adminRoleFilterLabel(filter string) string {
+	switch filter {
+	case adminRoleUser:
+		return h.adminRoleUserLabel()
+	case adminRoleAdmin:
+		return h.adminRoleAdminLabel()
+	default:
+		return h.adminFilterAllLabel()
+	}
+}
+
+func (h *MessageHandler) adminMenuButton() string {
+	if text := strings.TrimSpace(h.messages.AdminMenuButton); text != "" {
+		return text
+	}
+	return "🛡 Админка"
+}
+
+func (h *MessageHandler) adminMenuTitle() string {
+	if text := strings.TrimSpace(h.messages.AdminMenuTitle); text != "" {
+		return text
+	}
+	return "🛡 *Админка*\n\nЧто будем делать?"
+}
+
+func (h *MessageHandler) adminUsersButton() string {
+	if text := strings.TrimSpace(h.messages.AdminUsersButton); text != "" {
+		return text
+	}
+	return "👥 Пользователи"
+}
+
+func (h *MessageHandler) adminTasksButton() string {
+	if text := strings.TrimSpace(h.messages.AdminTasksButton); text != "" {
+		return text
+	}
+	return "📋 Все дела"
+}
+
+func (h *MessageHandler) adminPayoutsButton() string {
+	if text := strings.TrimSpace(h.messages.AdminPayoutsButton); text != "" {
+		return text
+	}
+	return "💸 Выплаты"
+}
+
+func (h *MessageHandler) adminAuditButton() string {
+	if text := strings.TrimSpace(h.messages.AdminAuditButton); text != "" {
+		return text
+	}
+	return "📜 Журнал действий"
+}
+
+func (h *MessageHandler) adminBackButton() string {
+	if text := strings.TrimSpace(h.messages.AdminBackButton); text != "" {
+		return text
+	}
+	return "⬅️ В админку"
+}
+
+func (h *MessageHandler) adminCancelButton() string {
+	if text := strings.TrimSpace(h.messages.AdminCancelButton); text != "" {
+		return text
+	}
+	return "Отмена"
+}
+
+func (h *MessageHandler) adminPrevButton() string {
+	if text := strings.TrimSpace(h.messages.AdminPrevButton); text != "" {
+		return text
+	}
+	return "⬅️ Назад"
+}
+
+func (h *MessageHandler) adminNextButton() string {
+	if text := strings.TrimSpace(h.messages.AdminNextButton); text != "" {
+		return text
+	}
+	return "➡️ Далее"
+}
+
+func (h *MessageHandler) adminFilterAllLabel() string {
+	if text := strings.TrimSpace(h.messages.AdminFilterAllLabel); text != "" {
+		return text
+	}
+	return "все"
+}
+
+func (h *MessageHandler) adminStatusActiveLabel() string {
+	if text := strings.TrimSpace(h.messages.AdminStatusActiveLabel); text != "" {
+		return text
+	}
+	return "активен"
+}
+
+func (h *MessageHandler) adminStatusInactiveLabel() string {
+	if text := strings.TrimSpace(h.messages.AdminStatusInactiveLabel); text != "" {
+		return text
+	}
+	return "заблокирован"
+}
+
+func (h *MessageHandler) adminRoleUserLabel() string {
+	if text := strings.TrimSpace(h.messages.AdminRoleUserLabel); text != "" {
+		return text
+	}
+	return "пользователь"
+}
+
+func (h *MessageHandler) adminRoleAdminLabel() string {
+	if text := strings.TrimSpace(h.messages.AdminRoleAdminLabel); text != "" {
+		return text
+	}
+	return "админ"
+}
+
+func (h *MessageHandler) adminUsersTitle() string {
+	if text := strings.TrimSpace(h.messages.AdminUsersTitle); text != "" {
+		return text
+	}
+	return "👥 *Пользователи*"
+}
+
+func (h *MessageHandler) adminUsersSummaryTemplate() string {
+	if text := strings.TrimSpace(h.messages.AdminUsersSummaryTemplate); text != "" {
+		return text
+	}
+	return "Найдено: %d"
+}
+
+func (h *MessageHandler) adminUsersStatusFilterButton() string {
+	if text := strings.TrimSpace(h.messages.AdminUsersStatusFilterButton); text != "" {
+		return text
+	}
+	return "Статус: %s"
+}
+
+func (h *MessageHandler) adminUsersRoleFilterButton() string {
+	if text := strings.TrimSpace(h.messages.AdminUsersRoleFilterButton); text != "" {
+		return text
+	}
+	return "Роль: %s"
+}
+
+func (h *MessageHandler) adminUsersEmptyText() string {
+	if text := strings.TrimSpace(h.messages.AdminUsersEmptyText); text != "" {
+		return text
+	}
+	return "Никого не нашлось."
+}
+
+func (h *MessageHandler) adminUsersSearchButton() string {
+	if text := strings.TrimSpace(h.messages.AdminUsersSearchButton); text != "" {
+		return text
+	}
+	return "🔎 Найти по ID"
+}
+
+func (h *MessageHandler) adminUsersSearchPrompt() string {
+	if text := strings.TrimSpace(h.messages.AdminUsersSearchPrompt); text != "" {
+		return text
+	}
+	return "Пришли MAX ID пользователя."
+}
+
+func (h *MessageHandler) adminUsersNotFoundText() string {
+	if text := strings.TrimSpace(h.messages.AdminUsersNotFoundText); text != "" {
+		return text
+	}
+	return "Пользователь с таким ID не найден."
+}
+
+func (h *MessageHandler) adminUsersErrorText() string {
+	if text := strings.TrimSpace(h.messages.AdminUsersErrorText); text != "" {
+		return text
+	}
+	return "Не удалось загрузить пользователей. Попробуй позже."
+}
+
+func (h *MessageHandler) adminUserDetailTemplate() string {
+	if text := strings.TrimSpace(h.messages.AdminUserDetailTemplate); text != "" {
+		return text
+	}
+	return "👤 *%s*\n\nMAX ID: %s\nРоль: %s\nСтатус: %s\nБаланс: %d добриков"
+}
+
+func (h *MessageHandler) adminUserDeactivateButton() string {
+	if text := strings.TrimSpace(h.messages.AdminUserDeactivateButton); text != "" {
+		return text
+	}
+	return "⛔ Заблокировать"
+}
+
+func (h *MessageHandler) adminUserActivateButton() string {
+	if text := strings.TrimSpace(h.messages.AdminUserActivateButton); text != "" {
+		return text
+	}
+	return "✅ Разблокировать"
+}
+
+func (h *MessageHandler) adminUserDeactivatedText() string {
+	if text := strings.TrimSpace(h.messages.AdminUserDeactivatedText); text != "" {
+		return text
+	}
+	return "⛔ Аккаунт заблокирован."
+}
+
+func (h *MessageHandler) adminUserActivatedText() string {
+	if text := strings.TrimSpace(h.messages.AdminUserActivatedText); text != "" {
+		return text
+	}
+	return "✅ Аккаунт разблокирован."
+}
+
+func (h *MessageHandler) adminUserSelfText() string {
+	if text := strings.TrimSpace(h.messages.AdminUserSelfText); text != "" {
+		return text
+	}
+	return "Свой аккаунт заблокировать нельзя."
+}
+
+func (h *MessageHandler) adminUserUpdateErrorText() string {
+	if text := strings.TrimSpace(h.messages.AdminUserUpdateErrorText); text != "" {
+		return text
+	}
+	return "Не удалось изменить статус. Попробуй ещё раз."
+}
+
+func (h *MessageHandler) adminUsersBackButton() string {
+	if text := strings.TrimSpace(h.messages.AdminUsersBackButton); text != "" {
+		return text
+	}
+	return "⬅️ К пользователям"
+}
+
+func (h *MessageHandler) adminCoinsDepositButton() string {
+	if text := strings.TrimSpace(h.messages.AdminCoinsDepositButton); text != "" {
+		return text
+	}
+	return "➕ Начислить"
+}
+
+func (h *MessageHandler) adminCoinsWithdrawButton() string {
+	if text := strings.TrimSpace(h.messages.AdminCoinsWithdrawButton); text != "" {
+		return text
+	}
+	return "➖ Списать"
+}
+
+func (h *MessageHandler) adminCoinsDepositPrompt() string {
+	if text := strings.TrimSpace(h.messages.AdminCoinsDepositPrompt); text != "" {
+		return text
+	}
+	return "Сколько добриков начислить пользователю %s? Пришли число."
+}
+
+func (h *MessageHandler) adminCoinsWithdrawPrompt() string {
+	if text := strings.TrimSpace(h.messages.AdminCoinsWithdrawPrompt); text != "" {
+		return text
+	}
+	return "Сколько добриков списать у пользователя %s? Пришли число."
+}
+
+func (h *MessageHandler) adminCoinsAmountInvalidText() string {
+	if text := strings.TrimSpace(h.messages.AdminCoinsAmountInvalidText); text != "" {
+		return text
+	}
+	return "Нужно целое число от 1 до %d."
+}
+
+func (h *MessageHandler) adminCoinsReasonPrompt() string {
+	if text := strings.TrimSpace(h.messages.AdminCoinsReasonPrompt); text != "" {
+		return text
+	}
+	return "Укажи причину — она попадёт в историю операций и в журнал."
+}
+
+func (h *MessageHandler) adminCoinsReasonInvalidText() string {
+	if text := strings.TrimSpace(h.messages.AdminCoinsReasonInvalidText); text != "" {
+		return text
+	}
+	return "Причина обязательна и должна быть не длиннее %d символов."
+}
+
+func (h *MessageHandler) adminCoinsDepositConfirmTemplate() string {
+	if text := strings.TrimSpace(h.messages.AdminCoinsDepositConfirmTemplate); text != "" {
+		return text
+	}
+	return "Начислить %d добриков пользователю %s?\n\nПричина: %s"
+}
+
+func (h *MessageHandler) adminCoinsWithdrawConfirmTemplate() string {
+	if text := strings.TrimSpace(h.messages.AdminCoinsWithdrawConfirmTemplate); text != "" {
+		return text
+	}
+	return "Списать %d добриков у пользователя %s?\n\nПричина: %s"
+}
+
+func (h *MessageHandler) adminCoinsConfirmButton() string {
+	if text := strings.TrimSpace(h.messages.AdminCoinsConfirmButton); text != "" {
+		return text
+	}
+	return "✅ Подтвердить"
+}
+
+func (h *MessageHandler) adminCoinsSuccessText() string {
+	if text := strings.TrimSpace(h.messages.AdminCoinsSuccessText); text != "" {
+		return text
+	}
+	return "✅ Баланс изменён."
+}
+
+func (h *MessageHandler) adminCoinsNotEnoughText() string {
+	if text := strings.TrimSpace(h.messages.AdminCoinsNotEnoughText); text != "" {
+		return text
+	}
+	return "На балансе недостаточно добриков для списания."
+}
+
+func (h *MessageHandler) adminCoinsErrorText() string {
+	if text := strings.TrimSpace(h.messages.AdminCoinsErrorText); text != "" {
+		return text
+	}
+	return "Не удалось изменить баланс. Попробуй ещё раз."
+}
+
+func (h *MessageHandler) adminCoinsDescriptionTemplate() string {
+	if text := strings.TrimSpace(h.messages.AdminCoinsDescriptionTemplate); text != "" {
+		return text
+	}
+	return "Корректировка администратором: %s"
+}
+
+func (h *MessageHandler) adminTasksTitle() string {
+	if text := strings.TrimSpace(h.messages.AdminTasksTitle); text != "" {
+		return text
+	}
+	return "📋 *Все дела*"
+}
+
+func (h *MessageHandler) adminTasksSummaryTemplate() string {
+	if text := strings.TrimSpace(h.messages.AdminTasksSummaryTemplate); text != "" {
+		return text
+	}
+	return "Всего: %d"
+}
+
+func (h *MessageHandler) adminTasksEmptyText() string {
+	if text := strings.TrimSpace(h.messages.AdminTasksEmptyText); text != "" {
+		return text
+	}
+	return "Дел пока нет."
+}
+
+func (h *MessageHandler) adminTasksErrorText() string {
+	if text := strings.TrimSpace(h.messages.AdminTasksErrorText); text != "" {
+		return text
+	}
+	return "Не удалось загрузить дела. Попробуй позже."
+}
+
+func (h *MessageHandler) adminTasksBackButton() string {
+	if text := strings.TrimSpace(h.messages.AdminTasksBackButton); text != "" {
+		return text
+	}
+	return "⬅️ К делам"
+}
+
+func (h *MessageHandler) adminTaskDetailTemplate() string {
+	if text := strings.TrimSpace(h.messages.AdminTaskDetailTemplate); text != "" {
+		return text
+	}
+	return "📋 *%s*\n\n%s\n\nЗаказчик: %s\nНаграда: %d\nОткликов: %d\nСоздано: %s"
+}
+
+func (h *MessageHandler) adminTaskDeleteButton() string {
+	if text := strings.TrimSpace(h.messages.AdminTaskDeleteButton); text != "" {
+		return text
+	}
+	return "🗑 Удалить дело"
+}
+
+func (h *MessageHandler) adminTaskDeleteAskTemplate() string {
+	if text := strings.TrimSpace(h.messages.AdminTaskDeleteAskTemplate); text != "" {
+		return text
+	}
+	return "Удалить дело «%s»?\n\nЗаказчик и откликнувшиеся волонтёры получат уведомление."
+}
+
+func (h *MessageHandler) adminTaskDeleteConfirmButton() string {
+	if text := strings.TrimSpace(h.messages.AdminTaskDeleteConfirmButton); text != "" {
+		return text
+	}
+	return "Да, удалить"
+}
+
+func (h *MessageHandler) adminTaskDeletedTemplate() string {
+	if text := strings.TrimSpace(h.messages.AdminTaskDeletedTemplate); text != "" {
+		return text
+	}
+	return "🗑 Дело «%s» удалено."
+}
+
+func (h *MessageHandler) adminTaskDeleteErrorText() string {
+	if text := strings.TrimSpace(h.messages.AdminTaskDeleteErrorText); text != "" {
+		return text
+	}
+	return "Не удалось удалить дело. Попробуй ещё раз."
+}
+
+func (h *MessageHandler) adminTaskDeletedNotification() string {
+	if text := strings.TrimSpace(h.messages.AdminTaskDeletedNotification); text != "" {
+		return text
+	}
+	return "Дело «%s» снято с публикации модератором. Если есть вопросы, напиши в поддержку."
+}
+
+func (h *MessageHandler) adminAuditTitle() string {
+	if text := strings.TrimSpace(h.messages.AdminAuditTitle); text != "" {
+		return text
+	}
+	return "📜 *Журнал действий*"
+}
+
+func (h *MessageHandler) adminAuditEmptyText() string {
+	if text := strings.TrimSpace(h.messages.AdminAuditEmptyText); text != "" {
+		return text
+	}
+	return "Пока ничего не происходило."
+}
+
+func (h *MessageHandler) adminAuditItemTemplate() string {
+	if text := strings.TrimSpace(h.messages.AdminAuditItemTemplate); text != "" {
+		return text
+	}
+	return "%s · %s\n%s"
+}
+
+func (h *MessageHandler) adminAuditUserDeactivated() string {
+	if text := strings.TrimSpace(h.messages.AdminAuditUserDeactivated); text != "" {
+		return text
+	}
+	return "заблокировал(а) %s"
+}
+
+func (h *MessageHandler) adminAuditUserActivated() string {
+	if text := strings.TrimSpace(h.messages.AdminAuditUserActivated); text != "" {
+		return text
+	}
+	return "разблокировал(а) %s"
+}
+
+func (h *MessageHandler) adminAuditCoinsDeposit() string {
+	if text := strings.TrimSpace(h.messages.AdminAuditCoinsDeposit); text != "" {
+		return text
+	}
+	return "начислил(а) %d добриков пользователю %s: %s"
+}
+
+func (h *MessageHandler) adminAuditCoinsWithdraw() string {
+	if text := strings.TrimSpace(h.messages.AdminAuditCoinsWithdraw); text != "" {
+		return text
+	}
+	return "списал(а) %d добриков у %s: %s"
+}
+
+func (h *MessageHandler) adminAuditTaskDeleted() string {
+	if text := strings.TrimSpace(h.messages.AdminAuditTaskDeleted); text != "" {
+		return text
+	}
+	return "удалил(а) дело «%s»"
+}
+
+func (h *MessageHandler) adminAuditPayoutRetry() string {
+	if text := strings.TrimSpace(h.messages.AdminAuditPayoutRetry); text != "" {
+		return text
+	}
+	return "повторил(а) выплату за «%s» для %s"
+}
+
+func (h *MessageHandler) adminAuditPayoutRevert() string {
+	if text := strings.TrimSpace(h.messages.AdminAuditPayoutRevert); text != "" {
+		return text
+	}
+	return "откатил(а) выплату за «%s» для %s"
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"DobrikaDev/max-bot/internal/storage"
+
+	"go.uber.org/zap"
+)
+
+// adminAuditSeqKey holds the number of the latest entry in the audit
+// bucket; entries are stored under their own numbers.
+const adminAuditSeqKey = "seq"
+
+const (
+	adminActionUserDeactivate = "admin_user_deactivate"
+	adminActionUserActivate   = "admin_user_activate"
+	adminActionCoinsDeposit   = "admin_coins_deposit"
+	adminActionCoinsWithdraw  = "admin_coins_withdraw"
+	adminActionTaskDelete     = "admin_task_delete"
+	adminActionPayoutRetry    = "payout_retry"
+	adminActionPayoutRevert   = "payout_revert"
+)
+
+// adminAuditEntry is one recorded admin action. Summary is rendered when the
+// action happens, so the log reads the same after the target is renamed or
+// deleted.
+type adminAuditEntry struct {
+	At      time.Time `json:"at"`
+	AdminID int64     `json:"admin_id"`
+	Action  string    `json:"action"`
+	Target  string    `json:"target"`
+	Summary string    `json:"summary"`
+}
+
+// adminAuditLog stores every admin action as its own never-expiring entry,
+// numbered in order, so recording one does not rewrite the others. The
+// trail is as durable as the store: with the memory backend it lasts until
+// restart, and the service log under the "audit" key is the full record.
+type adminAuditLog struct {
+	mu     sync.Mutex
+	store  storage.Store
+	logger *zap.Logger
+}
+
+func newAdminAuditLog(store storage.Store, logger *zap.Logger) *adminAuditLog {
+	return &adminAuditLog{store: store, logger: logger}
+}
+
+func (l *adminAuditLog) append(entry adminAuditEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		l.logger.Error("failed to encode admin audit entry", zap.Error(err))
+		return
+	}
+
+	seq := l.lastSeqLocked() + 1
+	if err := l.store.Set(sessionBucketAdminAudit, adminAuditKey(seq), data, 0); err != nil {
+		l.logger.Error("failed to save admin audit entry", zap.Error(err))
+		return
+	}
+	if err := l.store.Set(sessionBucketAdminAudit, adminAuditSeqKey, []byte(strconv.FormatUint(seq, 10)), 0); err != nil {
+		l.logger.Error("failed to save admin audit sequence", zap.Error(err))
+	}
+}
+
+// recent returns up to limit entries, newest first.
+func (l *adminAuditLog) recent(limit int) []adminAuditEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var entries []adminAuditEntry
+	for seq := l.lastSeqLocked(); seq > 0 && len(entries) < limit; seq-- {
+		data, ok, err := l.store.Get(sessionBucketAdminAudit, adminAuditKey(seq))
+		if err != nil {
+			l.logger.Warn("failed to load admin audit entry", zap.Error(err), zap.Uint64("seq", seq))
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		var entry adminAuditEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			l.logger.Warn("failed to decode admin audit entry", zap.Error(err), zap.Uint64("seq", seq))
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func (l *adminAuditLog) lastSeqLocked() uint64 {
+	data, ok, err := l.store.Get(sessionBucketAdminAudit, adminAuditSeqKey)
+	if err != nil || !ok {
+		if err != nil {
+			l.logger.Warn("failed to load admin audit sequence", zap.Error(err))
+		}
+		return 0
+	}
+
+	seq, err := strconv.ParseUint(string(data), 10, 64)
+	if err != nil {
+		l.logger.Warn("failed to decode admin audit sequence", zap.Error(err))
+		return 0
+	}
+	return seq
+}
+
+// adminAuditKey zero-pads the number so the keys sort in order.
+func adminAuditKey(seq uint64) string {
+	return fmt.Sprintf("%020d", seq)
+}
+
+// auditAdmin records an admin action that changed something, both in the
+// stored trail admins can read in the bot and in the service log under the
+// "audit" key.
+func (h *MessageHandler) auditAdmin(adminID int64, action, target, summary string, fields ...zap.Field) {
+	h.logger.Info("admin action", append([]zap.Field{
+		zap.String("audit", action),
+		zap.Int64("admin_id", adminID),
+		zap.String("target", target),
+		zap.String("summary", summary),
+	}, fields...)...)
+
+	h.adminAudit.append(adminAuditEntry{
+		At:      time.Now(),
+		AdminID: adminID,
+		Action:  action,
+		Target:  target,
+		Summary: summary,
+	})
+}
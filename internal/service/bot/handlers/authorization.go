@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"DobrikaDev/max-bot/internal/callbackrouter"
+	taskpb "DobrikaDev/max-bot/internal/generated/taskpb"
+	userpb "DobrikaDev/max-bot/internal/generated/userpb"
+
+	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
+	"go.uber.org/zap"
+)
+
+var (
+	errMalformedPayload     = errors.New("malformed payload")
+	errTaskNotFound         = errors.New("task not found")
+	errNotTaskOwner         = errors.New("user does not own the task")
+	errVolunteerNotAssigned = errors.New("volunteer is not assigned to the task")
+	errForeignCustomer      = errors.New("customer id does not belong to the user")
+	errNotAdmin             = errors.New("user is not an admin")
+)
+
+type callbackResource int
+
+const (
+	// resourceTask routes carry a taskID the customer must own.
+	resourceTask callbackResource = iota
+	// resourceAssignment routes carry a taskID and a volunteerID; the task
+	// must be owned by the customer and the volunteer must have responded
+	// to it.
+	resourceAssignment
+	// resourceCustomer routes carry a customerID that must be the clicking
+	// user's own.
+	resourceCustomer
+	// resourceAdmin routes may only be used by users with ROLE_ADMIN.
+	resourceAdmin
+)
+
+// Routes are protected by the namespace their pattern starts with, so a new
+// route is covered the moment it is added to the table.
+const (
+	// adminCallbackNamespace routes are limited to admins whatever they
+	// carry.
+	adminCallbackNamespace = "admin"
+	// customerCallbackNamespace routes act on the customer's own tasks and
+	// volunteers; their taskID, volunteerID and customerID parameters are
+	// checked. Other namespaces only act on the clicking user's own data.
+	customerCallbackNamespace = "customer"
+)
+
+// callbackTarget is the resource a protected callback acts on.
+type callbackTarget struct {
+	resource    callbackResource
+	taskID      string
+	volunteerID string
+	customerID  string
+}
+
+// parseCallbackTarget reads the resource a matched route acts on from its
+// parameters. ok is false for routes that are not protected.
+func parseCallbackTarget(route *callbackrouter.Route, param func(name string) string) (target callbackTarget, ok bool, err error) {
+	namespace, _, _ := strings.Cut(route.Prefix, ":")
+	switch namespace {
+	case adminCallbackNamespace:
+		return callbackTarget{resource: resourceAdmin}, true, nil
+	case customerCallbackNamespace:
+	default:
+		return target, false, nil
+	}
+
+	has := func(name string) bool { return slices.Contains(route.Params, name) }
+	target.taskID = strings.TrimSpace(param("taskID"))
+	target.volunteerID = strings.TrimSpace(param("volunteerID"))
+	target.customerID = strings.TrimSpace(param("customerID"))
+
+	switch {
+	case has("taskID") && has("volunteerID"):
+		target.resource = resourceAssignment
+		if target.taskID == "" || target.volunteerID == "" {
+			return target, true, errMalformedPayload
+		}
+	case has("taskID"):
+		target.resource = resourceTask
+		if target.taskID == "" {
+			return target, true, errMalformedPayload
+		}
+	case has("customerID"):
+		target.resource = resourceCustomer
+	default:
+		return target, false, nil
+	}
+	return target, true, nil
+}
+
+// checkTaskAccess decides whether userID may act on the target task. It is
+// kept free of service calls so the rules can be checked in isolation.
+func checkTaskAccess(task *taskpb.Task, target callbackTarget, userID string) error {
+	if task == nil {
+		return errTaskNotFound
+	}
+	if strings.TrimSpace(task.GetCustomerId()) != userID {
+		return errNotTaskOwner
+	}
+	if target.resource == resourceAssignment && assignmentStatusForUser(parseTaskAssignments(task), target.volunteerID) == "" {
+		return errVolunteerNotAssigned
+	}
+	return nil
+}
+
+// checkCustomerAccess allows an empty customer id, which handlers already
+// replace with the clicking user's own.
+func checkCustomerAccess(target callbackTarget, userID string) error {
+	if target.customerID != "" && target.customerID != userID {
+		return errForeignCustomer
+	}
+	return nil
+}
+
+// authorizeCallback checks that the clicking user owns whatever the matched
+// route points at. Denials are answered with a notification and written to
+// the audit log; the caller must stop handling the update when it returns
+// false.
+func (h *MessageHandler) authorizeCallback(ctx context.Context, req *callbackrouter.Request) bool {
+	callbackQuery := req.Update
+	target, protected, err := parseCallbackTarget(req.Route, req.Param)
+	if !protected {
+		return true
+	}
+
+	userID := fmt.Sprintf("%d", callbackQuery.Callback.User.UserId)
+
+	if err == nil {
+		switch target.resource {
+		case resourceCustomer:
+			err = checkCustomerAccess(target, userID)
+		case resourceAdmin:
+			if !h.isAdmin(ctx, callbackQuery.Callback.User.UserId) {
+				err = errNotAdmin
+			}
+		default:
+			var task *taskpb.Task
+			task, err = h.getTaskByID(ctx, target.taskID)
+			if err != nil {
+				// A lookup failure is not evidence of forgery; deny without
+				// raising an audit record.
+				h.logger.Error("failed to fetch task for authorization", zap.Error(err), zap.String("task_id", target.taskID))
+				h.denyCallback(ctx, callbackQuery)
+				return false
+			}
+			err = checkTaskAccess(task, target, userID)
+		}
+	}
+
+	if err == nil {
+		return true
+	}
+
+	h.logger.Warn("callback authorization denied",
+		zap.String("audit", "callback_authorization"),
+		zap.String("user_id", userID),
+		zap.String("payload", req.Payload),
+		zap.String("task_id", target.taskID),
+		zap.String("volunteer_id", target.volunteerID),
+		zap.String("customer_id", target.customerID),
+		zap.String("reason", err.Error()),
+	)
+	h.denyCallback(ctx, callbackQuery)
+	return false
+}
+
+func (h *MessageHandler) denyCallback(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate) {
+	if callbackQuery.Callback.CallbackID == "" || !callbackrouter.ClaimAnswer(ctx) {
+		return
+	}
+
+	answer := &schemes.CallbackAnswer{Notification: h.callbackForbiddenText()}
+	if _, err := h.api.Messages.AnswerOnCallback(ctx, callbackQuery.Callback.CallbackID, answer); err != nil && !isBenignAPIError(err) {
+		h.logger.Warn("failed to answer denied callback", zap.Error(err), zap.String("callback_id", callbackQuery.Callback.CallbackID))
+	}
+}
+
+func (h *MessageHandler) callbackForbiddenText() string {
+	if text := strings.TrimSpace(h.messages.CallbackForbiddenText); text != "" {
+		return text
+	}
+	return "Это действие тебе недоступно."
+}
+
+// isAdmin reports whether the user has ROLE_ADMIN. Lookup failures count as
+// not an admin.
+func (h *MessageHandler) isAdmin(ctx context.Context, userID int64) bool {
+	if h.user == nil || userID == 0 {
+		return false
+	}
+
+	resp, err := h.user.GetUserByMaxID(ctx, &userpb.GetUserByMaxIDRequest{MaxId: strconv.FormatInt(userID, 10)})
+	if err != nil {
+		h.logger.Warn("failed to check admin role", zap.Error(err), zap.Int64("user_id", userID))
+		return false
+	}
+	return resp.GetError() == nil && resp.GetUser().GetRole() == userpb.Role_ROLE_ADMIN
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"DobrikaDev/max-bot/internal/callbackrouter"
+	taskpb "DobrikaDev/max-bot/internal/generated/taskpb"
+
+	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+const (
+	testOwnerID     = "100"
+	testVolunteerID = "200"
+	testStrangerID  = "300"
+	testTaskID      = "42"
+)
+
+func testTask() *taskpb.Task {
+	return &taskpb.Task{
+		Id:         testTaskID,
+		CustomerId: testOwnerID,
+		Meta:       []*taskpb.Meta{{Key: "assignment", Value: `{"user_id":"` + testVolunteerID + `","status":"pending"}`}},
+	}
+}
+
+// stubTaskService serves testTask and nothing else.
+type stubTaskService struct {
+	taskpb.TaskServiceClient
+}
+
+func (stubTaskService) GetTaskByID(_ context.Context, req *taskpb.GetTaskByIDRequest, _ ...grpc.CallOption) (*taskpb.GetTaskByIDResponse, error) {
+	if req.GetId() != testTaskID {
+		return &taskpb.GetTaskByIDResponse{Error: &taskpb.Error{Code: taskpb.ErrorCode_ERROR_CODE_NOT_FOUND}}, nil
+	}
+	return &taskpb.GetTaskByIDResponse{Task: testTask()}, nil
+}
+
+// testRouter registers every pattern of the real route table with a handler
+// that only records it ran, behind the authorization middleware.
+func testRouter(t *testing.T, h *MessageHandler, handled *bool) *callbackrouter.Router {
+	t.Helper()
+
+	router := callbackrouter.New()
+	router.Use(h.authorizeMiddleware)
+	for _, route := range h.callbackRouteTable() {
+		router.Handle(route.pattern, func(context.Context, *callbackrouter.Request) error {
+			*handled = true
+			return nil
+		})
+	}
+	if err := router.Err(); err != nil {
+		t.Fatalf("route table: %v", err)
+	}
+	return router
+}
+
+func TestParseCallbackTarget(t *testing.T) {
+	h := &MessageHandler{}
+	var handled bool
+	router := testRouter(t, h, &handled)
+
+	tests := []struct {
+		payload   string
+		protected bool
+		want      callbackTarget
+		err       error
+	}{
+		{"customer:task:view:42", true, callbackTarget{resource: resourceTask, taskID: "42"}, nil},
+		{"customer:task:cancel:confirm:42", true, callbackTarget{resource: resourceTask, taskID: "42"}, nil},
+		{"customer:task:approve:42:200", true, callbackTarget{resource: resourceAssignment, taskID: "42", volunteerID: "200"}, nil},
+		{"customer:feedback:rate:42:200:5", true, callbackTarget{resource: resourceAssignment, taskID: "42", volunteerID: "200"}, nil},
+		{"customer:task:approve:42: ", true, callbackTarget{resource: resourceAssignment, taskID: "42"}, errMalformedPayload},
+		{"customer:task:view: ", true, callbackTarget{resource: resourceTask}, errMalformedPayload},
+		{"customer:tasks:page:300:0", true, callbackTarget{resource: resourceCustomer, customerID: "300"}, nil},
+		{"customer:tasks:page::0", true, callbackTarget{resource: resourceCustomer}, nil},
+		{"admin:task:42", true, callbackTarget{resource: resourceAdmin}, nil},
+		{"admin:menu", true, callbackTarget{resource: resourceAdmin}, nil},
+		{"customer:feedback:submit", false, callbackTarget{}, nil},
+		{"volunteer:task:view:42", false, callbackTarget{}, nil},
+		{"profile:data:export", false, callbackTarget{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.payload, func(t *testing.T) {
+			route, params, ok := router.Match(tt.payload)
+			if !ok {
+				t.Fatalf("no route matches %q", tt.payload)
+			}
+
+			got, protected, err := parseCallbackTarget(route, func(name string) string { return params[name] })
+			if protected != tt.protected {
+				t.Fatalf("protected = %v, want %v", protected, tt.protected)
+			}
+			if !errors.Is(err, tt.err) || (err == nil) != (tt.err == nil) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if protected && got != tt.want {
+				t.Fatalf("target = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckTaskAccess(t *testing.T) {
+	tests := []struct {
+		name   string
+		task   *taskpb.Task
+		target callbackTarget
+		userID string
+		err    error
+	}{
+		{"owner", testTask(), callbackTarget{resource: resourceTask, taskID: testTaskID}, testOwnerID, nil},
+		{"stranger", testTask(), callbackTarget{resource: resourceTask, taskID: testTaskID}, testStrangerID, errNotTaskOwner},
+		{"missing task", nil, callbackTarget{resource: resourceTask, taskID: "7"}, testOwnerID, errTaskNotFound},
+		{"assigned volunteer", testTask(), callbackTarget{resource: resourceAssignment, taskID: testTaskID, volunteerID: testVolunteerID}, testOwnerID, nil},
+		{"forged volunteer", testTask(), callbackTarget{resource: resourceAssignment, taskID: testTaskID, volunteerID: testStrangerID}, testOwnerID, errVolunteerNotAssigned},
+		{"volunteer as customer", testTask(), callbackTarget{resource: resourceAssignment, taskID: testTaskID, volunteerID: testVolunteerID}, testVolunteerID, errNotTaskOwner},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkTaskAccess(tt.task, tt.target, tt.userID); !errors.Is(err, tt.err) || (err == nil) != (tt.err == nil) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestCheckCustomerAccess(t *testing.T) {
+	tests := []struct {
+		name       string
+		customerID string
+		err        error
+	}{
+		{"own", testOwnerID, nil},
+		{"implicit own", "", nil},
+		{"foreign", testStrangerID, errForeignCustomer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkCustomerAccess(callbackTarget{resource: resourceCustomer, customerID: tt.customerID}, testOwnerID)
+			if !errors.Is(err, tt.err) || (err == nil) != (tt.err == nil) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestAuthorizeMiddleware(t *testing.T) {
+	h := &MessageHandler{logger: zap.NewNop(), task: stubTaskService{}}
+
+	tests := []struct {
+		name    string
+		payload string
+		userID  string
+		allowed bool
+	}{
+		{"owner views task", "customer:task:view:42", testOwnerID, true},
+		{"owner approves volunteer", "customer:task:approve:42:200", testOwnerID, true},
+		{"stranger views task", "customer:task:view:42", testStrangerID, false},
+		{"stranger approves volunteer", "customer:task:approve:42:200", testStrangerID, false},
+		{"volunteer approves themselves", "customer:task:approve:42:200", testVolunteerID, false},
+		{"forged volunteer", "customer:task:reject:42:300", testOwnerID, false},
+		{"unknown task", "customer:task:cancel:confirm:7", testOwnerID, false},
+		{"foreign task list", "customer:tasks:page:100:0", testStrangerID, false},
+		{"own task list", "customer:tasks:page:300:0", testStrangerID, true},
+		{"non-admin in admin menu", "admin:task:42", testOwnerID, false},
+		{"volunteer route", "volunteer:task:view:42", testStrangerID, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var handled bool
+			router := testRouter(t, h, &handled)
+
+			userID, _ := strconv.ParseInt(tt.userID, 10, 64)
+			update := &schemes.MessageCallbackUpdate{Callback: schemes.Callback{Payload: tt.payload, User: schemes.User{UserId: userID}}}
+
+			if err := router.Dispatch(context.Background(), update); err != nil {
+				t.Fatalf("dispatch: %v", err)
+			}
+			if handled != tt.allowed {
+				t.Fatalf("handled = %v, want %v", handled, tt.allowed)
+			}
+		})
+	}
+}
@@ -1,28 +1,32 @@
 package handlers
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
-	"net/url"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"DobrikaDev/max-bot/internal/callbackcodec"
+	"DobrikaDev/max-bot/internal/callbackrouter"
 	customerpb "DobrikaDev/max-bot/internal/generated/customerpb"
 	taskpb "DobrikaDev/max-bot/internal/generated/taskpb"
 	userpb "DobrikaDev/max-bot/internal/generated/userpb"
 	"DobrikaDev/max-bot/internal/locales"
+	"DobrikaDev/max-bot/internal/metrics"
+	"DobrikaDev/max-bot/internal/outbox"
+	"DobrikaDev/max-bot/internal/storage"
 	"DobrikaDev/max-bot/utils/config"
 
 	maxbot "github.com/max-messenger/max-bot-api-client-go"
 	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
@@ -58,18 +62,37 @@ type MessageHandler struct {
 	customer customerpb.CustomerServiceClient
 	task     taskpb.TaskServiceClient
 	messages locales.Messages
+	lang     string
+	format   string
+	views    *atomic.Pointer[languageViews]
+
+	userConn     *grpc.ClientConn
+	customerConn *grpc.ClientConn
+	taskConn     *grpc.ClientConn
 
 	sessions         *sessionStore
 	customerSessions *customerSessionStore
 	taskSessions     *taskSessionStore
 	menus            *menuStore
+	feedbackSessions *feedbackSessionStore
+	callbacks        *callbackcodec.Codec
+	router           *callbackrouter.Router
+	notifier         *taskNotifier
+	outbox           *outbox.Outbox
+	payouts          *payoutLog
+	adminSessions    *adminSessionStore
+	adminAudit       *adminAuditLog
+	accounts         *accountCache
+	languages        sessionTable[languagePreference]
+	localeHints      sessionTable[localeHint]
+	overrides        *localeWatcher
 
 	httpClient *http.Client
 	apiBaseURL string
 	apiVersion string
 }
 
-const messageFormatMarkdown = "markdown"
+const defaultAPIBaseURL = "https://botapi.max.ru"
 
 type messageEditPayload struct {
 	Text        string        `json:"text,omitempty"`
@@ -77,25 +100,70 @@ type messageEditPayload struct {
 	Attachments []interface{} `json:"attachments"`
 }
 
-func NewMessageHandler(api *maxbot.Api, cfg *config.Config, logger *zap.Logger) *MessageHandler {
+// HandlerOption adjusts how NewMessageHandler wires the handler.
+type HandlerOption func(*handlerOptions)
+
+type handlerOptions struct {
+	dialOptions []grpc.DialOption
+}
+
+// WithDialOptions adds options to every service connection, e.g. a custom
+// dialer for in-process servers.
+func WithDialOptions(opts ...grpc.DialOption) HandlerOption {
+	return func(o *handlerOptions) {
+		o.dialOptions = append(o.dialOptions, opts...)
+	}
+}
+
+func NewMessageHandler(api *maxbot.Api, cfg *config.Config, logger *zap.Logger, store storage.Store, opts ...HandlerOption) *MessageHandler {
+	var options handlerOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	handler := &MessageHandler{
 		api:              api,
 		cfg:              cfg,
 		logger:           logger,
-		sessions:         newSessionStore(),
-		customerSessions: newCustomerSessionStore(),
-		taskSessions:     newTaskSessionStore(),
-		menus:            newMenuStore(),
+		sessions:         newSessionStore(store, cfg.SessionTTL, logger),
+		customerSessions: newCustomerSessionStore(store, cfg.SessionTTL, logger),
+		taskSessions:     newTaskSessionStore(store, cfg.SessionTTL, logger),
+		menus:            newMenuStore(store, cfg.SessionTTL, logger),
+		feedbackSessions: newFeedbackSessionStore(store, cfg.SessionTTL, logger),
+		adminSessions:    newAdminSessionStore(store, cfg.SessionTTL, logger),
+		adminAudit:       newAdminAuditLog(store, logger),
+		accounts:         newAccountCache(cfg.AccountStatusTTL),
+		lang:             locales.DefaultLanguage,
+		format:           messageFormat(cfg.MessageFormat),
+		views:            new(atomic.Pointer[languageViews]),
+		languages:        newPersistentTable[languagePreference](store, sessionBucketLanguage, logger),
+		localeHints:      newSessionTable[localeHint](store, sessionBucketLocaleHint, cfg.SessionTTL, logger),
 		httpClient:       &http.Client{Timeout: 10 * time.Second},
-		apiBaseURL:       "https://botapi.max.ru",
+		apiBaseURL:       defaultAPIBaseURL,
 		apiVersion:       "1.2.5",
 	}
 
-	msgs, err := locales.Load()
+	if cfg.MaxAPIURL != "" {
+		handler.apiBaseURL = cfg.MaxAPIURL
+	}
+
+	router, err := handler.newCallbackRouter()
+	if err != nil {
+		logger.Fatal("invalid callback route table", zap.Error(err))
+	}
+	handler.router = router
+	handler.outbox = handler.newMessageOutbox(store)
+	handler.payouts = newPayoutLog(store, logger, handler.runPayout)
+	handler.callbacks = newCallbackCodec(cfg.CallbackSecret, cfg.MaxToken, cfg.CallbackTTL, callbackPrefixes(router), logger)
+
+	bundles, err := locales.LoadAll()
 	if err != nil {
 		logger.Warn("failed to load locales", zap.Error(err))
 	}
-	handler.messages = msgs
+	for _, problem := range locales.Validate() {
+		logger.Warn("locale problem", zap.String("language", problem.Language), zap.String("key", problem.Key), zap.String("problem", problem.Message))
+	}
+	handler.messages = bundles[locales.DefaultLanguage]
 
 	if cfg.MaxToken == "" {
 		logger.Warn("MAX token is empty; message editing will fail")
@@ -103,33 +171,120 @@ func NewMessageHandler(api *maxbot.Api, cfg *config.Config, logger *zap.Logger)
 
 	if cfg.UserServiceURL == "" {
 		logger.Warn("user service URL is not configured; registration completion will be skipped")
-	} else if conn, err := grpc.Dial(cfg.UserServiceURL, grpc.WithTransportCredentials(insecure.NewCredentials())); err != nil {
+	} else if conn, err := grpc.Dial(cfg.UserServiceURL, dialOptions(options.dialOptions...)...); err != nil {
 		logger.Error("failed to connect to user service", zap.Error(err))
 	} else {
+		handler.userConn = conn
 		handler.user = userpb.NewUserServiceClient(conn)
 	}
 
 	if cfg.CustomerServiceURL == "" {
 		logger.Warn("customer service URL is not configured; need help flow will be disabled")
-	} else if conn, err := grpc.Dial(cfg.CustomerServiceURL, grpc.WithTransportCredentials(insecure.NewCredentials())); err != nil {
+	} else if conn, err := grpc.Dial(cfg.CustomerServiceURL, dialOptions(options.dialOptions...)...); err != nil {
 		logger.Error("failed to connect to customer service", zap.Error(err))
 	} else {
+		handler.customerConn = conn
 		handler.customer = customerpb.NewCustomerServiceClient(conn)
 	}
 
 	if cfg.TaskServiceURL == "" {
 		logger.Warn("task service URL is not configured; task features will be disabled")
-	} else if conn, err := grpc.Dial(cfg.TaskServiceURL, grpc.WithTransportCredentials(insecure.NewCredentials())); err != nil {
+	} else if conn, err := grpc.Dial(cfg.TaskServiceURL, dialOptions(options.dialOptions...)...); err != nil {
 		logger.Error("failed to connect to task service", zap.Error(err))
 	} else {
+		handler.taskConn = conn
 		handler.task = taskpb.NewTaskServiceClient(conn)
 	}
 
+	handler.notifier = newTaskNotifier(store, cfg.NotifyDigestWindow, cfg.NotifyTimezone, logger, handler.sendTaskEvents)
+
+	// Views copy the handler, so they are made once every field is set.
+	handler.setLanguageViews(bundles)
+	if cfg.LocaleDir != "" {
+		handler.watchLocales(cfg.LocaleDir)
+	}
+
+	handler.notifier.resume()
+	handler.outbox.Resume()
+	handler.payouts.resume()
+
 	return handler
 }
 
+func dialOptions(extra ...grpc.DialOption) []grpc.DialOption {
+	return append([]grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(metrics.UnaryClientInterceptor()),
+	}, extra...)
+}
+
+func (h *MessageHandler) serviceConns() map[string]*grpc.ClientConn {
+	return map[string]*grpc.ClientConn{
+		"user":     h.userConn,
+		"customer": h.customerConn,
+		"task":     h.taskConn,
+	}
+}
+
+// CheckServices reports an error for every configured service connection
+// that is not usable right now. Idle connections are asked to reconnect and
+// count as ready, since the next call will bring them up.
+func (h *MessageHandler) CheckServices() error {
+	var errs []error
+	for name, conn := range h.serviceConns() {
+		if conn == nil {
+			continue
+		}
+
+		switch state := conn.GetState(); state {
+		case connectivity.Ready:
+		case connectivity.Idle:
+			conn.Connect()
+		default:
+			errs = append(errs, fmt.Errorf("%s service connection is %s", name, strings.ToLower(state.String())))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close stops the locale watcher, pending notifications, payout and outbound
+// retries and releases the gRPC connections opened in NewMessageHandler.
+// Queued work stays in the store for the next start.
+func (h *MessageHandler) Close() error {
+	if h.overrides != nil {
+		h.overrides.close()
+	}
+	if h.notifier != nil {
+		h.notifier.close()
+	}
+	if h.payouts != nil {
+		h.payouts.close()
+	}
+	if h.outbox != nil {
+		h.outbox.Close()
+	}
+
+	var errs []error
+	for name, conn := range h.serviceConns() {
+		if conn == nil {
+			continue
+		}
+		if err := conn.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close %s service connection: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
 func (h *MessageHandler) HandleMessage(ctx context.Context, message *schemes.MessageCreatedUpdate) {
 	h.logger.Info("Received message", zap.Any("message", message))
+	h = h.forUser(message.Message.Sender.UserId)
+
+	if !h.accountGate(ctx, message.Message.Recipient.ChatId, message.Message.Sender.UserId, "") {
+		return
+	}
 
 	if !h.ensureUserContext(ctx, message) {
 		return
@@ -143,6 +298,10 @@ func (h *MessageHandler) HandleMessage(ctx context.Context, message *schemes.Mes
 		return
 	}
 
+	if h.tryHandleFeedbackMessage(ctx, message) {
+		return
+	}
+
 	if h.tryHandleCustomerMessage(ctx, message) {
 		return
 	}
@@ -151,11 +310,27 @@ func (h *MessageHandler) HandleMessage(ctx context.Context, message *schemes.Mes
 		return
 	}
 
+	if h.tryHandleAdminMessage(ctx, message) {
+		return
+	}
+
 	if h.isRegistrationTrigger(message) {
 		h.startRegistration(ctx, message.Message.Sender.UserId, message.Message.Recipient.ChatId, message.Message.Sender.Name, "")
 		return
 	}
 
+	if h.isAdminCommand(message) && h.isAdmin(ctx, message.Message.Sender.UserId) {
+		h.menus.delete(message.Message.Recipient.ChatId)
+		h.showAdminMenu(ctx, message.Message.Recipient.ChatId, message.Message.Sender.UserId)
+		return
+	}
+
+	if h.isAdminPayoutsCommand(message) && h.isAdmin(ctx, message.Message.Sender.UserId) {
+		h.menus.delete(message.Message.Recipient.ChatId)
+		h.showAdminPayouts(ctx, message.Message.Recipient.ChatId, message.Message.Sender.UserId)
+		return
+	}
+
 	if h.isStartCommand(message) {
 		h.menus.delete(message.Message.Recipient.ChatId)
 		h.SendMainMenu(ctx, message.Message.Recipient.ChatId, message.Message.Sender.UserId)
@@ -164,30 +339,21 @@ func (h *MessageHandler) HandleMessage(ctx context.Context, message *schemes.Mes
 }
 func (h *MessageHandler) HandleCallbackQuery(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate) {
 	h.logger.Info("Received callback query", zap.Any("callbackQuery", callbackQuery))
-	if h.tryHandleRegistrationCallback(ctx, callbackQuery) {
-		return
-	}
-
-	if h.tryHandleCustomerCallback(ctx, callbackQuery) {
+	h = h.forUser(callbackQuery.Callback.User.UserId)
+	if !h.openCallback(ctx, callbackQuery) {
 		return
 	}
+	metrics.ObserveCallback(callbackQuery.Callback.Payload)
 
-	if h.tryHandleTaskCreationCallback(ctx, callbackQuery) {
-		return
+	chatID := callbackQuery.Callback.User.UserId
+	if callbackQuery.Message != nil {
+		chatID = callbackQuery.Message.Recipient.ChatId
 	}
-
-	if h.handleMainMenuCallback(ctx, callbackQuery) {
+	if !h.accountGate(ctx, chatID, callbackQuery.Callback.User.UserId, callbackQuery.Callback.CallbackID) {
 		return
 	}
 
-	if callbackQuery.Message != nil {
-		chatID := callbackQuery.Message.Recipient.ChatId
-		userID := callbackQuery.Message.Recipient.UserId
-		if callbackQuery.Message.Body.Mid != "" {
-			h.menus.set(chatID, callbackQuery.Message.Body.Mid, userID)
-		}
-		h.SendMainMenu(ctx, chatID, userID)
-	}
+	h.dispatchCallback(ctx, callbackQuery)
 }
 
 func (h *MessageHandler) SendMainMenu(ctx context.Context, chatID, userID int64, intro ...string) {
@@ -204,6 +370,11 @@ func (h *MessageHandler) SendMainMenu(ctx context.Context, chatID, userID int64,
 	keyboard.AddRow().
 		AddCallback(h.messages.MainMenuButtons[3], schemes.DEFAULT, callbackMainMenuAbout)
 
+	if h.isAdmin(ctx, userID) {
+		keyboard.AddRow().
+			AddCallback(h.adminMenuButton(), schemes.DEFAULT, callbackAdminMenu)
+	}
+
 	h.renderMenu(ctx, chatID, userID, text, keyboard)
 }
 
@@ -309,55 +480,68 @@ func (h *MessageHandler) SendJoinMenu(ctx context.Context, chatID, userID int64)
 }
 
 func (h *MessageHandler) renderMenu(ctx context.Context, chatID, userID int64, text string, keyboard *maxbot.Keyboard) {
+	result := metrics.MenuSend
+	if parts := h.splitText(text); len(parts) > 1 {
+		// The head goes out as messages of its own and the menu comes last,
+		// under them, so it cannot stay in the message it was in.
+		h.menus.delete(chatID)
+		h.sendLeadingParts(ctx, chatID, userID, parts[:len(parts)-1])
+		text = parts[len(parts)-1]
+	}
 	if entry, ok := h.menus.get(chatID); ok && entry.MessageID != "" {
 		if err := h.editInteractiveMessage(ctx, chatID, entry.UserID, entry.MessageID, text, keyboard); err == nil {
 			h.menus.set(chatID, entry.MessageID, userID)
+			metrics.ObserveMenuRender(metrics.MenuEdit)
 			return
 		} else {
-			if isRetryableMessageError(err) {
-				h.logger.Warn("deferring menu update due to retryable error", zap.Error(err), zap.Int64("chat_id", chatID))
+			if errors.Is(err, outbox.ErrDeferred) {
+				h.logger.Warn("menu update queued for retry", zap.Error(err), zap.Int64("chat_id", chatID))
+				metrics.ObserveMenuRender(metrics.MenuDeferred)
 				return
 			}
 
 			h.logger.Warn("failed to update menu message", zap.Error(err), zap.Int64("chat_id", chatID))
 			h.menus.delete(chatID)
+			result = metrics.MenuFallback
 		}
 	}
 
-	messageID, err := h.sendInteractiveMessage(ctx, chatID, userID, text, keyboard)
+	messageID, err := h.sendMessage(ctx, chatID, userID, h.buildMessageBody(text, keyboard), outboundTagMenu)
 	if err != nil {
-		if isRetryableMessageError(err) {
-			h.logger.Warn("deferring menu send due to retryable error", zap.Error(err), zap.Int64("chat_id", chatID))
+		if errors.Is(err, outbox.ErrDeferred) {
+			// The menu is recorded once the message goes out, see onOutboundDelivered.
+			h.logger.Warn("menu send queued for retry", zap.Error(err), zap.Int64("chat_id", chatID))
+			metrics.ObserveMenuRender(metrics.MenuDeferred)
 			return
 		}
 
 		h.logger.Error("failed to send menu message", zap.Error(err), zap.Int64("chat_id", chatID))
+		metrics.ObserveMenuRender(metrics.MenuFailed)
 		return
 	}
 
 	h.menus.set(chatID, messageID, userID)
+	metrics.ObserveMenuRender(result)
 }
 
+// sendInteractiveMessage sends text with keyboard under it and returns the
+// id of the message that carries the keyboard. A text too long for one
+// message is sent in parts with the keyboard on the last one.
 func (h *MessageHandler) sendInteractiveMessage(ctx context.Context, chatID, userID int64, text string, keyboard *maxbot.Keyboard) (string, error) {
-	msg := maxbot.NewMessage().
-		SetChat(chatID).
-		SetText(text).
-		SetFormat(messageFormatMarkdown)
-
-	if userID != 0 {
-		msg.SetUser(userID)
-	}
-
-	if keyboard != nil {
-		msg.AddKeyboard(keyboard)
-	}
-
-	sent, err := h.api.Messages.SendMessageResult(ctx, msg)
-	if err != nil {
-		return "", err
+	parts := h.splitText(text)
+	h.sendLeadingParts(ctx, chatID, userID, parts[:len(parts)-1])
+	return h.sendMessage(ctx, chatID, userID, h.buildMessageBody(parts[len(parts)-1], keyboard), "")
+}
+
+// sendLeadingParts sends the parts of a split text that come before the one
+// with the keyboard. The outbox keeps a chat's messages in order, so a part
+// that is deferred still goes out before the rest.
+func (h *MessageHandler) sendLeadingParts(ctx context.Context, chatID, userID int64, parts []string) {
+	for _, part := range parts {
+		if _, err := h.sendMessage(ctx, chatID, userID, h.buildMessageBody(part, nil), ""); err != nil && !errors.Is(err, outbox.ErrDeferred) {
+			h.logger.Error("failed to send message part", zap.Error(err), zap.Int64("chat_id", chatID))
+		}
 	}
-
-	return sent.Body.Mid, nil
 }
 
 func (h *MessageHandler) editInteractiveMessage(ctx context.Context, chatID, userID int64, messageID, text string, keyboard *maxbot.Keyboard) error {
@@ -366,16 +550,17 @@ func (h *MessageHandler) editInteractiveMessage(ctx context.Context, chatID, use
 	}
 
 	body := h.buildMessageBody(text, keyboard)
-	return h.editMessageRaw(ctx, messageID, body)
+	return h.editMessageRaw(ctx, chatID, messageID, body)
 }
 
 func (h *MessageHandler) buildMessageBody(text string, keyboard *maxbot.Keyboard) *messageEditPayload {
 	payload := &messageEditPayload{
-		Text:   text,
-		Format: messageFormatMarkdown,
+		Text:   h.formatText(text),
+		Format: h.format,
 	}
 
 	if keyboard != nil {
+		h.sealKeyboard(keyboard)
 		payload.Attachments = []interface{}{schemes.NewInlineKeyboardAttachmentRequest(keyboard.Build())}
 	} else {
 		payload.Attachments = []interface{}{}
@@ -384,162 +569,54 @@ func (h *MessageHandler) buildMessageBody(text string, keyboard *maxbot.Keyboard
 	return payload
 }
 
-func (h *MessageHandler) editMessageRaw(ctx context.Context, messageID string, body *messageEditPayload) error {
-	if h.cfg.MaxToken == "" {
-		return fmt.Errorf("max token is empty")
+// sendMessage queues a new message for the chat and returns its id once it
+// is sent. An error wrapping outbox.ErrDeferred means it is still queued.
+func (h *MessageHandler) sendMessage(ctx context.Context, chatID, userID int64, body *messageEditPayload, tag string) (string, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal message body: %w", err)
 	}
 
+	return h.outbox.Send(ctx, &outbox.Message{
+		Kind:   outbox.KindSend,
+		ChatID: chatID,
+		UserID: userID,
+		Tag:    tag,
+		Body:   data,
+	})
+}
+
+// editMessageRaw queues an edit of messageID behind the chat's other
+// outbound messages.
+func (h *MessageHandler) editMessageRaw(ctx context.Context, chatID int64, messageID string, body *messageEditPayload) error {
 	if body == nil {
 		body = &messageEditPayload{
 			Attachments: []interface{}{},
 		}
 	}
 
-	query := url.Values{}
-	query.Set("message_id", messageID)
-	query.Set("access_token", h.cfg.MaxToken)
-	query.Set("v", h.apiVersion)
-
-	u := h.apiBaseURL
-	if !strings.HasSuffix(u, "/") {
-		u += "/"
-	}
-	u += "messages"
-
 	data, err := json.Marshal(body)
 	if err != nil {
 		return fmt.Errorf("failed to marshal message body: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPut, fmt.Sprintf("%s?%s", u, query.Encode()), bytes.NewReader(data))
-	if err != nil {
-		return fmt.Errorf("failed to create edit request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "max-bot-dynamic-menu/1.0")
-
-	resp, err := h.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to execute edit request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("http %d: %s", resp.StatusCode, strings.TrimSpace(string(bodyBytes)))
-	}
-
-	var result schemes.SimpleQueryResult
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return fmt.Errorf("failed to decode edit response: %w", err)
-	}
-
-	if !result.Success {
-		return fmt.Errorf("edit response unsuccessful: %s", result.Message)
-	}
-
-	return nil
+	_, err = h.outbox.Send(ctx, &outbox.Message{
+		Kind:      outbox.KindEdit,
+		ChatID:    chatID,
+		MessageID: messageID,
+		Body:      data,
+	})
+	return err
 }
 
-func (h *MessageHandler) handleMainMenuCallback(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate) bool {
-	payload := callbackQuery.Callback.Payload
-	if payload == "" || callbackQuery.Message == nil {
-		return false
-	}
-
-	chatID := callbackQuery.Message.Recipient.ChatId
-	userID := callbackQuery.Callback.User.UserId
-
-	switch {
-	case strings.HasPrefix(payload, callbackVolunteerTasksFilter+":"):
-		h.handleVolunteerTasksFilter(ctx, callbackQuery, strings.TrimPrefix(payload, callbackVolunteerTasksFilter+":"))
-		return true
-	case strings.HasPrefix(payload, callbackVolunteerTasksPage+":"):
-		h.handleVolunteerTasksPage(ctx, callbackQuery, strings.TrimPrefix(payload, callbackVolunteerTasksPage+":"))
-		return true
-	case strings.HasPrefix(payload, callbackVolunteerTaskView+":"):
-		h.handleVolunteerTaskView(ctx, callbackQuery, strings.TrimPrefix(payload, callbackVolunteerTaskView+":"))
-		return true
-	case strings.HasPrefix(payload, callbackVolunteerTaskJoin+":"):
-		h.handleVolunteerTaskJoin(ctx, callbackQuery, strings.TrimPrefix(payload, callbackVolunteerTaskJoin+":"))
-		return true
-	case strings.HasPrefix(payload, callbackVolunteerTaskLeave+":"):
-		h.handleVolunteerTaskLeave(ctx, callbackQuery, strings.TrimPrefix(payload, callbackVolunteerTaskLeave+":"))
-		return true
-	case strings.HasPrefix(payload, callbackVolunteerTaskConfirm+":"):
-		h.handleVolunteerTaskConfirm(ctx, callbackQuery, strings.TrimPrefix(payload, callbackVolunteerTaskConfirm+":"))
-		return true
-	case strings.HasPrefix(payload, callbackCustomerTasksPage+":"):
-		h.handleCustomerTasksPage(ctx, callbackQuery, strings.TrimPrefix(payload, callbackCustomerTasksPage+":"))
-		return true
-	case strings.HasPrefix(payload, callbackCustomerTaskView+":"):
-		h.handleCustomerTaskView(ctx, callbackQuery, strings.TrimPrefix(payload, callbackCustomerTaskView+":"))
-		return true
-	case strings.HasPrefix(payload, callbackCustomerTaskAssignment+":"):
-		h.handleCustomerTaskAssignment(ctx, callbackQuery, strings.TrimPrefix(payload, callbackCustomerTaskAssignment+":"))
-		return true
-	case strings.HasPrefix(payload, callbackCustomerTaskApprove+":"):
-		h.handleCustomerTaskApprove(ctx, callbackQuery, strings.TrimPrefix(payload, callbackCustomerTaskApprove+":"))
-		return true
-	case strings.HasPrefix(payload, callbackCustomerTaskReject+":"):
-		h.handleCustomerTaskReject(ctx, callbackQuery, strings.TrimPrefix(payload, callbackCustomerTaskReject+":"))
-		return true
-	}
-
-	switch payload {
-	case callbackMainMenuProfile:
-		h.showProfile(ctx, chatID, userID)
-	case callbackMainMenuHelp:
-		h.showVolunteerMenu(ctx, chatID, userID)
-	case callbackMainMenuAbout:
-		h.showAboutDobrikaMenu(ctx, chatID, userID)
-	case callbackVolunteerOnDemand:
-		h.showVolunteerTasksList(ctx, chatID, userID, volunteerTasksViewModeOnDemand, volunteerTasksFilterAll, "", 0)
-	case callbackVolunteerTasks:
-		h.showVolunteerTasksList(ctx, chatID, userID, volunteerTasksViewModeAll, volunteerTasksFilterAll, "", 0)
-	case callbackVolunteerLocationSkip:
-		h.handleVolunteerLocationSkip(ctx, chatID, userID)
-	case callbackVolunteerBack:
-		h.showVolunteerMenu(ctx, chatID, userID)
-	case callbackProfileCoins:
-		h.showProfileCoinsMenu(ctx, chatID, userID)
-	case callbackProfileHistory:
-		h.showProfileHistory(ctx, chatID, userID)
-	case callbackProfileEdit:
-		h.showProfileEdit(ctx, chatID, userID)
-	case callbackProfileSecurity:
-		h.showProfileSecurity(ctx, chatID, userID)
-	case callbackProfileBack:
-		h.SendMainMenu(ctx, chatID, userID)
-	case callbackCoinsHowToGet:
-		h.showCoinsHowToGet(ctx, chatID, userID)
-	case callbackCoinsHowToSpend:
-		h.showCoinsHowToSpend(ctx, chatID, userID)
-	case callbackCoinsLevels:
-		h.showCoinsLevels(ctx, chatID, userID)
-	case callbackAboutHowItWorks:
-		h.renderMenu(ctx, chatID, userID, h.messages.AboutDobrikaHowText, h.aboutMenuKeyboard())
-	case callbackAboutRules:
-		h.renderMenu(ctx, chatID, userID, h.messages.AboutDobrikaRulesText, h.aboutMenuKeyboard())
-	case callbackAboutInitiator:
-		h.renderMenu(ctx, chatID, userID, h.messages.AboutDobrikaInitiatorText, h.aboutMenuKeyboard())
-	case callbackAboutSupport:
-		h.renderMenu(ctx, chatID, userID, h.messages.AboutDobrikaSupportText, h.aboutMenuKeyboard())
-	case callbackAboutBack:
-		h.SendMainMenu(ctx, chatID, userID)
-	default:
-		return false
-	}
-
-	h.answerCallback(ctx, callbackQuery.Callback.CallbackID)
-	return true
-}
-
-func (h *MessageHandler) showProfile(ctx context.Context, chatID, userID int64) {
+func (h *MessageHandler) showProfile(ctx context.Context, chatID, userID int64, intro ...string) {
 	text, err := h.buildProfileText(ctx, userID)
 	if err != nil {
 		h.logger.Error("failed to build profile text", zap.Error(err), zap.Int64("user_id", userID))
-		text = "Не удалось получить данные профиля. Попробуйте позже."
+		text = h.profileLoadErrorText()
+	}
+	if len(intro) > 0 && strings.TrimSpace(intro[0]) != "" {
+		text = strings.TrimSpace(intro[0]) + "\n\n" + text
 	}
 
 	keyboard := h.api.Messages.NewKeyboardBuilder()
@@ -549,6 +626,11 @@ func (h *MessageHandler) showProfile(ctx context.Context, chatID, userID int64)
 	keyboard.AddRow().
 		AddCallback(h.messages.ProfileCoinsButton, schemes.DEFAULT, callbackProfileCoins).
 		AddCallback(h.messages.ProfileSecurityButton, schemes.DEFAULT, callbackProfileSecurity)
+	keyboard.AddRow().
+		AddCallback(h.messages.ProfileDataButton, schemes.DEFAULT, callbackProfileData).
+		AddCallback(h.notificationSettingsButton(), schemes.DEFAULT, callbackProfileNotify)
+	keyboard.AddRow().
+		AddCallback(h.profileLanguageButton(), schemes.DEFAULT, callbackProfileLanguage)
 	keyboard.AddRow().
 		AddCallback(h.messages.ProfileBackButton, schemes.DEFAULT, callbackProfileBack)
 
@@ -594,34 +676,26 @@ func (h *MessageHandler) volunteerBackKeyboard() *maxbot.Keyboard {
 func (h *MessageHandler) lookupUserName(ctx context.Context, maxID string) string {
 	maxID = strings.TrimSpace(maxID)
 	if maxID == "" {
-		return "Неизвестный волонтёр"
+		return h.userNameUnknown()
 	}
 
 	if h.user == nil {
-		return fmt.Sprintf("Пользователь %s", maxID)
+		return fmt.Sprintf(h.userNameFallbackTemplate(), maxID)
 	}
 
 	resp, err := h.user.GetUserByMaxID(ctx, &userpb.GetUserByMaxIDRequest{MaxId: maxID})
 	if err != nil || resp.GetError() != nil || resp.GetUser() == nil {
-		return fmt.Sprintf("Пользователь %s", maxID)
+		return fmt.Sprintf(h.userNameFallbackTemplate(), maxID)
 	}
 
 	name := strings.TrimSpace(resp.GetUser().GetName())
 	if name == "" {
-		return fmt.Sprintf("Пользователь %s", maxID)
+		return fmt.Sprintf(h.userNameFallbackTemplate(), maxID)
 	}
 
 	return name
 }
 
-func (h *MessageHandler) showProfileHistory(ctx context.Context, chatID, userID int64) {
-	h.renderMenu(ctx, chatID, userID, h.messages.ProfileHistoryText, h.singleButtonKeyboard(h.messages.ProfileBackButton, callbackProfileBack))
-}
-
-func (h *MessageHandler) showProfileEdit(ctx context.Context, chatID, userID int64) {
-	h.renderMenu(ctx, chatID, userID, h.messages.ProfileEditText, h.singleButtonKeyboard(h.messages.ProfileBackButton, callbackProfileBack))
-}
-
 func (h *MessageHandler) showProfileSecurity(ctx context.Context, chatID, userID int64) {
 	text := fmt.Sprintf("%s\n\n%s", h.messages.ProfileSecurityTitle, fmt.Sprintf(h.messages.ProfileSecurityText, h.messages.ProfileSecuritySOSLink))
 
@@ -658,13 +732,16 @@ func (h *MessageHandler) buildProfileText(ctx context.Context, userID int64) (st
 	builder.WriteString("\n\n")
 
 	if name := strings.TrimSpace(user.GetName()); name != "" {
-		builder.WriteString(fmt.Sprintf("*Имя:* %s\n", name))
+		builder.WriteString(fmt.Sprintf(h.profileNameTemplate(), escapeMarkdown(name)))
+		builder.WriteString("\n")
 	}
 	if age := user.GetAge(); age > 0 {
-		builder.WriteString(fmt.Sprintf("*Возраст:* %d\n", age))
+		builder.WriteString(fmt.Sprintf(h.profileAgeTemplate(), age))
+		builder.WriteString("\n")
 	}
 	if city := strings.TrimSpace(user.GetGeolocation()); city != "" {
-		builder.WriteString(fmt.Sprintf("*Город:* %s\n", city))
+		builder.WriteString(fmt.Sprintf(h.profileCityTemplate(), escapeMarkdown(city)))
+		builder.WriteString("\n")
 	}
 
 	builder.WriteString("\n")
@@ -676,7 +753,7 @@ func (h *MessageHandler) buildProfileText(ctx context.Context, userID int64) (st
 		for _, chunk := range strings.Split(about, ";") {
 			if item := strings.TrimSpace(chunk); item != "" {
 				builder.WriteString("• ")
-				builder.WriteString(item)
+				builder.WriteString(escapeMarkdown(item))
 				builder.WriteString("\n")
 			}
 		}
@@ -684,7 +761,7 @@ func (h *MessageHandler) buildProfileText(ctx context.Context, userID int64) (st
 		builder.WriteString("\n")
 	}
 
-	level := "Новичок"
+	level := h.profileDefaultLevel()
 	if group := user.GetReputationGroup(); group != nil && strings.TrimSpace(group.GetName()) != "" {
 		level = group.GetName()
 	}
@@ -698,6 +775,11 @@ func (h *MessageHandler) buildProfileText(ctx context.Context, userID int64) (st
 		balance = int(balanceResp.GetBalance())
 	}
 
+	if rating := h.volunteerRatingLine(ctx, maxID); rating != "" {
+		builder.WriteString(rating)
+		builder.WriteString("\n")
+	}
+
 	builder.WriteString(fmt.Sprintf(h.messages.ProfileLevelBalanceTemplate, level, balance))
 
 	return builder.String(), nil
@@ -733,10 +815,6 @@ func (h *MessageHandler) showCoinsHowToSpend(ctx context.Context, chatID, userID
 	h.renderMenu(ctx, chatID, userID, h.messages.CoinsHowToSpendText, h.coinsDetailKeyboard())
 }
 
-func (h *MessageHandler) showCoinsLevels(ctx context.Context, chatID, userID int64) {
-	h.renderMenu(ctx, chatID, userID, h.messages.CoinsLevelsText, h.coinsDetailKeyboard())
-}
-
 func (h *MessageHandler) showAboutDobrikaMenu(ctx context.Context, chatID, userID int64) {
 	h.renderMenu(ctx, chatID, userID, h.messages.AboutDobrikaText, h.aboutMenuKeyboard())
 }
@@ -784,3 +862,52 @@ func (h *MessageHandler) aboutMenuKeyboard() *maxbot.Keyboard {
 
 	return keyboard
 }
+
+func (h *MessageHandler) profileNameTemplate() string {
+	if text := strings.TrimSpace(h.messages.ProfileNameTemplate); text != "" {
+		return text
+	}
+	return "*Имя:* %s"
+}
+
+func (h *MessageHandler) profileAgeTemplate() string {
+	if text := strings.TrimSpace(h.messages.ProfileAgeTemplate); text != "" {
+		return text
+	}
+	return "*Возраст:* %d"
+}
+
+func (h *MessageHandler) profileCityTemplate() string {
+	if text := strings.TrimSpace(h.messages.ProfileCityTemplate); text != "" {
+		return text
+	}
+	return "*Город:* %s"
+}
+
+func (h *MessageHandler) profileDefaultLevel() string {
+	if text := strings.TrimSpace(h.messages.ProfileDefaultLevel); text != "" {
+		return text
+	}
+	return "Новичок"
+}
+
+func (h *MessageHandler) profileLoadErrorText() string {
+	if text := strings.TrimSpace(h.messages.ProfileLoadErrorText); text != "" {
+		return text
+	}
+	return "Не удалось получить данные профиля. Попробуйте позже."
+}
+
+func (h *MessageHandler) userNameUnknown() string {
+	if text := strings.TrimSpace(h.messages.UserNameUnknown); text != "" {
+		return text
+	}
+	return "Неизвестный волонтёр"
+}
+
+func (h *MessageHandler) userNameFallbackTemplate() string {
+	if text := strings.TrimSpace(h.messages.UserNameFallbackTemplate); text != "" {
+		return text
+	}
+	return "Пользователь %s"
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"strings"
+	"time"
+
+	"DobrikaDev/max-bot/internal/callbackcodec"
+	"DobrikaDev/max-bot/internal/callbackrouter"
+	"DobrikaDev/max-bot/internal/metrics"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
+	"go.uber.org/zap"
+)
+
+// callbackPrefixes lists the route prefixes the codec compacts into a short
+// hash. Payloads outside the router still work, they are stored verbatim.
+func callbackPrefixes(router *callbackrouter.Router) []string {
+	routes := router.Routes()
+	prefixes := make([]string, 0, len(routes))
+	for _, route := range routes {
+		prefixes = append(prefixes, route.Prefix)
+	}
+	return prefixes
+}
+
+// newCallbackCodec signs with the configured secret. Without one the key is
+// derived from the bot token, so buttons survive restarts either way; with
+// neither, signing is off and payloads travel as plain text.
+func newCallbackCodec(secret, maxToken string, ttl time.Duration, routes []string, logger *zap.Logger) *callbackcodec.Codec {
+	key := []byte(secret)
+	if len(key) == 0 {
+		if maxToken == "" {
+			logger.Warn("callback secret and MAX token are empty; callback payloads are not signed")
+			return nil
+		}
+		logger.Warn("callback secret is empty; deriving it from the MAX token")
+		mac := hmac.New(sha256.New, []byte(maxToken))
+		mac.Write([]byte("max-bot callback codec"))
+		key = mac.Sum(nil)
+	}
+
+	codec, err := callbackcodec.New(key, ttl, routes)
+	if err != nil {
+		logger.Error("failed to create callback codec; callback payloads are not signed", zap.Error(err))
+		return nil
+	}
+	return codec
+}
+
+// sealKeyboard replaces every callback payload with a signed token just before
+// the keyboard is sent. Keyboard.Build hands out the builder's own row slices,
+// so rewriting the built buttons updates the keyboard in place.
+func (h *MessageHandler) sealKeyboard(keyboard *maxbot.Keyboard) {
+	if h.callbacks == nil || keyboard == nil {
+		return
+	}
+
+	for _, row := range keyboard.Build().Buttons {
+		for i, button := range row {
+			if callback, ok := button.(schemes.CallbackButton); ok {
+				callback.Payload = h.callbacks.Encode(callback.Payload)
+				row[i] = callback
+			}
+		}
+	}
+}
+
+// openCallback swaps the token in an incoming callback for the payload it was
+// made from. It returns false, after telling the user the menu is outdated,
+// when the token is missing, tampered with or expired.
+func (h *MessageHandler) openCallback(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate) bool {
+	if h.callbacks == nil {
+		return true
+	}
+
+	token := callbackQuery.Callback.Payload
+	payload, err := h.callbacks.Decode(token)
+	if err == nil {
+		callbackQuery.Callback.Payload = payload
+		return true
+	}
+
+	reason := callbackRejectReason(err)
+	metrics.ObserveCallbackRejected(reason)
+	if reason == "expired" || reason == "unsigned" {
+		h.logger.Info("rejected outdated callback", zap.String("reason", reason), zap.Int64("user_id", callbackQuery.Callback.User.UserId))
+	} else {
+		h.logger.Warn("rejected invalid callback", zap.String("reason", reason), zap.Int64("user_id", callbackQuery.Callback.User.UserId), zap.String("payload", token))
+	}
+
+	if callbackQuery.Callback.CallbackID != "" {
+		answer := &schemes.CallbackAnswer{Notification: h.callbackOutdatedText()}
+		if _, err := h.api.Messages.AnswerOnCallback(ctx, callbackQuery.Callback.CallbackID, answer); err != nil && !isBenignAPIError(err) {
+			h.logger.Warn("failed to answer outdated callback", zap.Error(err))
+		}
+	}
+
+	if callbackQuery.Message != nil {
+		chatID := callbackQuery.Message.Recipient.ChatId
+		userID := callbackQuery.Callback.User.UserId
+		if callbackQuery.Message.Body.Mid != "" {
+			h.menus.set(chatID, callbackQuery.Message.Body.Mid, userID)
+		}
+		h.SendMainMenu(ctx, chatID, userID, h.callbackOutdatedText())
+	}
+
+	return false
+}
+
+func callbackRejectReason(err error) string {
+	switch {
+	case errors.Is(err, callbackcodec.ErrNotToken):
+		return "unsigned"
+	case errors.Is(err, callbackcodec.ErrExpired):
+		return "expired"
+	case errors.Is(err, callbackcodec.ErrSignature):
+		return "signature"
+	case errors.Is(err, callbackcodec.ErrUnknownRoute):
+		return "unknown_route"
+	default:
+		return "malformed"
+	}
+}
+
+func (h *MessageHandler) callbackOutdatedText() string {
+	if text := strings.TrimSpace(h.messages.CallbackOutdatedText); text != "" {
+		return text
+	}
+	return "Это меню устарело — вот актуальное 👇"
+}
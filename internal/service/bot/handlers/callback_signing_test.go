@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"DobrikaDev/max-bot/internal/callbackcodec"
+	"DobrikaDev/max-bot/internal/locales"
+	"DobrikaDev/max-bot/internal/storage"
+	"DobrikaDev/max-bot/utils/config"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
+	"go.uber.org/zap"
+)
+
+const testMaxToken = "test-token"
+
+// fakeMaxAPI records the messages and callback answers the handler sends.
+type fakeMaxAPI struct {
+	mu      sync.Mutex
+	texts   []string
+	answers []string
+}
+
+func (a *fakeMaxAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Text         string `json:"text"`
+		Notification string `json:"notification"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/answers":
+		a.answers = append(a.answers, body.Notification)
+		_, _ = w.Write([]byte(`{"success":true}`))
+	case r.URL.Path == "/messages" && r.Method == http.MethodPost:
+		a.texts = append(a.texts, body.Text)
+		_, _ = w.Write([]byte(`{"message":{"body":{"mid":"mid.1"}}}`))
+	case r.URL.Path == "/messages" && r.Method == http.MethodPut:
+		a.texts = append(a.texts, body.Text)
+		_, _ = w.Write([]byte(`{"success":true}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// markers drops markdown emphasis so texts compare however they render.
+var markers = strings.NewReplacer("*", "", "_", "", "\\", "")
+
+// sent reports whether any sent or edited message contains text.
+func (a *fakeMaxAPI) sent(text string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, got := range a.texts {
+		if strings.Contains(markers.Replace(got), markers.Replace(text)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *fakeMaxAPI) sentTexts() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return slices.Clone(a.texts)
+}
+
+func (a *fakeMaxAPI) callbackAnswers() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return slices.Clone(a.answers)
+}
+
+type testAPIConfig struct{ url string }
+
+func (c testAPIConfig) GetHttpBotAPIUrl() string        { return c.url }
+func (c testAPIConfig) GetHttpBotAPITimeOut() int       { return 0 }
+func (c testAPIConfig) GetHttpBotAPIVersion() string    { return "" }
+func (c testAPIConfig) BotTokenCheckInInputSteam() bool { return false }
+func (c testAPIConfig) BotTokenCheckString() string     { return testMaxToken }
+func (c testAPIConfig) GetDebugLogMode() bool           { return false }
+func (c testAPIConfig) GetDebugLogChat() int64          { return 0 }
+
+// newTestHandler wires a handler without services to a fake MAX API.
+func newTestHandler(t *testing.T) (*MessageHandler, *fakeMaxAPI) {
+	t.Helper()
+
+	fake := &fakeMaxAPI{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	api, err := maxbot.NewWithConfig(testAPIConfig{url: server.URL + "/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		MaxToken:       testMaxToken,
+		MaxAPIURL:      server.URL + "/",
+		CallbackSecret: "test secret",
+		CallbackTTL:    time.Hour,
+	}
+	store := storage.NewMemoryStore(0)
+	h := NewMessageHandler(api, cfg, zap.NewNop(), store)
+	t.Cleanup(func() {
+		_ = h.Close()
+		_ = store.Close()
+	})
+	return h, fake
+}
+
+func TestOpenCallbackRejectsForgedToken(t *testing.T) {
+	const userID = 4242
+	aboutText := firstLine(locales.For(locales.DefaultLanguage).AboutDobrikaText)
+
+	press := func(h *MessageHandler, payload string) {
+		h.HandleCallbackQuery(context.Background(), &schemes.MessageCallbackUpdate{
+			Callback: schemes.Callback{CallbackID: "cb", Payload: payload, User: schemes.User{UserId: userID}},
+			Message:  &schemes.Message{Recipient: schemes.Recipient{ChatId: userID}, Body: schemes.MessageBody{Mid: "mid.0"}},
+		})
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		h, fake := newTestHandler(t)
+		press(h, h.callbacks.Encode(callbackMainMenuAbout))
+		if !fake.sent(aboutText) {
+			t.Fatalf("about screen not shown, sent %q", fake.sentTexts())
+		}
+	})
+
+	forged := map[string]func(token string) string{
+		"tampered": func(token string) string {
+			data, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(token, callbackcodec.Prefix))
+			if err != nil {
+				t.Fatal(err)
+			}
+			data[len(data)-1] ^= 0x01
+			return callbackcodec.Prefix + base64.RawURLEncoding.EncodeToString(data)
+		},
+		"unsigned": func(string) string { return callbackMainMenuAbout },
+	}
+	for name, forge := range forged {
+		t.Run(name, func(t *testing.T) {
+			h, fake := newTestHandler(t)
+			press(h, forge(h.callbacks.Encode(callbackMainMenuAbout)))
+
+			if fake.sent(aboutText) {
+				t.Fatal("forged callback was dispatched")
+			}
+			outdated := h.callbackOutdatedText()
+			if !fake.sent(outdated) {
+				t.Fatalf("outdated menu not shown, sent %q", fake.sentTexts())
+			}
+			if answers := fake.callbackAnswers(); len(answers) != 1 || answers[0] != outdated {
+				t.Fatalf("callback answers = %q, want %q", answers, outdated)
+			}
+		})
+	}
+}
+
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(text, "\n")
+	return line
+}
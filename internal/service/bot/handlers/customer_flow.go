@@ -49,58 +49,6 @@ func (h *MessageHandler) tryHandleCustomerMessage(ctx context.Context, update *s
 	return true
 }
 
-func (h *MessageHandler) tryHandleCustomerCallback(ctx context.Context, update *schemes.MessageCallbackUpdate) bool {
-	payload := update.Callback.Payload
-	if payload == "" {
-		return false
-	}
-
-	switch payload {
-	case callbackMainMenuNeedHelp:
-		h.handleCustomerNeedHelp(ctx, update)
-		h.answerCallback(ctx, update.Callback.CallbackID)
-		return true
-	case callbackCustomerTypeIndividual, callbackCustomerTypeBusiness:
-		h.handleCustomerTypeSelection(ctx, update, payload)
-		h.answerCallback(ctx, update.Callback.CallbackID)
-		return true
-	case callbackCustomerManageCreate:
-		h.handleCustomerManageCreate(ctx, update)
-		h.answerCallback(ctx, update.Callback.CallbackID)
-		return true
-	case callbackCustomerManageUpdate:
-		h.handleCustomerManageUpdate(ctx, update)
-		h.answerCallback(ctx, update.Callback.CallbackID)
-		return true
-	case callbackCustomerManageDelete:
-		h.handleCustomerManageDelete(ctx, update)
-		h.answerCallback(ctx, update.Callback.CallbackID)
-		return true
-	case callbackCustomerDeleteConfirm:
-		h.handleCustomerDeleteConfirm(ctx, update)
-		h.answerCallback(ctx, update.Callback.CallbackID)
-		return true
-	case callbackCustomerDeleteCancel:
-		h.handleCustomerDeleteCancel(ctx, update)
-		h.answerCallback(ctx, update.Callback.CallbackID)
-		return true
-	case callbackCustomerManageBack:
-		h.handleCustomerManageBack(ctx, update)
-		h.answerCallback(ctx, update.Callback.CallbackID)
-		return true
-	case callbackCustomerManageTasks:
-		h.handleCustomerManageTasks(ctx, update)
-		h.answerCallback(ctx, update.Callback.CallbackID)
-		return true
-	case callbackCustomerManageCreateTask:
-		h.handleCustomerManageCreateTask(ctx, update)
-		h.answerCallback(ctx, update.Callback.CallbackID)
-		return true
-	default:
-		return false
-	}
-}
-
 func (h *MessageHandler) handleCustomerNeedHelp(ctx context.Context, update *schemes.MessageCallbackUpdate) {
 	if update.Message == nil {
 		h.logger.Warn("need help callback without message context")
@@ -416,7 +364,7 @@ func (h *MessageHandler) promptCustomerName(ctx context.Context, session *custom
 	text := h.customerNamePromptText(session)
 
 	if session.Existing && session.Name != "" {
-		text = fmt.Sprintf("%s\n\n*Сейчас:* %s", text, session.Name)
+		text = fmt.Sprintf("%s\n\n*Сейчас:* %s", text, escapeMarkdown(session.Name))
 	}
 
 	h.updateCustomerSessionMessage(ctx, session, text, emptyKeyboard())
@@ -426,7 +374,7 @@ func (h *MessageHandler) promptCustomerAbout(ctx context.Context, session *custo
 	text := h.customerAboutPromptText(session)
 
 	if session.Existing && session.About != "" {
-		text = fmt.Sprintf("%s\n\n*Сейчас:* %s", text, session.About)
+		text = fmt.Sprintf("%s\n\n*Сейчас:* %s", text, escapeMarkdown(session.About))
 	}
 
 	h.updateCustomerSessionMessage(ctx, session, text, emptyKeyboard())
@@ -541,7 +489,7 @@ func (h *MessageHandler) showCustomerManageMenu(ctx context.Context, chatID, use
 		name = "—"
 	}
 
-	summary := fmt.Sprintf(summaryTemplate, name, about)
+	summary := fmt.Sprintf(summaryTemplate, escapeMarkdown(name), escapeMarkdown(about))
 
 	builder := strings.Builder{}
 	if strings.TrimSpace(intro) != "" {
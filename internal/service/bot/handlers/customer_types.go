@@ -1,9 +1,12 @@
 package handlers
 
 import (
-	"sync"
+	"time"
 
 	customerpb "DobrikaDev/max-bot/internal/generated/customerpb"
+	"DobrikaDev/max-bot/internal/storage"
+
+	"go.uber.org/zap"
 )
 
 type customerStep int
@@ -35,32 +38,21 @@ func (s *customerSession) isInProgress() bool {
 }
 
 type customerSessionStore struct {
-	mu       sync.RWMutex
-	sessions map[int64]*customerSession
+	table sessionTable[customerSession]
 }
 
-func newCustomerSessionStore() *customerSessionStore {
-	return &customerSessionStore{sessions: make(map[int64]*customerSession)}
+func newCustomerSessionStore(store storage.Store, ttl time.Duration, logger *zap.Logger) *customerSessionStore {
+	return &customerSessionStore{table: newSessionTable[customerSession](store, sessionBucketCustomer, ttl, logger)}
 }
 
 func (s *customerSessionStore) get(userID int64) (*customerSession, bool) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	session, ok := s.sessions[userID]
-	return session, ok
+	return s.table.load(userID)
 }
 
 func (s *customerSessionStore) upsert(session *customerSession) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	s.sessions[session.UserID] = session
+	s.table.save(session.UserID, session)
 }
 
 func (s *customerSessionStore) delete(userID int64) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	delete(s.sessions, userID)
+	s.table.remove(userID)
 }
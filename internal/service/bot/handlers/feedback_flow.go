@@ -0,0 +1,422 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	customerpb "DobrikaDev/max-bot/internal/generated/customerpb"
+	"DobrikaDev/max-bot/internal/storage"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
+	"go.uber.org/zap"
+)
+
+const (
+	feedbackMinRating        = 1
+	feedbackMaxRating        = 5
+	feedbackCommentMaxLength = 1000
+	feedbackPageSize         = 100
+	feedbackMaxPages         = 20
+)
+
+// feedbackSession holds a rating that is waiting for an optional comment.
+type feedbackSession struct {
+	UserID      int64
+	ChatID      int64
+	TaskID      string
+	VolunteerID string
+	Rating      int32
+}
+
+type feedbackSessionStore struct {
+	table sessionTable[feedbackSession]
+}
+
+func newFeedbackSessionStore(store storage.Store, ttl time.Duration, logger *zap.Logger) *feedbackSessionStore {
+	return &feedbackSessionStore{table: newSessionTable[feedbackSession](store, sessionBucketFeedback, ttl, logger)}
+}
+
+func (s *feedbackSessionStore) get(userID int64) (*feedbackSession, bool) {
+	return s.table.load(userID)
+}
+
+func (s *feedbackSessionStore) upsert(session *feedbackSession) {
+	s.table.save(session.UserID, session)
+}
+
+func (s *feedbackSessionStore) delete(userID int64) {
+	s.table.remove(userID)
+}
+
+// volunteerRating is the aggregated feedback of a single volunteer.
+type volunteerRating struct {
+	Average float64
+	Count   int
+}
+
+func (h *MessageHandler) tryHandleFeedbackMessage(ctx context.Context, update *schemes.MessageCreatedUpdate) bool {
+	session, ok := h.feedbackSessions.get(update.Message.Sender.UserId)
+	if !ok {
+		return false
+	}
+
+	if h.isStartCommand(update) {
+		h.feedbackSessions.delete(session.UserID)
+		return false
+	}
+
+	comment := strings.TrimSpace(update.GetText())
+	if comment == "" {
+		h.renderMenu(ctx, session.ChatID, session.UserID, h.feedbackCommentPromptText(session.Rating), h.feedbackCommentKeyboard())
+		return true
+	}
+
+	if utf8.RuneCountInString(comment) > feedbackCommentMaxLength {
+		h.renderMenu(ctx, session.ChatID, session.UserID, h.feedbackCommentTooLongText(), h.feedbackCommentKeyboard())
+		return true
+	}
+
+	h.submitFeedback(ctx, session, comment)
+	return true
+}
+
+func (h *MessageHandler) handleCustomerFeedbackStart(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, taskID, volunteerID string) {
+	h.answerCallback(ctx, callbackQuery.Callback.CallbackID)
+
+	if callbackQuery.Message == nil || taskID == "" || volunteerID == "" {
+		return
+	}
+
+	h.promptVolunteerFeedback(ctx, callbackQuery.Message.Recipient.ChatId, callbackQuery.Callback.User.UserId, taskID, volunteerID)
+}
+
+func (h *MessageHandler) handleCustomerFeedbackRate(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, taskID, volunteerID, ratingValue string) {
+	h.answerCallback(ctx, callbackQuery.Callback.CallbackID)
+
+	if callbackQuery.Message == nil || taskID == "" || volunteerID == "" {
+		return
+	}
+
+	rating, err := strconv.Atoi(ratingValue)
+	if err != nil || rating < feedbackMinRating || rating > feedbackMaxRating {
+		return
+	}
+
+	session := &feedbackSession{
+		UserID:      callbackQuery.Callback.User.UserId,
+		ChatID:      callbackQuery.Message.Recipient.ChatId,
+		TaskID:      taskID,
+		VolunteerID: volunteerID,
+		Rating:      int32(rating),
+	}
+	h.feedbackSessions.upsert(session)
+
+	h.renderMenu(ctx, session.ChatID, session.UserID, h.feedbackCommentPromptText(session.Rating), h.feedbackCommentKeyboard())
+}
+
+func (h *MessageHandler) handleCustomerFeedbackNoComment(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate) {
+	h.answerCallback(ctx, callbackQuery.Callback.CallbackID)
+
+	session, ok := h.feedbackSessions.get(callbackQuery.Callback.User.UserId)
+	if !ok {
+		if callbackQuery.Message != nil {
+			h.showCustomerTasksMenu(ctx, callbackQuery.Message.Recipient.ChatId, callbackQuery.Callback.User.UserId, fmt.Sprintf("%d", callbackQuery.Callback.User.UserId), 0)
+		}
+		return
+	}
+
+	h.submitFeedback(ctx, session, "")
+}
+
+func (h *MessageHandler) handleCustomerFeedbackSkip(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, taskID, volunteerID string) {
+	h.answerCallback(ctx, callbackQuery.Callback.CallbackID)
+
+	if callbackQuery.Message == nil || taskID == "" || volunteerID == "" {
+		return
+	}
+
+	userID := callbackQuery.Callback.User.UserId
+	h.feedbackSessions.delete(userID)
+	h.showCustomerTaskAssignmentDetail(ctx, callbackQuery.Message.Recipient.ChatId, userID, taskID, volunteerID)
+}
+
+// promptVolunteerFeedback asks the customer to rate the volunteer. When the
+// customer service is not available or the feedback has already been left,
+// the assignment detail is shown instead.
+func (h *MessageHandler) promptVolunteerFeedback(ctx context.Context, chatID, userID int64, taskID, volunteerID string, intro ...string) {
+	introText := ""
+	if len(intro) > 0 {
+		introText = strings.TrimSpace(intro[0])
+	}
+
+	if h.customer == nil {
+		h.showCustomerTaskAssignmentDetail(ctx, chatID, userID, taskID, volunteerID, introText)
+		return
+	}
+
+	exists, err := h.feedbackExists(ctx, taskID, volunteerID)
+	if err != nil {
+		h.logger.Warn("failed to check existing feedback", zap.Error(err), zap.String("task_id", taskID), zap.String("volunteer_id", volunteerID))
+	}
+	if exists {
+		h.showCustomerTaskAssignmentDetail(ctx, chatID, userID, taskID, volunteerID, joinIntro(introText, h.feedbackAlreadyLeftText()))
+		return
+	}
+
+	text := fmt.Sprintf(h.feedbackPromptTemplate(), escapeMarkdown(h.lookupUserName(ctx, volunteerID)))
+	h.renderMenu(ctx, chatID, userID, joinIntro(introText, text), h.feedbackRatingKeyboard(taskID, volunteerID))
+}
+
+func (h *MessageHandler) submitFeedback(ctx context.Context, session *feedbackSession, comment string) {
+	if h.customer == nil {
+		h.feedbackSessions.delete(session.UserID)
+		h.showCustomerTaskAssignmentDetail(ctx, session.ChatID, session.UserID, session.TaskID, session.VolunteerID, h.feedbackErrorText())
+		return
+	}
+
+	if exists, err := h.feedbackExists(ctx, session.TaskID, session.VolunteerID); err != nil {
+		h.logger.Warn("failed to check existing feedback", zap.Error(err), zap.String("task_id", session.TaskID), zap.String("volunteer_id", session.VolunteerID))
+	} else if exists {
+		h.feedbackSessions.delete(session.UserID)
+		h.showCustomerTaskAssignmentDetail(ctx, session.ChatID, session.UserID, session.TaskID, session.VolunteerID, h.feedbackAlreadyLeftText())
+		return
+	}
+
+	resp, err := h.customer.CreateFeedback(ctx, &customerpb.CreateFeedbackRequest{
+		Feedback: &customerpb.Feedback{
+			Rating:     session.Rating,
+			Comment:    comment,
+			TaskId:     session.TaskID,
+			UserId:     session.VolunteerID,
+			CustomerId: fmt.Sprintf("%d", session.UserID),
+		},
+	})
+	if err != nil {
+		h.logger.Error("failed to create feedback", zap.Error(err), zap.String("task_id", session.TaskID), zap.String("volunteer_id", session.VolunteerID))
+		h.renderMenu(ctx, session.ChatID, session.UserID, h.feedbackErrorText(), h.feedbackCommentKeyboard())
+		return
+	}
+	if svcErr := resp.GetError(); svcErr != nil {
+		h.logger.Warn("customer service returned error when creating feedback", zap.String("task_id", session.TaskID), zap.String("volunteer_id", session.VolunteerID), zap.String("message", svcErr.GetMessage()))
+		h.renderMenu(ctx, session.ChatID, session.UserID, h.feedbackErrorText(), h.feedbackCommentKeyboard())
+		return
+	}
+
+	h.feedbackSessions.delete(session.UserID)
+	h.showCustomerTaskAssignmentDetail(ctx, session.ChatID, session.UserID, session.TaskID, session.VolunteerID, h.feedbackSuccessText())
+}
+
+// canLeaveFeedback reports whether the rating button should be offered on the
+// assignment screen: the work is done and no feedback has been left yet.
+func (h *MessageHandler) canLeaveFeedback(ctx context.Context, taskID, volunteerID, status string) bool {
+	if h.customer == nil {
+		return false
+	}
+
+	switch normalizeStatus(status) {
+	case "confirmed", "completed", "done":
+	default:
+		return false
+	}
+
+	exists, err := h.feedbackExists(ctx, taskID, volunteerID)
+	if err != nil {
+		h.logger.Warn("failed to check existing feedback", zap.Error(err), zap.String("task_id", taskID), zap.String("volunteer_id", volunteerID))
+		return false
+	}
+
+	return !exists
+}
+
+func (h *MessageHandler) feedbackExists(ctx context.Context, taskID, volunteerID string) (bool, error) {
+	if h.customer == nil {
+		return false, fmt.Errorf("customer service client is not configured")
+	}
+
+	resp, err := h.customer.CountFeedbacks(ctx, &customerpb.CountFeedbacksRequest{TaskId: taskID, UserId: volunteerID})
+	if err != nil {
+		return false, err
+	}
+	if svcErr := resp.GetError(); svcErr != nil {
+		return false, fmt.Errorf("customer service error: %s", svcErr.GetMessage())
+	}
+
+	return resp.GetTotal() > 0, nil
+}
+
+// fetchVolunteerRating averages every feedback left for the volunteer. The
+// service has no aggregate RPC, so the feedbacks are read page by page.
+func (h *MessageHandler) fetchVolunteerRating(ctx context.Context, volunteerID string) (volunteerRating, error) {
+	if h.customer == nil {
+		return volunteerRating{}, fmt.Errorf("customer service client is not configured")
+	}
+
+	var (
+		sum   int64
+		count int
+	)
+
+	for page := 0; page < feedbackMaxPages; page++ {
+		resp, err := h.customer.GetFeedbacks(ctx, &customerpb.GetFeedbacksRequest{
+			UserId: volunteerID,
+			Limit:  feedbackPageSize,
+			Offset: int32(page * feedbackPageSize),
+		})
+		if err != nil {
+			return volunteerRating{}, err
+		}
+		if svcErr := resp.GetError(); svcErr != nil {
+			return volunteerRating{}, fmt.Errorf("customer service error: %s", svcErr.GetMessage())
+		}
+
+		for _, feedback := range resp.GetFeedbacks() {
+			if rating := feedback.GetRating(); rating >= feedbackMinRating && rating <= feedbackMaxRating {
+				sum += int64(rating)
+				count++
+			}
+		}
+
+		fetched := (page + 1) * feedbackPageSize
+		if len(resp.GetFeedbacks()) < feedbackPageSize || fetched >= int(resp.GetTotal()) {
+			break
+		}
+	}
+
+	if count == 0 {
+		return volunteerRating{}, nil
+	}
+
+	return volunteerRating{Average: float64(sum) / float64(count), Count: count}, nil
+}
+
+// volunteerRatingLine renders the rating summary, or an empty string when the
+// rating cannot be fetched so the surrounding screen still renders.
+func (h *MessageHandler) volunteerRatingLine(ctx context.Context, volunteerID string) string {
+	if h.customer == nil {
+		return ""
+	}
+
+	rating, err := h.fetchVolunteerRating(ctx, volunteerID)
+	if err != nil {
+		h.logger.Warn("failed to fetch volunteer rating", zap.Error(err), zap.String("volunteer_id", volunteerID))
+		return ""
+	}
+
+	if rating.Count == 0 {
+		return h.volunteerRatingEmptyText()
+	}
+
+	return fmt.Sprintf(h.volunteerRatingTemplate(), rating.Average, rating.Count)
+}
+
+func (h *MessageHandler) feedbackRatingKeyboard(taskID, volunteerID string) *maxbot.Keyboard {
+	keyboard := h.api.Messages.NewKeyboardBuilder()
+
+	row := keyboard.AddRow()
+	for rating := feedbackMinRating; rating <= feedbackMaxRating; rating++ {
+		row.AddCallback(fmt.Sprintf("%d⭐", rating), schemes.DEFAULT, fmt.Sprintf("%s:%s:%s:%d", callbackCustomerFeedbackRate, taskID, volunteerID, rating))
+	}
+
+	keyboard.AddRow().
+		AddCallback(h.feedbackSkipButton(), schemes.DEFAULT, fmt.Sprintf("%s:%s:%s", callbackCustomerFeedbackSkip, taskID, volunteerID))
+
+	return keyboard
+}
+
+func (h *MessageHandler) feedbackCommentKeyboard() *maxbot.Keyboard {
+	keyboard := h.api.Messages.NewKeyboardBuilder()
+	keyboard.AddRow().
+		AddCallback(h.feedbackNoCommentButton(), schemes.POSITIVE, callbackCustomerFeedbackSubmit)
+	return keyboard
+}
+
+func joinIntro(intro, text string) string {
+	if intro == "" {
+		return text
+	}
+	return intro + "\n\n" + text
+}
+
+func (h *MessageHandler) feedbackPromptTemplate() string {
+	if text := strings.TrimSpace(h.messages.CustomerFeedbackPromptText); text != "" {
+		return text
+	}
+	return "⭐ Оцени помощь волонтёра %s от 1 до 5"
+}
+
+func (h *MessageHandler) feedbackCommentPromptText(rating int32) string {
+	template := strings.TrimSpace(h.messages.CustomerFeedbackCommentPrompt)
+	if template == "" {
+		template = "Твоя оценка: %s\n\nНапиши пару слов о помощи волонтёра или нажми «Без комментария»."
+	}
+	return fmt.Sprintf(template, strings.Repeat("⭐", int(rating)))
+}
+
+func (h *MessageHandler) feedbackCommentTooLongText() string {
+	template := strings.TrimSpace(h.messages.CustomerFeedbackCommentTooLongText)
+	if template == "" {
+		template = "Комментарий слишком длинный. Уложись, пожалуйста, в %d символов."
+	}
+	return fmt.Sprintf(template, feedbackCommentMaxLength)
+}
+
+func (h *MessageHandler) feedbackSkipButton() string {
+	if text := strings.TrimSpace(h.messages.CustomerFeedbackSkipButton); text != "" {
+		return text
+	}
+	return "Пропустить"
+}
+
+func (h *MessageHandler) feedbackNoCommentButton() string {
+	if text := strings.TrimSpace(h.messages.CustomerFeedbackNoCommentButton); text != "" {
+		return text
+	}
+	return "Без комментария"
+}
+
+func (h *MessageHandler) feedbackRateButton() string {
+	if text := strings.TrimSpace(h.messages.CustomerFeedbackRateButton); text != "" {
+		return text
+	}
+	return "⭐ Оценить волонтёра"
+}
+
+func (h *MessageHandler) feedbackSuccessText() string {
+	if text := strings.TrimSpace(h.messages.CustomerFeedbackSuccessText); text != "" {
+		return text
+	}
+	return "💚 Спасибо за отзыв!"
+}
+
+func (h *MessageHandler) feedbackErrorText() string {
+	if text := strings.TrimSpace(h.messages.CustomerFeedbackErrorText); text != "" {
+		return text
+	}
+	return "⚠️ Не удалось сохранить отзыв. Попробуй чуть позже."
+}
+
+func (h *MessageHandler) feedbackAlreadyLeftText() string {
+	if text := strings.TrimSpace(h.messages.CustomerFeedbackAlreadyLeftText); text != "" {
+		return text
+	}
+	return "Отзыв по этому делу уже оставлен 💚"
+}
+
+func (h *MessageHandler) volunteerRatingTemplate() string {
+	if text := strings.TrimSpace(h.messages.VolunteerRatingTemplate); text != "" {
+		return text
+	}
+	return "*Рейтинг:* ⭐ %.1f (отзывов: %d)"
+}
+
+func (h *MessageHandler) volunteerRatingEmptyText() string {
+	if text := strings.TrimSpace(h.messages.VolunteerRatingEmptyText); text != "" {
+		return text
+	}
+	return "*Рейтинг:* пока нет отзывов"
+}
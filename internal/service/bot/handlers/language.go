@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"DobrikaDev/max-bot/internal/callbackrouter"
+	"DobrikaDev/max-bot/internal/locales"
+
+	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
+	"go.uber.org/zap"
+)
+
+// languagePreference is the language a user picked in the profile.
+type languagePreference struct {
+	Code string `json:"code"`
+}
+
+// localeHint is the locale MAX last reported for a user. It only steers
+// the language until the user picks one.
+type localeHint struct {
+	Locale string `json:"locale"`
+}
+
+// languageViews maps a language code to the handler that speaks it.
+type languageViews map[string]*MessageHandler
+
+// setLanguageViews makes one handler per bundle and puts them in place at
+// once. A view is a shallow copy of h that differs only in messages and in
+// the callback router, whose routes are bound to the view, so everything a
+// view renders comes from its bundle. Views are copied from h every time,
+// so they must be built from the handler NewMessageHandler returned.
+func (h *MessageHandler) setLanguageViews(bundles map[string]locales.Messages) {
+	views := make(languageViews, len(bundles))
+	for _, lang := range locales.Languages() {
+		messages, ok := bundles[lang]
+		if !ok {
+			continue
+		}
+
+		view := *h
+		view.lang = lang
+		view.messages = messages
+		router, err := view.newCallbackRouter()
+		if err != nil {
+			h.logger.Fatal("invalid callback route table", zap.Error(err), zap.String("language", lang))
+		}
+		view.router = router
+		views[lang] = &view
+	}
+	h.views.Store(&views)
+}
+
+// view returns the handler for lang, if there is one.
+func (h *MessageHandler) view(lang string) (*MessageHandler, bool) {
+	views := h.views.Load()
+	if views == nil {
+		return nil, false
+	}
+	view, ok := (*views)[lang]
+	return view, ok
+}
+
+// languageFor picks the user's language: their choice in the profile, then
+// the locale MAX reports for them, then Russian.
+func (h *MessageHandler) languageFor(userID int64) string {
+	if pref, ok := h.languages.load(userID); ok {
+		if _, known := h.view(pref.Code); known {
+			return pref.Code
+		}
+	}
+	if hint, ok := h.localeHints.load(userID); ok {
+		if lang := locales.Match(hint.Locale); lang != "" {
+			if _, known := h.view(lang); known {
+				return lang
+			}
+		}
+	}
+	return locales.DefaultLanguage
+}
+
+// forUser returns the view that speaks userID's language. Entry points
+// switch to it first; texts for somebody other than the current user, such
+// as notifications, are rendered through the recipient's view.
+func (h *MessageHandler) forUser(userID int64) *MessageHandler {
+	if view, ok := h.view(h.languageFor(userID)); ok {
+		return view
+	}
+	return h
+}
+
+func (h *MessageHandler) forMaxID(maxID string) *MessageHandler {
+	userID, err := strconv.ParseInt(strings.TrimSpace(maxID), 10, 64)
+	if err != nil {
+		return h
+	}
+	return h.forUser(userID)
+}
+
+// RememberUserLocale records the locale MAX reported with an update. The SDK
+// drops user_locale when it decodes updates, so the bot passes it in from
+// the raw request; it is only available in webhook mode.
+func (h *MessageHandler) RememberUserLocale(userID int64, locale string) {
+	locale = strings.TrimSpace(locale)
+	if userID == 0 || locale == "" {
+		return
+	}
+	if hint, ok := h.localeHints.load(userID); ok && hint.Locale == locale {
+		return
+	}
+	h.localeHints.save(userID, &localeHint{Locale: locale})
+}
+
+func (h *MessageHandler) showLanguageSettings(ctx context.Context, chatID, userID int64) {
+	keyboard := h.api.Messages.NewKeyboardBuilder()
+	for _, lang := range locales.Languages() {
+		view, ok := h.view(lang)
+		if !ok {
+			continue
+		}
+
+		label := view.languageName()
+		if lang == h.lang {
+			label = "✅ " + label
+		}
+		keyboard.AddRow().
+			AddCallback(label, schemes.DEFAULT, fmt.Sprintf("%s:%s", callbackProfileLanguageSet, lang))
+	}
+	keyboard.AddRow().
+		AddCallback(h.messages.ProfileBackButton, schemes.DEFAULT, callbackMainMenuProfile)
+
+	h.renderMenu(ctx, chatID, userID, h.languageSelectText(), keyboard)
+}
+
+// handleLanguageSet stores the choice and shows the profile again, already
+// in the new language.
+func (h *MessageHandler) handleLanguageSet(ctx context.Context, req *callbackrouter.Request) error {
+	if req.Update.Message == nil {
+		return callbackrouter.ErrNotHandled
+	}
+
+	chatID, userID := req.ChatID(), req.UserID()
+	lang := req.Param("lang")
+
+	view, ok := h.view(lang)
+	if !ok {
+		h.showLanguageSettings(ctx, chatID, userID)
+		return nil
+	}
+
+	h.languages.save(userID, &languagePreference{Code: lang})
+	h.logger.Info("language changed", zap.Int64("user_id", userID), zap.String("language", lang))
+	view.showProfile(ctx, chatID, userID, view.languageChangedText())
+	return nil
+}
+
+func (h *MessageHandler) languageName() string {
+	if text := strings.TrimSpace(h.messages.LanguageName); text != "" {
+		return text
+	}
+	return h.lang
+}
+
+func (h *MessageHandler) profileLanguageButton() string {
+	if text := strings.TrimSpace(h.messages.ProfileLanguageButton); text != "" {
+		return text
+	}
+	return "🌐 Язык"
+}
+
+func (h *MessageHandler) languageSelectText() string {
+	if text := strings.TrimSpace(h.messages.LanguageSelectText); text != "" {
+		return text
+	}
+	return "🌐 *Язык*\n\nВыбери, на каком языке Добрика будет с тобой говорить."
+}
+
+func (h *MessageHandler) languageChangedText() string {
+	if text := strings.TrimSpace(h.messages.LanguageChangedText); text != "" {
+		return text
+	}
+	return "✅ Язык изменён."
+}
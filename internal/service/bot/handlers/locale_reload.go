@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"time"
+
+	"DobrikaDev/max-bot/internal/locales"
+	"DobrikaDev/max-bot/internal/metrics"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// localeReloadDelay lets a burst of file events settle before the reload. A
+// ConfigMap update swaps a symlink and touches several entries at once.
+const localeReloadDelay = 500 * time.Millisecond
+
+// localeWatcher reapplies the locale overrides when their directory changes.
+type localeWatcher struct {
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+}
+
+// watchLocales applies the overrides in dir and reapplies them whenever the
+// files change. Watching is best effort: if it cannot start, the overrides
+// read now stay until the next restart.
+func (h *MessageHandler) watchLocales(dir string) {
+	h.reloadLocales(dir)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		h.logger.Error("failed to watch locale overrides", zap.Error(err), zap.String("dir", dir))
+		return
+	}
+	if err := watcher.Add(dir); err != nil {
+		h.logger.Error("failed to watch locale overrides", zap.Error(err), zap.String("dir", dir))
+		watcher.Close()
+		return
+	}
+
+	// Set before the loop starts: a reload copies the handler.
+	h.overrides = &localeWatcher{watcher: watcher, done: make(chan struct{})}
+	go h.overrides.run(h, dir)
+}
+
+func (w *localeWatcher) run(h *MessageHandler, dir string) {
+	defer close(w.done)
+
+	var (
+		timer  *time.Timer
+		reload <-chan time.Time
+	)
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			if timer == nil {
+				timer = time.NewTimer(localeReloadDelay)
+			} else {
+				timer.Reset(localeReloadDelay)
+			}
+			reload = timer.C
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			h.logger.Warn("locale watcher error", zap.Error(err), zap.String("dir", dir))
+		case <-reload:
+			reload = nil
+			h.reloadLocales(dir)
+		}
+	}
+}
+
+func (w *localeWatcher) close() {
+	w.watcher.Close()
+	<-w.done
+}
+
+// reloadLocales puts the embedded bundles with the overrides from dir on top
+// in place of the current ones. Overrides with any problem are rejected as a
+// whole and the texts in use stay.
+func (h *MessageHandler) reloadLocales(dir string) {
+	bundles, problems, err := locales.LoadOverrides(dir)
+	if err != nil {
+		metrics.ObserveLocaleReload(metrics.LocaleReloadRejected)
+		h.logger.Error("failed to read locale overrides; keeping current texts", zap.Error(err), zap.String("dir", dir))
+		return
+	}
+	if len(problems) > 0 {
+		for _, problem := range problems {
+			h.logger.Warn("locale override problem", zap.String("language", problem.Language), zap.String("key", problem.Key), zap.String("problem", problem.Message))
+		}
+		metrics.ObserveLocaleReload(metrics.LocaleReloadRejected)
+		h.logger.Error("locale overrides rejected; keeping current texts", zap.String("dir", dir), zap.Int("problems", len(problems)))
+		return
+	}
+
+	h.setLanguageViews(bundles)
+	metrics.ObserveLocaleReload(metrics.LocaleReloadApplied)
+	h.logger.Info("locale overrides applied", zap.String("dir", dir))
+}
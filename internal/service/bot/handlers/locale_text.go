@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"DobrikaDev/max-bot/internal/locales"
+
+	"go.uber.org/zap"
+)
+
+// Built-in Russian texts that several fallbacks share.
+const (
+	defaultPageFooter           = "Страница {{number .Page}} из {{number .Pages}}"
+	defaultRewardAmountText     = `{{number .Amount}} {{plural .Amount "добрик" "добрика" "добриков"}}`
+	defaultVolunteersNeededText = `{{plural .Count "Нужен" "Нужно" "Нужно"}} {{number .Count}} {{plural .Count "волонтёр" "волонтёра" "волонтёров"}}`
+)
+
+// Data for locale texts with named placeholders; the field names are the
+// placeholders a text may use.
+type (
+	pageData struct {
+		Page  int
+		Pages int
+	}
+
+	countData struct {
+		Count int
+	}
+
+	rewardData struct {
+		TaskName    string
+		Amount      int32
+		Coefficient string
+	}
+
+	createdData struct {
+		CreatedAt time.Time
+	}
+)
+
+func (h *MessageHandler) formatter() locales.Formatter {
+	return locales.FormatterFor(h.lang)
+}
+
+// render fills a locale text with data in the view's language. An empty or
+// broken text falls back to the built-in Russian one, so a bad override
+// shows a sensible message rather than template syntax.
+func (h *MessageHandler) render(text, fallback string, data any) string {
+	if text = strings.TrimSpace(text); text != "" {
+		out, err := h.formatter().Render(text, data)
+		if err == nil {
+			return out
+		}
+		h.logger.Warn("failed to render locale text", zap.Error(err), zap.String("language", h.lang), zap.String("text", text))
+	}
+
+	out, err := locales.FormatterFor(locales.DefaultLanguage).Render(fallback, data)
+	if err != nil {
+		h.logger.Error("failed to render fallback text", zap.Error(err), zap.String("text", fallback))
+		return fallback
+	}
+	return out
+}
+
+// pageFooter renders a "page N of M" line; page is zero-based.
+func (h *MessageHandler) pageFooter(text string, page, pages int) string {
+	return h.render(text, defaultPageFooter, pageData{Page: page + 1, Pages: pages})
+}
@@ -1,6 +1,12 @@
 package handlers
 
-import "sync"
+import (
+	"time"
+
+	"DobrikaDev/max-bot/internal/storage"
+
+	"go.uber.org/zap"
+)
 
 type menuEntry struct {
 	MessageID string
@@ -8,32 +14,25 @@ type menuEntry struct {
 }
 
 type menuStore struct {
-	mu    sync.RWMutex
-	items map[int64]menuEntry
+	table sessionTable[menuEntry]
 }
 
-func newMenuStore() *menuStore {
-	return &menuStore{items: make(map[int64]menuEntry)}
+func newMenuStore(store storage.Store, ttl time.Duration, logger *zap.Logger) *menuStore {
+	return &menuStore{table: newSessionTable[menuEntry](store, sessionBucketMenu, ttl, logger)}
 }
 
 func (s *menuStore) get(chatID int64) (menuEntry, bool) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	value, ok := s.items[chatID]
-	return value, ok
+	value, ok := s.table.load(chatID)
+	if !ok {
+		return menuEntry{}, false
+	}
+	return *value, true
 }
 
 func (s *menuStore) set(chatID int64, messageID string, userID int64) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	s.items[chatID] = menuEntry{MessageID: messageID, UserID: userID}
+	s.table.save(chatID, &menuEntry{MessageID: messageID, UserID: userID})
 }
 
 func (s *menuStore) delete(chatID int64) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	delete(s.items, chatID)
+	s.table.remove(chatID)
 }
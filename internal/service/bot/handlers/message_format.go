@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"html"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	messageFormatMarkdown = "markdown"
+	messageFormatHTML     = "html"
+
+	// maxMessageLength is the most characters MAX takes in one message.
+	maxMessageLength = 4000
+)
+
+// Texts are put together in MAX markdown: locale texts are written in it
+// and user content goes in through escapeMarkdown. The format setting only
+// decides what is sent: the markdown as is, or the same text as HTML.
+
+// markdownSpecials are the characters MAX markdown gives a meaning to.
+const markdownSpecials = "\\*_~+`[]"
+
+// escapeMarkdown makes user content such as names and task descriptions
+// safe to put into a message text, so a name like "*star_" shows as
+// written instead of breaking the formatting.
+func escapeMarkdown(text string) string {
+	if !strings.ContainsAny(text, markdownSpecials) {
+		return text
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(text) + 8)
+	for _, r := range text {
+		if strings.ContainsRune(markdownSpecials, r) {
+			builder.WriteByte('\\')
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+// messageFormat resolves the configured message format. Anything but html
+// means markdown.
+func messageFormat(configured string) string {
+	if strings.EqualFold(strings.TrimSpace(configured), messageFormatHTML) {
+		return messageFormatHTML
+	}
+	return messageFormatMarkdown
+}
+
+// formatText turns a markdown text into what is sent in the configured
+// format.
+func (h *MessageHandler) formatText(text string) string {
+	if h.format == messageFormatHTML {
+		return markdownToHTML(text)
+	}
+	return text
+}
+
+// splitText cuts text into parts that stay within maxMessageLength once
+// formatted. It cuts between paragraphs where it can, then between lines,
+// and only cuts a line when a single line is too long.
+func (h *MessageHandler) splitText(text string) []string {
+	fits := func(part string) bool {
+		return utf8.RuneCountInString(h.formatText(part)) <= maxMessageLength
+	}
+	if fits(text) {
+		return []string{text}
+	}
+
+	var parts []string
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		parts = appendPart(parts, paragraph, "\n\n", fits, func(paragraph string) []string {
+			var lines []string
+			for _, line := range strings.Split(paragraph, "\n") {
+				lines = appendPart(lines, line, "\n", fits, cutLine(fits))
+			}
+			return lines
+		})
+	}
+	return parts
+}
+
+// appendPart adds piece to the last part if the two still fit, and starts a
+// new part otherwise. A piece that does not fit on its own is split first.
+func appendPart(parts []string, piece, sep string, fits func(string) bool, split func(string) []string) []string {
+	if n := len(parts); n > 0 && fits(parts[n-1]+sep+piece) {
+		parts[n-1] += sep + piece
+		return parts
+	}
+	if fits(piece) {
+		return append(parts, piece)
+	}
+	return append(parts, split(piece)...)
+}
+
+// cutLine splits a line that is too long by itself at the last space that
+// fits, or anywhere if there is none. An escape is never cut from the
+// character it escapes.
+func cutLine(fits func(string) bool) func(string) []string {
+	return func(line string) []string {
+		var parts []string
+		runes := []rune(line)
+		for len(runes) > 0 {
+			end := len(runes)
+			if !fits(string(runes)) {
+				end = max(sort.Search(len(runes), func(n int) bool { return !fits(string(runes[:n+1])) }), 1)
+			}
+			if end < len(runes) {
+				if space := lastSpace(runes[:end]); space > 0 {
+					end = space
+				}
+				if end > 1 && trailingBackslashes(runes[:end])%2 == 1 {
+					end--
+				}
+			}
+			parts = append(parts, strings.TrimSpace(string(runes[:end])))
+			runes = []rune(strings.TrimLeftFunc(string(runes[end:]), unicode.IsSpace))
+		}
+		return parts
+	}
+}
+
+// trailingBackslashes counts the backslashes runes ends with. An odd count
+// means the last one escapes whatever comes next.
+func trailingBackslashes(runes []rune) int {
+	n := 0
+	for n < len(runes) && runes[len(runes)-1-n] == '\\' {
+		n++
+	}
+	return n
+}
+
+func lastSpace(runes []rune) int {
+	for i := len(runes) - 1; i > 0; i-- {
+		if unicode.IsSpace(runes[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// htmlTags maps MAX markdown markers to HTML tags, longest marker first so
+// "**" is not read as two "*".
+var htmlTags = []struct {
+	marker string
+	tag    string
+}{
+	{"**", "b"},
+	{"__", "b"},
+	{"~~", "s"},
+	{"++", "u"},
+	{"*", "i"},
+	{"_", "i"},
+}
+
+// markdownToHTML rewrites a MAX markdown text as HTML. Escaped characters
+// and everything that is not markup come out HTML-escaped; a marker with no
+// closing pair is kept as text, the way MAX shows it. Tags always nest: a
+// marker closed out of order closes the tags opened after it and reopens
+// them.
+func markdownToHTML(text string) string {
+	runes := []rune(text)
+
+	var builder strings.Builder
+	var open []int // indexes into htmlTags, innermost last
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case r == '\\' && i+1 < len(runes):
+			builder.WriteString(html.EscapeString(string(runes[i+1])))
+			i += 2
+			continue
+		case r == '`':
+			if end := indexUnescaped(runes, i+1, "`"); end >= 0 {
+				builder.WriteString("<code>" + html.EscapeString(string(runes[i+1:end])) + "</code>")
+				i = end + 1
+				continue
+			}
+		case r == '[':
+			if label, url, next, ok := parseLink(runes, i); ok {
+				builder.WriteString(`<a href="` + html.EscapeString(url) + `">` + markdownToHTML(label) + "</a>")
+				i = next
+				continue
+			}
+		}
+
+		// The innermost open marker closes first, so "***x***" reads
+		// as <b><i>x</i></b>.
+		tag := -1
+		if n := len(open); n > 0 && markerAt(runes, i, htmlTags[open[n-1]].marker) {
+			tag = open[n-1]
+		} else {
+			tag = markerTag(runes, i)
+		}
+
+		if tag >= 0 {
+			marker := htmlTags[tag].marker
+			end := i + len([]rune(marker))
+			if depth := slices.Index(open, tag); depth >= 0 {
+				for _, inner := range slices.Backward(open[depth+1:]) {
+					builder.WriteString("</" + htmlTags[inner].tag + ">")
+				}
+				builder.WriteString("</" + htmlTags[tag].tag + ">")
+				for _, inner := range open[depth+1:] {
+					builder.WriteString("<" + htmlTags[inner].tag + ">")
+				}
+				open = slices.Delete(open, depth, depth+1)
+				i = end
+				continue
+			}
+			if indexUnescaped(runes, end, marker) >= 0 {
+				builder.WriteString("<" + htmlTags[tag].tag + ">")
+				open = append(open, tag)
+				i = end
+				continue
+			}
+		}
+
+		builder.WriteString(html.EscapeString(string(r)))
+		i++
+	}
+	for _, tag := range slices.Backward(open) {
+		builder.WriteString("</" + htmlTags[tag].tag + ">")
+	}
+	return builder.String()
+}
+
+// markerTag returns the index in htmlTags of the formatting marker that
+// starts at runes[i], or -1.
+func markerTag(runes []rune, i int) int {
+	for idx, candidate := range htmlTags {
+		if markerAt(runes, i, candidate.marker) {
+			return idx
+		}
+	}
+	return -1
+}
+
+// markerAt reports whether marker starts at runes[i]. An underscore inside a
+// word, as in @dobrika_support, is not a marker.
+func markerAt(runes []rune, i int, marker string) bool {
+	m := []rune(marker)
+	if i+len(m) > len(runes) || string(runes[i:i+len(m)]) != marker {
+		return false
+	}
+	return marker != "_" || i == 0 || i+1 >= len(runes) || !isWordRune(runes[i-1]) || !isWordRune(runes[i+1])
+}
+
+// indexUnescaped finds marker in runes from start on, skipping escaped
+// characters, or returns -1.
+func indexUnescaped(runes []rune, start int, marker string) int {
+	m := []rune(marker)
+	for i := start; i+len(m) <= len(runes); i++ {
+		if runes[i] == '\\' {
+			i++
+			continue
+		}
+		if string(runes[i:i+len(m)]) == marker {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseLink reads a [label](url) link that starts at runes[i].
+func parseLink(runes []rune, i int) (label, url string, next int, ok bool) {
+	closeLabel := indexUnescaped(runes, i+1, "](")
+	if closeLabel < 0 {
+		return "", "", 0, false
+	}
+	closeURL := indexUnescaped(runes, closeLabel+2, ")")
+	if closeURL < 0 {
+		return "", "", 0, false
+	}
+	return string(runes[i+1 : closeLabel]), string(runes[closeLabel+2 : closeURL]), closeURL + 1, true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
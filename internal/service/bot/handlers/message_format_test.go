@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"html"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+)
+
+func TestEscapeMarkdown(t *testing.T) {
+	for _, special := range markdownSpecials {
+		text := "a" + string(special) + "b"
+		want := "a\\" + string(special) + "b"
+
+		got := escapeMarkdown(text)
+		if got != want {
+			t.Errorf("escapeMarkdown(%q) = %q, want %q", text, got, want)
+		}
+		// Escaped content reads as written once rendered.
+		if rendered := markdownToHTML(got); rendered != html.EscapeString(text) {
+			t.Errorf("markdownToHTML(%q) = %q, want %q", got, rendered, html.EscapeString(text))
+		}
+	}
+
+	for _, text := range []string{"", "Алиса Иванова", "@dobrika", "2 + 2 = 4 < 5"} {
+		if !strings.ContainsAny(text, markdownSpecials) && escapeMarkdown(text) != text {
+			t.Errorf("escapeMarkdown(%q) = %q, want it unchanged", text, escapeMarkdown(text))
+		}
+	}
+
+	if got, want := escapeMarkdown(`*star_ [x](y) \`), `\*star\_ \[x\](y) \\`; got != want {
+		t.Errorf("escapeMarkdown = %q, want %q", got, want)
+	}
+}
+
+func TestMarkdownToHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "Привет, мир", "Привет, мир"},
+		{"html is escaped", `<b>"x" & 'y'</b>`, "&lt;b&gt;&#34;x&#34; &amp; &#39;y&#39;&lt;/b&gt;"},
+		{"bold", "**bold** and __bold__", "<b>bold</b> and <b>bold</b>"},
+		{"italic", "*it* and _it_", "<i>it</i> and <i>it</i>"},
+		{"strike and underline", "~~gone~~ ++under++", "<s>gone</s> <u>under</u>"},
+		{"nested", "**bold _it_ bold**", "<b>bold <i>it</i> bold</b>"},
+		{"nested stars", "***both***", "<b><i>both</i></b>"},
+		{"unclosed", "2 * 3 = 6", "2 * 3 = 6"},
+		{"unclosed double", "**open", "**open"},
+		{"closed after unclosed", "a * b *c*", "a <i> b </i>c*"},
+		{"intra-word underscore", "@dobrika_support", "@dobrika_support"},
+		{"intra-word underscores", "Пиши @dobrika_support или snake_case_name", "Пиши @dobrika_support или snake_case_name"},
+		{"underscore around word", "_@dobrika_support_", "<i>@dobrika_support</i>"},
+		{"escaped markers", `\*not\* \_it\_`, "*not* _it_"},
+		{"escaped backslash", `a\\*b*`, `a\<i>b</i>`},
+		{"trailing backslash", `end\`, `end\`},
+		{"escaped brackets", `\[x\](https://a.b)`, "[x](https://a.b)"},
+		{"link", "[site](https://a.b/?q=1&r=2)", `<a href="https://a.b/?q=1&amp;r=2">site</a>`},
+		{"link with markup", "[**Добрика**](https://a.b)", `<a href="https://a.b"><b>Добрика</b></a>`},
+		{"link with escaped bracket", `[a\]b](https://a.b)`, `<a href="https://a.b">a]b</a>`},
+		{"not a link", "[x] (y)", "[x] (y)"},
+		{"misnested", "**a _b** c_", "<b>a <i>b</i></b><i> c</i>"},
+		{"code", "`a*b*c`", "<code>a*b*c</code>"},
+		{"pair hidden in code", "*a `b*` c", "<i>a <code>b*</code> c</i>"},
+		{"unclosed code", "`a *b*", "`a <i>b</i>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := markdownToHTML(tt.in); got != tt.want {
+				t.Fatalf("markdownToHTML(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitText(t *testing.T) {
+	var paragraphs []string
+	for i := 0; i < 40; i++ {
+		paragraphs = append(paragraphs, strings.Repeat("**Дело** — "+escapeMarkdown("*помочь_соседу* [срочно] & <быстро>")+"\n", 4))
+	}
+	long := strings.Join(paragraphs, "\n")
+
+	tests := []struct {
+		name  string
+		text  string
+		parts int
+	}{
+		{"short", "Короткий *текст*", 1},
+		{"paragraphs", long, 0},
+		{"one long line", strings.Repeat("слово ", 1000), 0},
+		{"no spaces", strings.Repeat("я", 9000), 3},
+		{"escapes", strings.Repeat(`x\*`, 3000), 0},
+		{"escaped backslashes", strings.Repeat(`\\`, 5000), 0},
+		{"odd backslash runs", strings.Repeat(`\\\*`, 3000), 0},
+	}
+
+	for _, format := range []string{messageFormatMarkdown, messageFormatHTML} {
+		h := &MessageHandler{format: format}
+		for _, tt := range tests {
+			t.Run(format+"/"+tt.name, func(t *testing.T) {
+				parts := h.splitText(tt.text)
+				if tt.parts > 0 && len(parts) != tt.parts {
+					t.Fatalf("got %d parts, want %d", len(parts), tt.parts)
+				}
+				if tt.parts != 1 && len(parts) < 2 {
+					t.Fatalf("got %d parts, want the text split", len(parts))
+				}
+
+				for i, part := range parts {
+					if n := utf8.RuneCountInString(h.formatText(part)); n > maxMessageLength {
+						t.Fatalf("part %d is %d characters once formatted, limit %d", i, n, maxMessageLength)
+					}
+					if part == "" {
+						t.Fatalf("part %d is empty", i)
+					}
+					if danglingEscape(part) {
+						t.Fatalf("part %d ends in a backslash cut from what it escapes: %q", i, tail(part))
+					}
+				}
+				if got, want := withoutSpace(strings.Join(parts, "")), withoutSpace(tt.text); got != want {
+					t.Fatal("the parts do not add up to the text")
+				}
+			})
+		}
+	}
+}
+
+// danglingEscape reports whether text ends in a backslash with nothing left
+// to escape.
+func danglingEscape(text string) bool {
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '\\' {
+			if i+1 == len(runes) {
+				return true
+			}
+			i++
+		}
+	}
+	return false
+}
+
+func withoutSpace(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+func tail(text string) string {
+	runes := []rune(text)
+	return string(runes[max(len(runes)-20, 0):])
+}
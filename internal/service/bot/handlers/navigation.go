@@ -1,65 +1,213 @@
+// Code generated by callbackgen from routes.go; DO NOT EDIT.
+
 package handlers
 
 const (
-	callbackMainMenuRegistration     = "nav:registration:start"
-	callbackMainMenuHelp             = "nav:main:help"
-	callbackMainMenuNeedHelp         = "nav:main:need_help"
-	callbackMainMenuProfile          = "nav:main:profile"
-	callbackMainMenuAbout            = "nav:main:about"
-	callbackProfileHistory           = "profile:history"
-	callbackProfileEdit              = "profile:edit"
-	callbackProfileSecurity          = "profile:security"
-	callbackProfileBack              = "profile:back"
-	callbackProfileCoins             = "profile:coins"
-	callbackCoinsHowToGet            = "profile:coins:get"
-	callbackCoinsHowToSpend          = "profile:coins:spend"
-	callbackCoinsLevels              = "profile:coins:levels"
-	callbackAboutHowItWorks          = "about:how"
-	callbackAboutRules               = "about:rules"
-	callbackAboutInitiator           = "about:initiator"
-	callbackAboutSupport             = "about:support"
-	callbackAboutBack                = "about:back"
-	callbackVolunteerBack            = "volunteer:back"
-	callbackVolunteerOnDemand        = "volunteer:on_demand"
-	callbackVolunteerTasks           = "volunteer:tasks"
-	callbackVolunteerTaskView        = "volunteer:task:view"
-	callbackVolunteerTaskJoin        = "volunteer:task:join"
-	callbackVolunteerTaskLeave       = "volunteer:task:leave"
-	callbackVolunteerTaskConfirm     = "volunteer:task:confirm"
-	callbackVolunteerTasksPage       = "volunteer:tasks:page"
-	callbackVolunteerTasksFilter     = "volunteer:tasks:filter"
-	callbackVolunteerLocationSkip    = "volunteer:location:skip"
-	callbackCustomerTaskView         = "customer:task:view"
-	callbackCustomerTaskAssignment   = "customer:task:assignment"
-	callbackCustomerTaskApprove      = "customer:task:approve"
-	callbackCustomerTaskReject       = "customer:task:reject"
-	callbackCustomerTasksPage        = "customer:tasks:page"
-	callbackTaskCreateModeOnline     = "task:create:mode:online"
-	callbackTaskCreateModeOffline    = "task:create:mode:offline"
-	callbackTaskCreateSkipMembers    = "task:create:members:skip"
-	callbackTaskCreateConfirm        = "task:create:confirm"
-	callbackTaskCreateRestart        = "task:create:restart"
-	callbackTaskCreateSkipLocation   = "task:create:location:skip"
-	callbackRegistrationAgeUnder18   = "registration:age:under18"
-	callbackRegistrationAge18_24     = "registration:age:18_24"
-	callbackRegistrationAge25_34     = "registration:age:25_34"
-	callbackRegistrationAge35_44     = "registration:age:35_44"
-	callbackRegistrationAge45_54     = "registration:age:45_54"
-	callbackRegistrationAge55_64     = "registration:age:55_64"
-	callbackRegistrationAge65Plus    = "registration:age:65_plus"
-	callbackRegistrationSexMale      = "registration:sex:male"
-	callbackRegistrationSexFemale    = "registration:sex:female"
-	callbackRegistrationSkipLocation = "registration:location:skip"
-	callbackRegistrationAboutToggle  = "registration:about:toggle"
-	callbackRegistrationAboutConfirm = "registration:about:confirm"
-	callbackCustomerTypeIndividual   = "customer:type:individual"
-	callbackCustomerTypeBusiness     = "customer:type:business"
-	callbackCustomerManageCreate     = "customer:manage:create"
-	callbackCustomerManageUpdate     = "customer:manage:update"
-	callbackCustomerManageDelete     = "customer:manage:delete"
-	callbackCustomerManageBack       = "customer:manage:back"
-	callbackCustomerManageTasks      = "customer:manage:tasks"
-	callbackCustomerManageCreateTask = "customer:manage:create_task"
-	callbackCustomerDeleteConfirm    = "customer:delete:confirm"
-	callbackCustomerDeleteCancel     = "customer:delete:cancel"
+	callbackMainMenuRegistration      = "nav:registration:start"
+	callbackProfileEditKeep           = "profile:edit:keep"
+	callbackProfileEditSave           = "profile:edit:save"
+	callbackProfileEditCancel         = "profile:edit:cancel"
+	callbackRegistrationSexMale       = "registration:sex:male"
+	callbackRegistrationSexFemale     = "registration:sex:female"
+	callbackRegistrationAgeUnder18    = "registration:age:under18"
+	callbackRegistrationAge18_24      = "registration:age:18_24"
+	callbackRegistrationAge25_34      = "registration:age:25_34"
+	callbackRegistrationAge35_44      = "registration:age:35_44"
+	callbackRegistrationAge45_54      = "registration:age:45_54"
+	callbackRegistrationAge55_64      = "registration:age:55_64"
+	callbackRegistrationAge65Plus     = "registration:age:65_plus"
+	callbackRegistrationSkipLocation  = "registration:location:skip"
+	callbackRegistrationAboutConfirm  = "registration:about:confirm"
+	callbackRegistrationAboutToggle   = "registration:about:toggle"
+	callbackMainMenuNeedHelp          = "nav:main:need_help"
+	callbackCustomerTypeIndividual    = "customer:type:individual"
+	callbackCustomerTypeBusiness      = "customer:type:business"
+	callbackCustomerManageCreate      = "customer:manage:create"
+	callbackCustomerManageUpdate      = "customer:manage:update"
+	callbackCustomerManageDelete      = "customer:manage:delete"
+	callbackCustomerDeleteConfirm     = "customer:delete:confirm"
+	callbackCustomerDeleteCancel      = "customer:delete:cancel"
+	callbackCustomerManageBack        = "customer:manage:back"
+	callbackCustomerManageTasks       = "customer:manage:tasks"
+	callbackCustomerManageCreateTask  = "customer:manage:create_task"
+	callbackTaskCreateModeOnline      = "task:create:mode:online"
+	callbackTaskCreateModeOffline     = "task:create:mode:offline"
+	callbackTaskCreateSkipLocation    = "task:create:location:skip"
+	callbackTaskCreateSkipMembers     = "task:create:members:skip"
+	callbackTaskCreateConfirm         = "task:create:confirm"
+	callbackTaskCreateRestart         = "task:create:restart"
+	callbackTaskEditKeep              = "task:edit:keep"
+	callbackTaskEditCancel            = "task:edit:cancel"
+	callbackMainMenuHelp              = "nav:main:help"
+	callbackVolunteerBack             = "volunteer:back"
+	callbackVolunteerOnDemand         = "volunteer:on_demand"
+	callbackVolunteerTasks            = "volunteer:tasks"
+	callbackVolunteerLocationSkip     = "volunteer:location:skip"
+	callbackVolunteerTasksFilter      = "volunteer:tasks:filter"
+	callbackVolunteerTasksPage        = "volunteer:tasks:page"
+	callbackVolunteerTaskView         = "volunteer:task:view"
+	callbackVolunteerTaskJoin         = "volunteer:task:join"
+	callbackVolunteerTaskLeave        = "volunteer:task:leave"
+	callbackVolunteerTaskConfirm      = "volunteer:task:confirm"
+	callbackCustomerTasksPage         = "customer:tasks:page"
+	callbackCustomerTaskView          = "customer:task:view"
+	callbackCustomerTaskEdit          = "customer:task:edit"
+	callbackCustomerTaskCancelAsk     = "customer:task:cancel:ask"
+	callbackCustomerTaskCancelConfirm = "customer:task:cancel:confirm"
+	callbackCustomerTaskAssignment    = "customer:task:assignment"
+	callbackCustomerTaskApprove       = "customer:task:approve"
+	callbackCustomerTaskReject        = "customer:task:reject"
+	callbackCustomerFeedbackStart     = "customer:feedback:start"
+	callbackCustomerFeedbackSkip      = "customer:feedback:skip"
+	callbackCustomerFeedbackRate      = "customer:feedback:rate"
+	callbackCustomerFeedbackSubmit    = "customer:feedback:submit"
+	callbackMainMenuProfile           = "nav:main:profile"
+	callbackProfileBack               = "profile:back"
+	callbackProfileHistory            = "profile:history"
+	callbackProfileHistoryPage        = "profile:history:page"
+	callbackProfileEdit               = "profile:edit"
+	callbackProfileSecurity           = "profile:security"
+	callbackProfileData               = "profile:data"
+	callbackProfileExport             = "profile:data:export"
+	callbackProfileDeleteAsk          = "profile:data:delete"
+	callbackProfileDeleteConfirm      = "profile:data:delete:confirm"
+	callbackProfileDeleteExecute      = "profile:data:delete:execute"
+	callbackProfileCoins              = "profile:coins"
+	callbackCoinsHowToGet             = "profile:coins:get"
+	callbackCoinsHowToSpend           = "profile:coins:spend"
+	callbackCoinsLevels               = "profile:coins:levels"
+	callbackProfileNotify             = "profile:notify"
+	callbackProfileNotifyToggle       = "profile:notify:toggle"
+	callbackProfileNotifyQuiet        = "profile:notify:quiet"
+	callbackProfileLanguage           = "profile:language"
+	callbackProfileLanguageSet        = "profile:language:set"
+	callbackAdminMenu                 = "admin:menu"
+	callbackAdminUsers                = "admin:users"
+	callbackAdminUsersSearch          = "admin:users:search"
+	callbackAdminUser                 = "admin:user"
+	callbackAdminUserStatus           = "admin:user:status"
+	callbackAdminCoins                = "admin:coins"
+	callbackAdminCoinsConfirm         = "admin:coins:confirm"
+	callbackAdminTasks                = "admin:tasks"
+	callbackAdminTask                 = "admin:task"
+	callbackAdminTaskDeleteAsk        = "admin:task:delete:ask"
+	callbackAdminTaskDelete           = "admin:task:delete:do"
+	callbackAdminAudit                = "admin:audit"
+	callbackAdminPayouts              = "admin:payouts"
+	callbackAdminPayoutView           = "admin:payouts:view"
+	callbackAdminPayoutRetry          = "admin:payouts:retry"
+	callbackAdminPayoutRevertAsk      = "admin:payouts:revert:ask"
+	callbackAdminPayoutRevert         = "admin:payouts:revert:do"
+	callbackMainMenuAbout             = "nav:main:about"
+	callbackAboutHowItWorks           = "about:how"
+	callbackAboutRules                = "about:rules"
+	callbackAboutInitiator            = "about:initiator"
+	callbackAboutSupport              = "about:support"
+	callbackAboutBack                 = "about:back"
 )
+
+// callbackPrefixByName lets the router check at startup that this file
+// matches the route table it was generated from.
+var callbackPrefixByName = map[string]string{
+	"callbackMainMenuRegistration":      callbackMainMenuRegistration,
+	"callbackProfileEditKeep":           callbackProfileEditKeep,
+	"callbackProfileEditSave":           callbackProfileEditSave,
+	"callbackProfileEditCancel":         callbackProfileEditCancel,
+	"callbackRegistrationSexMale":       callbackRegistrationSexMale,
+	"callbackRegistrationSexFemale":     callbackRegistrationSexFemale,
+	"callbackRegistrationAgeUnder18":    callbackRegistrationAgeUnder18,
+	"callbackRegistrationAge18_24":      callbackRegistrationAge18_24,
+	"callbackRegistrationAge25_34":      callbackRegistrationAge25_34,
+	"callbackRegistrationAge35_44":      callbackRegistrationAge35_44,
+	"callbackRegistrationAge45_54":      callbackRegistrationAge45_54,
+	"callbackRegistrationAge55_64":      callbackRegistrationAge55_64,
+	"callbackRegistrationAge65Plus":     callbackRegistrationAge65Plus,
+	"callbackRegistrationSkipLocation":  callbackRegistrationSkipLocation,
+	"callbackRegistrationAboutConfirm":  callbackRegistrationAboutConfirm,
+	"callbackRegistrationAboutToggle":   callbackRegistrationAboutToggle,
+	"callbackMainMenuNeedHelp":          callbackMainMenuNeedHelp,
+	"callbackCustomerTypeIndividual":    callbackCustomerTypeIndividual,
+	"callbackCustomerTypeBusiness":      callbackCustomerTypeBusiness,
+	"callbackCustomerManageCreate":      callbackCustomerManageCreate,
+	"callbackCustomerManageUpdate":      callbackCustomerManageUpdate,
+	"callbackCustomerManageDelete":      callbackCustomerManageDelete,
+	"callbackCustomerDeleteConfirm":     callbackCustomerDeleteConfirm,
+	"callbackCustomerDeleteCancel":      callbackCustomerDeleteCancel,
+	"callbackCustomerManageBack":        callbackCustomerManageBack,
+	"callbackCustomerManageTasks":       callbackCustomerManageTasks,
+	"callbackCustomerManageCreateTask":  callbackCustomerManageCreateTask,
+	"callbackTaskCreateModeOnline":      callbackTaskCreateModeOnline,
+	"callbackTaskCreateModeOffline":     callbackTaskCreateModeOffline,
+	"callbackTaskCreateSkipLocation":    callbackTaskCreateSkipLocation,
+	"callbackTaskCreateSkipMembers":     callbackTaskCreateSkipMembers,
+	"callbackTaskCreateConfirm":         callbackTaskCreateConfirm,
+	"callbackTaskCreateRestart":         callbackTaskCreateRestart,
+	"callbackTaskEditKeep":              callbackTaskEditKeep,
+	"callbackTaskEditCancel":            callbackTaskEditCancel,
+	"callbackMainMenuHelp":              callbackMainMenuHelp,
+	"callbackVolunteerBack":             callbackVolunteerBack,
+	"callbackVolunteerOnDemand":         callbackVolunteerOnDemand,
+	"callbackVolunteerTasks":            callbackVolunteerTasks,
+	"callbackVolunteerLocationSkip":     callbackVolunteerLocationSkip,
+	"callbackVolunteerTasksFilter":      callbackVolunteerTasksFilter,
+	"callbackVolunteerTasksPage":        callbackVolunteerTasksPage,
+	"callbackVolunteerTaskView":         callbackVolunteerTaskView,
+	"callbackVolunteerTaskJoin":         callbackVolunteerTaskJoin,
+	"callbackVolunteerTaskLeave":        callbackVolunteerTaskLeave,
+	"callbackVolunteerTaskConfirm":      callbackVolunteerTaskConfirm,
+	"callbackCustomerTasksPage":         callbackCustomerTasksPage,
+	"callbackCustomerTaskView":          callbackCustomerTaskView,
+	"callbackCustomerTaskEdit":          callbackCustomerTaskEdit,
+	"callbackCustomerTaskCancelAsk":     callbackCustomerTaskCancelAsk,
+	"callbackCustomerTaskCancelConfirm": callbackCustomerTaskCancelConfirm,
+	"callbackCustomerTaskAssignment":    callbackCustomerTaskAssignment,
+	"callbackCustomerTaskApprove":       callbackCustomerTaskApprove,
+	"callbackCustomerTaskReject":        callbackCustomerTaskReject,
+	"callbackCustomerFeedbackStart":     callbackCustomerFeedbackStart,
+	"callbackCustomerFeedbackSkip":      callbackCustomerFeedbackSkip,
+	"callbackCustomerFeedbackRate":      callbackCustomerFeedbackRate,
+	"callbackCustomerFeedbackSubmit":    callbackCustomerFeedbackSubmit,
+	"callbackMainMenuProfile":           callbackMainMenuProfile,
+	"callbackProfileBack":               callbackProfileBack,
+	"callbackProfileHistory":            callbackProfileHistory,
+	"callbackProfileHistoryPage":        callbackProfileHistoryPage,
+	"callbackProfileEdit":               callbackProfileEdit,
+	"callbackProfileSecurity":           callbackProfileSecurity,
+	"callbackProfileData":               callbackProfileData,
+	"callbackProfileExport":             callbackProfileExport,
+	"callbackProfileDeleteAsk":          callbackProfileDeleteAsk,
+	"callbackProfileDeleteConfirm":      callbackProfileDeleteConfirm,
+	"callbackProfileDeleteExecute":      callbackProfileDeleteExecute,
+	"callbackProfileCoins":              callbackProfileCoins,
+	"callbackCoinsHowToGet":             callbackCoinsHowToGet,
+	"callbackCoinsHowToSpend":           callbackCoinsHowToSpend,
+	"callbackCoinsLevels":               callbackCoinsLevels,
+	"callbackProfileNotify":             callbackProfileNotify,
+	"callbackProfileNotifyToggle":       callbackProfileNotifyToggle,
+	"callbackProfileNotifyQuiet":        callbackProfileNotifyQuiet,
+	"callbackProfileLanguage":           callbackProfileLanguage,
+	"callbackProfileLanguageSet":        callbackProfileLanguageSet,
+	"callbackAdminMenu":                 callbackAdminMenu,
+	"callbackAdminUsers":                callbackAdminUsers,
+	"callbackAdminUsersSearch":          callbackAdminUsersSearch,
+	"callbackAdminUser":                 callbackAdminUser,
+	"callbackAdminUserStatus":           callbackAdminUserStatus,
+	"callbackAdminCoins":                callbackAdminCoins,
+	"callbackAdminCoinsConfirm":         callbackAdminCoinsConfirm,
+	"callbackAdminTasks":                callbackAdminTasks,
+	"callbackAdminTask":                 callbackAdminTask,
+	"callbackAdminTaskDeleteAsk":        callbackAdminTaskDeleteAsk,
+	"callbackAdminTaskDelete":           callbackAdminTaskDelete,
+	"callbackAdminAudit":                callbackAdminAudit,
+	"callbackAdminPayouts":              callbackAdminPayouts,
+	"callbackAdminPayoutView":           callbackAdminPayoutView,
+	"callbackAdminPayoutRetry":          callbackAdminPayoutRetry,
+	"callbackAdminPayoutRevertAsk":      callbackAdminPayoutRevertAsk,
+	"callbackAdminPayoutRevert":         callbackAdminPayoutRevert,
+	"callbackMainMenuAbout":             callbackMainMenuAbout,
+	"callbackAboutHowItWorks":           callbackAboutHowItWorks,
+	"callbackAboutRules":                callbackAboutRules,
+	"callbackAboutInitiator":            callbackAboutInitiator,
+	"callbackAboutSupport":              callbackAboutSupport,
+	"callbackAboutBack":                 callbackAboutBack,
+}
@@ -0,0 +1,565 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"DobrikaDev/max-bot/internal/storage"
+
+	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultDigestWindow   = time.Minute
+	defaultNotifyTimezone = "Europe/Moscow"
+
+	// notificationQueueLimit caps a digest; older events are dropped first.
+	notificationQueueLimit = 50
+	// digestButtonLimit caps the deep-link buttons under a digest.
+	digestButtonLimit = 5
+
+	notificationSendTimeout = 15 * time.Second
+)
+
+type taskEventKind string
+
+const (
+	taskEventJoined    taskEventKind = "joined"
+	taskEventLeft      taskEventKind = "left"
+	taskEventConfirmed taskEventKind = "confirmed"
+)
+
+// taskEvent is something a volunteer did to a customer's task.
+type taskEvent struct {
+	Kind          taskEventKind `json:"kind"`
+	TaskID        string        `json:"task_id"`
+	TaskName      string        `json:"task_name"`
+	VolunteerID   string        `json:"volunteer_id"`
+	VolunteerName string        `json:"volunteer_name"`
+	At            time.Time     `json:"at"`
+}
+
+// notificationPrefs are the user's notification settings. Quiet hours run
+// from QuietStart to QuietEnd local time and are off when both are equal.
+type notificationPrefs struct {
+	Muted      bool `json:"muted"`
+	QuietStart int  `json:"quiet_start"`
+	QuietEnd   int  `json:"quiet_end"`
+}
+
+// quietHoursPresets are offered in turn by the quiet hours button. The first
+// one is the default for users who never changed it.
+var quietHoursPresets = [][2]int{{23, 8}, {22, 9}, {0, 8}, {0, 0}}
+
+func defaultNotificationPrefs() notificationPrefs {
+	return notificationPrefs{QuietStart: quietHoursPresets[0][0], QuietEnd: quietHoursPresets[0][1]}
+}
+
+// quietUntil reports whether now falls in the quiet hours and, if so, when
+// they end.
+func (p notificationPrefs) quietUntil(now time.Time) (time.Time, bool) {
+	start, end := p.QuietStart, p.QuietEnd
+	if start == end {
+		return time.Time{}, false
+	}
+
+	hour := now.Hour()
+	quiet := hour >= start && hour < end
+	if start > end {
+		quiet = hour >= start || hour < end
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	until := time.Date(now.Year(), now.Month(), now.Day(), end, 0, 0, 0, now.Location())
+	if !until.After(now) {
+		until = until.AddDate(0, 0, 1)
+	}
+	return until, true
+}
+
+// nextQuietPreset returns the preset after the current quiet hours.
+func (p notificationPrefs) nextQuietPreset() notificationPrefs {
+	next := 0
+	for i, preset := range quietHoursPresets {
+		if preset[0] == p.QuietStart && preset[1] == p.QuietEnd {
+			next = (i + 1) % len(quietHoursPresets)
+			break
+		}
+	}
+	p.QuietStart, p.QuietEnd = quietHoursPresets[next][0], quietHoursPresets[next][1]
+	return p
+}
+
+// notificationQueue holds a customer's events that are waiting to be sent.
+type notificationQueue struct {
+	Events     []taskEvent `json:"events"`
+	DueAt      time.Time   `json:"due_at"`
+	LastSentAt time.Time   `json:"last_sent_at"`
+}
+
+// taskNotifier delivers task events to customers. The first event after a
+// quiet period goes out right away; events that follow within the digest
+// window are collected and sent together when it closes. Nothing is sent
+// during the customer's quiet hours, the queue simply waits for them to end.
+// Queues live in the session store and are rescheduled on startup from an
+// index of customers with queued events.
+type taskNotifier struct {
+	mu     sync.Mutex
+	prefs  sessionTable[notificationPrefs]
+	queues sessionTable[notificationQueue]
+	index  keyIndex
+	logger *zap.Logger
+
+	window   time.Duration
+	location *time.Location
+	send     func(ctx context.Context, customerID int64, events []taskEvent)
+	now      func() time.Time
+
+	timers map[int64]*time.Timer
+	closed bool
+}
+
+func newTaskNotifier(store storage.Store, window time.Duration, timezone string, logger *zap.Logger, send func(ctx context.Context, customerID int64, events []taskEvent)) *taskNotifier {
+	if window <= 0 {
+		window = defaultDigestWindow
+	}
+
+	timezone = strings.TrimSpace(timezone)
+	if timezone == "" {
+		timezone = defaultNotifyTimezone
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		logger.Warn("failed to load notification timezone; using UTC+3", zap.Error(err), zap.String("timezone", timezone))
+		location = time.FixedZone("MSK", 3*60*60)
+	}
+
+	return &taskNotifier{
+		prefs: newPersistentTable[notificationPrefs](store, sessionBucketNotifyPrefs, logger),
+		// A queue outlives its events by a day so LastSentAt still spaces
+		// out the next burst.
+		queues:   newSessionTable[notificationQueue](store, sessionBucketNotifyQueue, 24*time.Hour, logger),
+		index:    newKeyIndex(store, sessionBucketNotifyIndex, logger),
+		logger:   logger,
+		window:   window,
+		location: location,
+		send:     send,
+		now:      time.Now,
+		timers:   make(map[int64]*time.Timer),
+	}
+}
+
+func (n *taskNotifier) preferences(userID int64) notificationPrefs {
+	if prefs, ok := n.prefs.load(userID); ok {
+		return *prefs
+	}
+	return defaultNotificationPrefs()
+}
+
+func (n *taskNotifier) setPreferences(userID int64, prefs notificationPrefs) {
+	n.prefs.save(userID, &prefs)
+}
+
+// enqueue queues an event for the customer unless they muted notifications.
+func (n *taskNotifier) enqueue(customerID int64, event taskEvent) {
+	if n.preferences(customerID).Muted {
+		n.logger.Debug("task event dropped, notifications muted", zap.Int64("customer_id", customerID), zap.String("task_id", event.TaskID))
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	queue, ok := n.queues.load(customerID)
+	if !ok {
+		queue = &notificationQueue{}
+	}
+
+	queue.Events = append(queue.Events, event)
+	if len(queue.Events) > notificationQueueLimit {
+		queue.Events = queue.Events[len(queue.Events)-notificationQueueLimit:]
+	}
+	if len(queue.Events) == 1 {
+		queue.DueAt = n.now()
+		if next := queue.LastSentAt.Add(n.window); next.After(queue.DueAt) {
+			queue.DueAt = next
+		}
+	}
+
+	n.queues.save(customerID, queue)
+	n.updateIndexLocked(customerID, true)
+	n.scheduleLocked(customerID, queue.DueAt)
+}
+
+// flush sends the customer's queue if it is due and outside quiet hours,
+// otherwise reschedules it.
+func (n *taskNotifier) flush(customerID int64) {
+	n.mu.Lock()
+	delete(n.timers, customerID)
+
+	queue, ok := n.queues.load(customerID)
+	if !ok || len(queue.Events) == 0 {
+		n.updateIndexLocked(customerID, false)
+		n.mu.Unlock()
+		return
+	}
+
+	now := n.now()
+	if queue.DueAt.After(now) {
+		n.scheduleLocked(customerID, queue.DueAt)
+		n.mu.Unlock()
+		return
+	}
+
+	prefs := n.preferences(customerID)
+	if until, quiet := prefs.quietUntil(now.In(n.location)); quiet && !prefs.Muted {
+		queue.DueAt = until
+		n.queues.save(customerID, queue)
+		n.scheduleLocked(customerID, until)
+		n.mu.Unlock()
+		return
+	}
+
+	events := queue.Events
+	queue.Events = nil
+	queue.DueAt = time.Time{}
+	queue.LastSentAt = now
+	n.queues.save(customerID, queue)
+	n.updateIndexLocked(customerID, false)
+	n.mu.Unlock()
+
+	if prefs.Muted {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notificationSendTimeout)
+	defer cancel()
+	n.send(ctx, customerID, events)
+}
+
+// queued returns the events waiting to be sent to the customer.
+func (n *taskNotifier) queued(customerID int64) []taskEvent {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if queue, ok := n.queues.load(customerID); ok {
+		return queue.Events
+	}
+	return nil
+}
+
+// forget drops the user's settings and queued events, for a deleted account.
+func (n *taskNotifier) forget(userID int64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if timer, ok := n.timers[userID]; ok {
+		timer.Stop()
+		delete(n.timers, userID)
+	}
+	n.prefs.remove(userID)
+	n.queues.remove(userID)
+	n.updateIndexLocked(userID, false)
+}
+
+// resume reschedules the queues left over from the previous run.
+func (n *taskNotifier) resume() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for _, customerID := range n.loadIndexLocked() {
+		queue, ok := n.queues.load(customerID)
+		if !ok || len(queue.Events) == 0 {
+			n.updateIndexLocked(customerID, false)
+			continue
+		}
+		n.scheduleLocked(customerID, queue.DueAt)
+	}
+}
+
+// close stops the timers. Queued events stay in the store for the next run.
+func (n *taskNotifier) close() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.closed = true
+	for customerID, timer := range n.timers {
+		timer.Stop()
+		delete(n.timers, customerID)
+	}
+}
+
+func (n *taskNotifier) scheduleLocked(customerID int64, at time.Time) {
+	if n.closed {
+		return
+	}
+	if timer, ok := n.timers[customerID]; ok {
+		timer.Stop()
+	}
+
+	delay := max(at.Sub(n.now()), 0)
+	n.timers[customerID] = time.AfterFunc(delay, func() { n.flush(customerID) })
+}
+
+func (n *taskNotifier) loadIndexLocked() []int64 {
+	keys := n.index.list()
+	ids := make([]int64, 0, len(keys))
+	for _, key := range keys {
+		if id, err := strconv.ParseInt(key, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (n *taskNotifier) updateIndexLocked(customerID int64, pending bool) {
+	n.index.set(strconv.FormatInt(customerID, 10), pending)
+}
+
+// notifyTaskOwner tells the task's customer what a volunteer just did.
+func (h *MessageHandler) notifyTaskOwner(ctx context.Context, taskID string, volunteerID int64, kind taskEventKind) {
+	if h.notifier == nil {
+		return
+	}
+
+	task, err := h.getTaskByID(ctx, taskID)
+	if err != nil || task == nil {
+		h.logger.Warn("failed to load task for owner notification", zap.Error(err), zap.String("task_id", taskID))
+		return
+	}
+
+	customerID, err := strconv.ParseInt(strings.TrimSpace(task.GetCustomerId()), 10, 64)
+	if err != nil || customerID <= 0 || customerID == volunteerID {
+		return
+	}
+
+	volunteer := strconv.FormatInt(volunteerID, 10)
+	h.notifier.enqueue(customerID, taskEvent{
+		Kind:          kind,
+		TaskID:        taskID,
+		TaskName:      safeTaskName(task.GetName()),
+		VolunteerID:   volunteer,
+		VolunteerName: h.forUser(customerID).lookupUserName(ctx, volunteer),
+		At:            time.Now(),
+	})
+}
+
+// sendTaskEvents sends a single event with a button to the response, or a
+// digest of several with a button per volunteer and task.
+func (h *MessageHandler) sendTaskEvents(ctx context.Context, customerID int64, events []taskEvent) {
+	if len(events) == 0 {
+		return
+	}
+	h = h.forUser(customerID)
+
+	keyboard := h.api.Messages.NewKeyboardBuilder()
+	var text string
+
+	if len(events) == 1 {
+		event := events[0]
+		text = h.taskEventText(event)
+		label := h.taskEventOpenButton()
+		if event.Kind == taskEventLeft {
+			label = h.taskEventTaskButton()
+		}
+		keyboard.AddRow().
+			AddCallback(label, schemes.POSITIVE, taskEventPayload(event))
+	} else {
+		var builder strings.Builder
+		builder.WriteString(h.taskEventDigestTitle())
+		builder.WriteString("\n\n")
+
+		seen := make(map[string]bool)
+		for _, event := range events {
+			builder.WriteString("• ")
+			builder.WriteString(h.taskEventText(event))
+			builder.WriteString("\n")
+
+			payload := taskEventPayload(event)
+			if seen[payload] || len(seen) >= digestButtonLimit {
+				continue
+			}
+			seen[payload] = true
+			keyboard.AddRow().
+				AddCallback(truncateLabel(fmt.Sprintf("%s · %s", event.VolunteerName, event.TaskName), 45), schemes.DEFAULT, payload)
+		}
+		text = strings.TrimSpace(builder.String())
+	}
+
+	keyboard.AddRow().
+		AddCallback(h.taskEventTasksButton(), schemes.DEFAULT, callbackCustomerManageTasks)
+
+	if _, err := h.sendInteractiveMessage(ctx, customerID, customerID, text, keyboard); err != nil {
+		h.logger.Error("failed to send task events", zap.Error(err), zap.Int64("customer_id", customerID), zap.Int("events", len(events)))
+	}
+}
+
+// taskEventPayload opens the volunteer's response, or the task when the
+// volunteer has left it and there is no response to show.
+func taskEventPayload(event taskEvent) string {
+	if event.Kind == taskEventLeft {
+		return fmt.Sprintf("%s:%s", callbackCustomerTaskView, event.TaskID)
+	}
+	return fmt.Sprintf("%s:%s:%s", callbackCustomerTaskAssignment, event.TaskID, event.VolunteerID)
+}
+
+func (h *MessageHandler) taskEventText(event taskEvent) string {
+	var template string
+	switch event.Kind {
+	case taskEventJoined:
+		template = strings.TrimSpace(h.messages.TaskEventJoinedTemplate)
+		if template == "" {
+			template = "🙋 %s откликнулся на задачу «%s»"
+		}
+	case taskEventLeft:
+		template = strings.TrimSpace(h.messages.TaskEventLeftTemplate)
+		if template == "" {
+			template = "🚶 %s больше не участвует в задаче «%s»"
+		}
+	default:
+		template = strings.TrimSpace(h.messages.TaskEventConfirmedTemplate)
+		if template == "" {
+			template = "✅ %s отметил, что помог с задачей «%s». Подтверди выполнение"
+		}
+	}
+	return fmt.Sprintf(template, escapeMarkdown(event.VolunteerName), escapeMarkdown(event.TaskName))
+}
+
+func (h *MessageHandler) showNotificationSettings(ctx context.Context, chatID, userID int64) {
+	prefs := defaultNotificationPrefs()
+	if h.notifier != nil {
+		prefs = h.notifier.preferences(userID)
+	}
+
+	status := h.notificationsOnLabel()
+	toggle := h.notificationMuteButton()
+	if prefs.Muted {
+		status = h.notificationsOffLabel()
+		toggle = h.notificationUnmuteButton()
+	}
+	quiet := h.quietHoursLabel(prefs)
+
+	keyboard := h.api.Messages.NewKeyboardBuilder()
+	keyboard.AddRow().
+		AddCallback(toggle, schemes.DEFAULT, callbackProfileNotifyToggle)
+	keyboard.AddRow().
+		AddCallback(fmt.Sprintf(h.notificationQuietButtonTemplate(), quiet), schemes.DEFAULT, callbackProfileNotifyQuiet)
+	keyboard.AddRow().
+		AddCallback(h.messages.ProfileBackButton, schemes.DEFAULT, callbackMainMenuProfile)
+
+	h.renderMenu(ctx, chatID, userID, fmt.Sprintf(h.notificationSettingsText(), status, quiet), keyboard)
+}
+
+func (h *MessageHandler) toggleNotifications(ctx context.Context, chatID, userID int64) {
+	if h.notifier != nil {
+		prefs := h.notifier.preferences(userID)
+		prefs.Muted = !prefs.Muted
+		h.notifier.setPreferences(userID, prefs)
+	}
+	h.showNotificationSettings(ctx, chatID, userID)
+}
+
+func (h *MessageHandler) cycleQuietHours(ctx context.Context, chatID, userID int64) {
+	if h.notifier != nil {
+		h.notifier.setPreferences(userID, h.notifier.preferences(userID).nextQuietPreset())
+	}
+	h.showNotificationSettings(ctx, chatID, userID)
+}
+
+func (h *MessageHandler) quietHoursLabel(prefs notificationPrefs) string {
+	if prefs.QuietStart == prefs.QuietEnd {
+		return h.notificationQuietOffLabel()
+	}
+	return fmt.Sprintf("%02d:00–%02d:00", prefs.QuietStart, prefs.QuietEnd)
+}
+
+func (h *MessageHandler) taskEventDigestTitle() string {
+	if text := strings.TrimSpace(h.messages.TaskEventDigestTitle); text != "" {
+		return text
+	}
+	return "🔔 Новости по твоим задачам:"
+}
+
+func (h *MessageHandler) taskEventOpenButton() string {
+	if text := strings.TrimSpace(h.messages.TaskEventOpenButton); text != "" {
+		return text
+	}
+	return "Открыть отклик"
+}
+
+func (h *MessageHandler) taskEventTaskButton() string {
+	if text := strings.TrimSpace(h.messages.TaskEventTaskButton); text != "" {
+		return text
+	}
+	return "Открыть задачу"
+}
+
+func (h *MessageHandler) taskEventTasksButton() string {
+	if text := strings.TrimSpace(h.messages.TaskEventTasksButton); text != "" {
+		return text
+	}
+	return "📋 Мои задачи"
+}
+
+func (h *MessageHandler) notificationSettingsButton() string {
+	if text := strings.TrimSpace(h.messages.NotificationSettingsButton); text != "" {
+		return text
+	}
+	return "🔔 Уведомления"
+}
+
+func (h *MessageHandler) notificationSettingsText() string {
+	if text := strings.TrimSpace(h.messages.NotificationSettingsText); text != "" {
+		return text
+	}
+	return "🔔 *Уведомления*\n\nСообщаем, когда волонтёры откликаются на твои задачи, отказываются от них или отмечают выполнение. Если событий много, пришлём их одной сводкой.\n\nУведомления: %s\nТихие часы: %s"
+}
+
+func (h *MessageHandler) notificationsOnLabel() string {
+	if text := strings.TrimSpace(h.messages.NotificationsOnLabel); text != "" {
+		return text
+	}
+	return "включены"
+}
+
+func (h *MessageHandler) notificationsOffLabel() string {
+	if text := strings.TrimSpace(h.messages.NotificationsOffLabel); text != "" {
+		return text
+	}
+	return "выключены"
+}
+
+func (h *MessageHandler) notificationQuietOffLabel() string {
+	if text := strings.TrimSpace(h.messages.NotificationQuietOffLabel); text != "" {
+		return text
+	}
+	return "не заданы"
+}
+
+func (h *MessageHandler) notificationMuteButton() string {
+	if text := strings.TrimSpace(h.messages.NotificationMuteButton); text != "" {
+		return text
+	}
+	return "🔕 Выключить"
+}
+
+func (h *MessageHandler) notificationUnmuteButton() string {
+	if text := strings.TrimSpace(h.messages.NotificationUnmuteButton); text != "" {
+		return text
+	}
+	return "🔔 Включить"
+}
+
+func (h *MessageHandler) notificationQuietButtonTemplate() string {
+	if text := strings.TrimSpace(h.messages.NotificationQuietButtonTemplate); text != "" {
+		return text
+	}
+	return "🌙 Тихие часы: %s"
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"DobrikaDev/max-bot/internal/storage"
+
+	"go.uber.org/zap"
+)
+
+const testCustomerID = 500
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// newTestNotifier runs a notifier in UTC with a one minute digest window on
+// a fake clock. Timers still run on the wall clock, so only queues due right
+// away are flushed on their own; tests flush the others by hand after
+// moving the clock.
+func newTestNotifier(t *testing.T, start time.Time) (*taskNotifier, *fakeClock, chan []taskEvent) {
+	t.Helper()
+
+	store := storage.NewMemoryStore(0)
+	sent := make(chan []taskEvent, 10)
+	n := newTaskNotifier(store, time.Minute, "UTC", zap.NewNop(), func(_ context.Context, customerID int64, events []taskEvent) {
+		if customerID != testCustomerID {
+			t.Errorf("sent to %d, want %d", customerID, testCustomerID)
+		}
+		sent <- events
+	})
+	clock := &fakeClock{now: start}
+	n.now = clock.Now
+	t.Cleanup(func() {
+		n.close()
+		_ = store.Close()
+	})
+	return n, clock, sent
+}
+
+func at(hour, minute int) time.Time {
+	return time.Date(2026, time.March, 10, hour, minute, 0, 0, time.UTC)
+}
+
+func joined(taskID string) taskEvent {
+	return taskEvent{Kind: taskEventJoined, TaskID: taskID, TaskName: "Задача " + taskID, VolunteerID: "200", VolunteerName: "Борис"}
+}
+
+func expectSent(t *testing.T, sent chan []taskEvent, want int) []taskEvent {
+	t.Helper()
+
+	select {
+	case events := <-sent:
+		if len(events) != want {
+			t.Fatalf("sent %d events, want %d", len(events), want)
+		}
+		return events
+	case <-time.After(2 * time.Second):
+		t.Fatalf("nothing sent, want %d events", want)
+		return nil
+	}
+}
+
+func expectNothingSent(t *testing.T, sent chan []taskEvent) {
+	t.Helper()
+
+	select {
+	case events := <-sent:
+		t.Fatalf("sent %d events, want none", len(events))
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func (n *taskNotifier) queueState(customerID int64) (notificationQueue, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	queue, ok := n.queues.load(customerID)
+	if !ok {
+		return notificationQueue{}, false
+	}
+	return *queue, true
+}
+
+func TestNotifierSendsFirstEventRightAway(t *testing.T) {
+	n, _, sent := newTestNotifier(t, at(12, 0))
+
+	n.enqueue(testCustomerID, joined("1"))
+
+	events := expectSent(t, sent, 1)
+	if events[0].TaskID != "1" {
+		t.Fatalf("sent %+v", events[0])
+	}
+	if queued := n.queued(testCustomerID); len(queued) != 0 {
+		t.Fatalf("%d events still queued", len(queued))
+	}
+}
+
+func TestNotifierDigestsBurst(t *testing.T) {
+	n, clock, sent := newTestNotifier(t, at(12, 0))
+
+	n.enqueue(testCustomerID, joined("1"))
+	expectSent(t, sent, 1)
+
+	for _, taskID := range []string{"2", "3", "4"} {
+		clock.Advance(10 * time.Second)
+		n.enqueue(testCustomerID, joined(taskID))
+	}
+	expectNothingSent(t, sent)
+
+	queue, _ := n.queueState(testCustomerID)
+	if want := at(12, 1); !queue.DueAt.Equal(want) {
+		t.Fatalf("digest due at %v, want %v, a window after the first event", queue.DueAt, want)
+	}
+
+	// Flushing early only reschedules.
+	n.flush(testCustomerID)
+	expectNothingSent(t, sent)
+
+	clock.Set(at(12, 1))
+	n.flush(testCustomerID)
+	events := expectSent(t, sent, 3)
+	for i, want := range []string{"2", "3", "4"} {
+		if events[i].TaskID != want {
+			t.Fatalf("digest event %d is task %s, want %s", i, events[i].TaskID, want)
+		}
+	}
+
+	// The next event waits out the window after the digest.
+	clock.Advance(30 * time.Second)
+	n.enqueue(testCustomerID, joined("5"))
+	expectNothingSent(t, sent)
+	if queue, _ := n.queueState(testCustomerID); !queue.DueAt.Equal(at(12, 2)) {
+		t.Fatalf("next event due at %v, want %v", queue.DueAt, at(12, 2))
+	}
+}
+
+func TestNotifierQuietHours(t *testing.T) {
+	tests := []struct {
+		name    string
+		quiet   [2]int
+		now     time.Time
+		until   time.Time
+		isQuiet bool
+	}{
+		{"overnight, before midnight", [2]int{23, 8}, at(23, 30), at(8, 0).AddDate(0, 0, 1), true},
+		{"overnight, after midnight", [2]int{23, 8}, at(2, 0), at(8, 0), true},
+		{"overnight, at the start", [2]int{23, 8}, at(23, 0), at(8, 0).AddDate(0, 0, 1), true},
+		{"overnight, at the end", [2]int{23, 8}, at(8, 0), time.Time{}, false},
+		{"overnight, daytime", [2]int{23, 8}, at(12, 0), time.Time{}, false},
+		{"wide, just before", [2]int{22, 9}, at(21, 59), time.Time{}, false},
+		{"wide, morning", [2]int{22, 9}, at(8, 59), at(9, 0), true},
+		{"from midnight", [2]int{0, 8}, at(7, 59), at(8, 0), true},
+		{"from midnight, evening", [2]int{0, 8}, at(23, 59), time.Time{}, false},
+		{"off", [2]int{0, 0}, at(3, 0), time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefs := notificationPrefs{QuietStart: tt.quiet[0], QuietEnd: tt.quiet[1]}
+			until, quiet := prefs.quietUntil(tt.now)
+			if quiet != tt.isQuiet || !until.Equal(tt.until) {
+				t.Fatalf("quietUntil(%v) = %v, %v; want %v, %v", tt.now, until, quiet, tt.until, tt.isQuiet)
+			}
+		})
+	}
+}
+
+func TestNotifierWaitsOutQuietHours(t *testing.T) {
+	// The default quiet hours run overnight from 23 to 8.
+	n, clock, sent := newTestNotifier(t, at(23, 30))
+	morning := at(8, 0).AddDate(0, 0, 1)
+
+	n.enqueue(testCustomerID, joined("1"))
+	expectNothingSent(t, sent)
+
+	// The queue came due at once and was moved to the end of quiet hours.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if queue, _ := n.queueState(testCustomerID); queue.DueAt.Equal(morning) {
+			break
+		}
+		if time.Now().After(deadline) {
+			queue, _ := n.queueState(testCustomerID)
+			t.Fatalf("queue due at %v, want %v", queue.DueAt, morning)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	clock.Set(at(3, 0).AddDate(0, 0, 1))
+	n.enqueue(testCustomerID, joined("2"))
+	n.flush(testCustomerID)
+	expectNothingSent(t, sent)
+
+	clock.Set(morning)
+	n.flush(testCustomerID)
+	expectSent(t, sent, 2)
+}
+
+func TestNotifierMuted(t *testing.T) {
+	n, _, sent := newTestNotifier(t, at(12, 0))
+
+	n.setPreferences(testCustomerID, notificationPrefs{Muted: true})
+	n.enqueue(testCustomerID, joined("1"))
+
+	expectNothingSent(t, sent)
+	if queued := n.queued(testCustomerID); len(queued) != 0 {
+		t.Fatalf("%d events queued for a muted customer", len(queued))
+	}
+}
+
+func TestNotifierMutedWhileQueued(t *testing.T) {
+	n, clock, sent := newTestNotifier(t, at(12, 0))
+
+	n.enqueue(testCustomerID, joined("1"))
+	expectSent(t, sent, 1)
+	n.enqueue(testCustomerID, joined("2"))
+
+	n.setPreferences(testCustomerID, notificationPrefs{Muted: true})
+	clock.Set(at(12, 1))
+	n.flush(testCustomerID)
+
+	expectNothingSent(t, sent)
+	if queued := n.queued(testCustomerID); len(queued) != 0 {
+		t.Fatalf("%d events still queued after muting", len(queued))
+	}
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"DobrikaDev/max-bot/internal/outbox"
+	"DobrikaDev/max-bot/internal/storage"
+
+	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
+	"go.uber.org/zap"
+)
+
+// outboundTagMenu marks sends made by renderMenu, so a menu that goes out
+// after a retry is still remembered as the chat's menu.
+const outboundTagMenu = "menu"
+
+// attachmentNotReady is the error code MAX answers with while an uploaded
+// file is still being processed.
+const attachmentNotReady = "attachment.not.ready"
+
+var errMaxTokenEmpty = errors.New("max token is empty")
+
+// messageAPIError is a non-200 answer from the messages endpoint.
+type messageAPIError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration
+}
+
+func (e *messageAPIError) Error() string {
+	return fmt.Sprintf("http %d: %s", e.StatusCode, e.Body)
+}
+
+func (h *MessageHandler) newMessageOutbox(store storage.Store) *outbox.Outbox {
+	return outbox.New(store, outbox.Options{
+		Transport:   h.deliverOutbound,
+		Classify:    classifyMessageError,
+		Delivered:   h.onOutboundDelivered,
+		MaxAttempts: h.cfg.OutboxMaxAttempts,
+		MaxAge:      h.cfg.OutboxMaxAge,
+	}, h.logger)
+}
+
+// classifyMessageError retries rate limits, server errors, transport
+// failures and attachments that are still being processed after upload.
+// Other API errors mean the request itself is wrong.
+func classifyMessageError(err error) (bool, time.Duration) {
+	var apiErr *messageAPIError
+	if errors.As(err, &apiErr) {
+		if apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= http.StatusInternalServerError {
+			return true, apiErr.RetryAfter
+		}
+		if strings.Contains(apiErr.Body, attachmentNotReady) {
+			return true, 0
+		}
+		return false, 0
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true, 0
+	}
+
+	return isRetryableMessageError(err), 0
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as
+// an HTTP date.
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
+
+// deliverOutbound performs a queued send or edit. The request is made
+// directly rather than through the client library so the Retry-After
+// header of a 429 is not lost.
+func (h *MessageHandler) deliverOutbound(ctx context.Context, msg *outbox.Message) (string, error) {
+	if h.cfg.MaxToken == "" {
+		return "", errMaxTokenEmpty
+	}
+
+	query := url.Values{}
+	query.Set("access_token", h.cfg.MaxToken)
+	query.Set("v", h.apiVersion)
+
+	method := http.MethodPost
+	switch msg.Kind {
+	case outbox.KindEdit:
+		method = http.MethodPut
+		query.Set("message_id", msg.MessageID)
+	default:
+		if msg.ChatID != 0 {
+			query.Set("chat_id", strconv.FormatInt(msg.ChatID, 10))
+		}
+		if msg.UserID != 0 {
+			query.Set("user_id", strconv.FormatInt(msg.UserID, 10))
+		}
+	}
+
+	u := h.apiBaseURL
+	if !strings.HasSuffix(u, "/") {
+		u += "/"
+	}
+	u += "messages"
+
+	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s?%s", u, query.Encode()), bytes.NewReader(msg.Body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s request: %w", msg.Kind, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "max-bot-dynamic-menu/1.0")
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute %s request: %w", msg.Kind, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return "", &messageAPIError{
+			StatusCode: resp.StatusCode,
+			Body:       strings.TrimSpace(string(bodyBytes)),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
+	if msg.Kind == outbox.KindEdit {
+		var result schemes.SimpleQueryResult
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return "", fmt.Errorf("failed to decode edit response: %w", err)
+		}
+		if !result.Success {
+			return "", fmt.Errorf("edit response unsuccessful: %s", result.Message)
+		}
+		return msg.MessageID, nil
+	}
+
+	var result struct {
+		Message schemes.Message `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode send response: %w", err)
+	}
+	return result.Message.Body.Mid, nil
+}
+
+// onOutboundDelivered runs for messages that went out after their sender
+// stopped waiting.
+func (h *MessageHandler) onOutboundDelivered(msg *outbox.Message, messageID string) {
+	h.logger.Info("deferred message delivered", zap.Int64("chat_id", msg.ChatID), zap.String("kind", string(msg.Kind)), zap.Int("attempts", msg.Attempts+1))
+
+	if msg.Kind == outbox.KindSend && msg.Tag == outboundTagMenu && messageID != "" {
+		h.menus.set(msg.ChatID, messageID, msg.UserID)
+	}
+}
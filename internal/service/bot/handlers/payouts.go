@@ -0,0 +1,577 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	taskpb "DobrikaDev/max-bot/internal/generated/taskpb"
+	userpb "DobrikaDev/max-bot/internal/generated/userpb"
+	"DobrikaDev/max-bot/internal/metrics"
+	"DobrikaDev/max-bot/internal/outbox"
+	"DobrikaDev/max-bot/internal/storage"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// payoutDoneTTL is how long a finished payout is remembered, which is
+	// how long a repeated approval is recognised as a duplicate.
+	payoutDoneTTL = 90 * 24 * time.Hour
+	// payoutStuckAttempts is when a payout shows up as stuck for admins.
+	// Retries go on regardless.
+	payoutStuckAttempts = 5
+
+	payoutBaseDelay     = 5 * time.Second
+	payoutMaxDelay      = 15 * time.Minute
+	payoutVerifyDelay   = 30 * time.Second
+	payoutAttemptTime   = 30 * time.Second
+	payoutHistoryWindow = 50
+)
+
+type payoutState string
+
+const (
+	// payoutApproving: ApproveTask was called and its outcome is unknown.
+	payoutApproving payoutState = "approving"
+	// payoutCrediting: the task is approved and the reward is not credited.
+	payoutCrediting payoutState = "crediting"
+	payoutCredited  payoutState = "credited"
+	// payoutReverted: an admin gave up on the reward and rejected the
+	// volunteer instead.
+	payoutReverted payoutState = "reverted"
+)
+
+var errPayoutBusy = errors.New("payout is being processed")
+
+// payoutRecord is one entry of the payout log. Ref goes into the balance
+// operation description so a credit whose response was lost can be found in
+// the volunteer's history instead of being made twice.
+type payoutRecord struct {
+	Key         string      `json:"key"`
+	Ref         string      `json:"ref"`
+	TaskID      string      `json:"task_id"`
+	TaskName    string      `json:"task_name"`
+	VolunteerID string      `json:"volunteer_id"`
+	CustomerID  int64       `json:"customer_id"`
+	Amount      int32       `json:"amount"`
+	State       payoutState `json:"state"`
+	Attempts    int         `json:"attempts"`
+	// Uncertain is saved before each credit attempt and cleared only when
+	// the attempt is known to have failed, so a credit that went through
+	// before a crash or a lost response is looked up instead of repeated.
+	Uncertain   bool      `json:"uncertain,omitempty"`
+	LastError   string    `json:"last_error,omitempty"`
+	NextAttempt time.Time `json:"next_attempt"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (r *payoutRecord) open() bool {
+	return r.State == payoutApproving || r.State == payoutCrediting
+}
+
+func (r *payoutRecord) stuck() bool {
+	return r.State == payoutCrediting && r.Attempts >= payoutStuckAttempts
+}
+
+func payoutKey(taskID, volunteerID string) string {
+	return taskID + ":" + volunteerID
+}
+
+func payoutRef(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:4])
+}
+
+type payoutOutcome int
+
+const (
+	payoutPaid payoutOutcome = iota
+	payoutDeferred
+	payoutDuplicate
+	payoutInProgress
+)
+
+// payoutLog is the durable record of task approvals and their rewards. Each
+// (task, volunteer) pair gets one record, which is what makes a repeated
+// approval harmless. Open records are listed in an index so they can be
+// resumed after a restart.
+type payoutLog struct {
+	mu      sync.Mutex
+	store   storage.Store
+	index   keyIndex
+	logger  *zap.Logger
+	timers  map[string]*time.Timer
+	running map[string]bool
+	closed  bool
+	run     func(key string)
+}
+
+func newPayoutLog(store storage.Store, logger *zap.Logger, run func(key string)) *payoutLog {
+	return &payoutLog{
+		store:   store,
+		index:   newKeyIndex(store, sessionBucketPayoutIndex, logger),
+		logger:  logger,
+		timers:  make(map[string]*time.Timer),
+		running: make(map[string]bool),
+		run:     run,
+	}
+}
+
+func (l *payoutLog) load(key string) (*payoutRecord, bool) {
+	data, ok, err := l.store.Get(sessionBucketPayouts, key)
+	if err != nil {
+		l.logger.Warn("failed to load payout", zap.Error(err), zap.String("key", key))
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+
+	rec := new(payoutRecord)
+	if err := json.Unmarshal(data, rec); err != nil {
+		l.logger.Warn("failed to decode payout", zap.Error(err), zap.String("key", key))
+		return nil, false
+	}
+	return rec, true
+}
+
+// saveLocked writes the record. Open records never expire; finished ones
+// are kept for payoutDoneTTL.
+func (l *payoutLog) saveLocked(rec *payoutRecord) {
+	rec.UpdatedAt = time.Now()
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		l.logger.Error("failed to encode payout", zap.Error(err), zap.String("key", rec.Key))
+		return
+	}
+
+	var ttl time.Duration
+	if !rec.open() {
+		ttl = payoutDoneTTL
+	}
+	if err := l.store.Set(sessionBucketPayouts, rec.Key, data, ttl); err != nil {
+		l.logger.Error("failed to save payout", zap.Error(err), zap.String("key", rec.Key))
+	}
+	l.index.set(rec.Key, rec.open())
+}
+
+func (l *payoutLog) save(rec *payoutRecord) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.saveLocked(rec)
+}
+
+// claim creates the record for a new approval. An existing record means the
+// approval already happened or is under way.
+func (l *payoutLog) claim(rec *payoutRecord) (*payoutRecord, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if existing, ok := l.load(rec.Key); ok {
+		return existing, false
+	}
+
+	l.running[rec.Key] = true
+	l.saveLocked(rec)
+	return rec, true
+}
+
+// discard forgets an approval that did not happen, so it can be retried.
+func (l *payoutLog) discard(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.store.Delete(sessionBucketPayouts, key); err != nil {
+		l.logger.Warn("failed to delete payout", zap.Error(err), zap.String("key", key))
+	}
+	l.index.set(key, false)
+	delete(l.running, key)
+}
+
+// acquire marks the payout as being worked on. It fails while another
+// attempt on the same payout is in flight.
+func (l *payoutLog) acquire(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.running[key] || l.closed {
+		return false
+	}
+	l.running[key] = true
+	return true
+}
+
+func (l *payoutLog) release(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.running, key)
+}
+
+func (l *payoutLog) schedule(key string, at time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return
+	}
+	if timer, ok := l.timers[key]; ok {
+		timer.Stop()
+	}
+	l.timers[key] = time.AfterFunc(max(time.Until(at), 0), func() {
+		l.mu.Lock()
+		delete(l.timers, key)
+		l.mu.Unlock()
+		l.run(key)
+	})
+}
+
+// pending returns the open records, oldest first.
+func (l *payoutLog) pending() []*payoutRecord {
+	l.mu.Lock()
+	keys := l.index.list()
+	l.mu.Unlock()
+
+	records := make([]*payoutRecord, 0, len(keys))
+	for _, key := range keys {
+		if rec, ok := l.load(key); ok && rec.open() {
+			records = append(records, rec)
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool { return records[i].CreatedAt.Before(records[j].CreatedAt) })
+	return records
+}
+
+// resume schedules the open records left over from the previous run.
+func (l *payoutLog) resume() {
+	for _, rec := range l.pending() {
+		l.schedule(rec.Key, rec.NextAttempt)
+	}
+}
+
+func (l *payoutLog) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.closed = true
+	for key, timer := range l.timers {
+		timer.Stop()
+		delete(l.timers, key)
+	}
+}
+
+// approveWithPayout approves the volunteer and credits the reward as one
+// saga. The log entry is written before anything else, so a second click
+// finds it and does nothing. If the credit fails, the approval stands and
+// the credit is retried in the background until it goes through.
+func (h *MessageHandler) approveWithPayout(ctx context.Context, task *taskpb.Task, volunteerID string, customerID int64) (payoutOutcome, error) {
+	taskID := task.GetId()
+	key := payoutKey(taskID, volunteerID)
+	now := time.Now()
+
+	rec, created := h.payouts.claim(&payoutRecord{
+		Key:         key,
+		Ref:         payoutRef(key),
+		TaskID:      taskID,
+		TaskName:    safeTaskName(task.GetName()),
+		VolunteerID: volunteerID,
+		CustomerID:  customerID,
+		Amount:      task.GetCost(),
+		State:       payoutApproving,
+		CreatedAt:   now,
+		NextAttempt: now.Add(payoutVerifyDelay),
+	})
+	if !created {
+		if rec.open() {
+			return payoutInProgress, nil
+		}
+		return payoutDuplicate, nil
+	}
+	defer h.payouts.release(key)
+
+	resp, err := h.task.ApproveTask(ctx, &taskpb.ApproveTaskRequest{UserId: volunteerID, TaskId: taskID})
+	switch {
+	case err != nil:
+		// The approval may still have gone through. Keep the record and
+		// check the task later rather than let a second click approve
+		// again without a reward.
+		h.logger.Warn("approval outcome unknown, will verify", zap.Error(err), zap.String("task_id", taskID), zap.String("volunteer_id", volunteerID))
+		h.payouts.schedule(key, rec.NextAttempt)
+		return 0, err
+	case resp.GetError() != nil:
+		h.payouts.discard(key)
+		return 0, fmt.Errorf("approve task: %s", resp.GetError().GetMessage())
+	}
+
+	rec.State = payoutCrediting
+	if h.creditPayout(ctx, rec) {
+		return payoutPaid, nil
+	}
+	return payoutDeferred, nil
+}
+
+// runPayout continues an open payout from wherever it stopped.
+func (h *MessageHandler) runPayout(key string) {
+	if !h.payouts.acquire(key) {
+		return
+	}
+	defer h.payouts.release(key)
+
+	// A payout finished or reverted in the meantime has nothing left to do.
+	rec, ok := h.payouts.load(key)
+	if !ok || !rec.open() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), payoutAttemptTime)
+	defer cancel()
+
+	if rec.State == payoutApproving && !h.verifyPayoutApproval(ctx, rec) {
+		return
+	}
+	// Whatever the record says, an earlier run may have credited the
+	// reward and stopped before saving it, so the history is checked first.
+	rec.Uncertain = true
+	h.creditPayout(ctx, rec)
+}
+
+// verifyPayoutApproval settles an approval whose response was lost by
+// looking at the volunteer's status on the task.
+func (h *MessageHandler) verifyPayoutApproval(ctx context.Context, rec *payoutRecord) bool {
+	task, err := h.getTaskByID(ctx, rec.TaskID)
+	if err != nil || task == nil {
+		if err != nil {
+			h.logger.Warn("failed to verify payout approval", zap.Error(err), zap.String("task_id", rec.TaskID))
+			h.payouts.schedule(rec.Key, time.Now().Add(payoutVerifyDelay))
+			return false
+		}
+		h.payouts.discard(rec.Key)
+		return false
+	}
+
+	if !isApprovedStatus(assignmentStatusForUser(parseTaskAssignments(task), rec.VolunteerID)) {
+		h.logger.Info("approval did not go through, payout dropped", zap.String("task_id", rec.TaskID), zap.String("volunteer_id", rec.VolunteerID))
+		h.payouts.discard(rec.Key)
+		return false
+	}
+
+	rec.State = payoutCrediting
+	return true
+}
+
+// creditPayout makes one attempt at crediting the reward and reports
+// whether it is credited now. A failure schedules the next attempt.
+func (h *MessageHandler) creditPayout(ctx context.Context, rec *payoutRecord) bool {
+	if rec.Amount <= 0 {
+		h.finishPayout(ctx, rec, metrics.PayoutCredited)
+		return true
+	}
+
+	if h.user == nil {
+		h.retryPayout(rec, errors.New("user service is not configured"), rec.Uncertain)
+		return false
+	}
+
+	if rec.Uncertain {
+		found, err := h.findPayoutOperation(ctx, rec)
+		if err != nil {
+			h.retryPayout(rec, fmt.Errorf("check balance history: %w", err), true)
+			return false
+		}
+		if found {
+			h.finishPayout(ctx, rec, metrics.PayoutReconciled)
+			return true
+		}
+	}
+
+	// From here on the credit may go through without us hearing of it.
+	rec.State = payoutCrediting
+	rec.Uncertain = true
+	h.payouts.save(rec)
+
+	resp, err := h.user.CreateOperation(ctx, &userpb.CreateOperationRequest{
+		MaxId:       rec.VolunteerID,
+		Amount:      rec.Amount,
+		Type:        userpb.BalanceOperationType_BALANCE_OPERATION_TYPE_DEPOSIT,
+		Description: h.payoutDescription(rec),
+	})
+	switch {
+	case err != nil:
+		h.retryPayout(rec, err, true)
+		return false
+	case resp.GetError() != nil:
+		h.retryPayout(rec, errors.New(resp.GetError().GetMessage()), false)
+		return false
+	}
+
+	h.finishPayout(ctx, rec, metrics.PayoutCredited)
+	return true
+}
+
+// findPayoutOperation looks for the deposit carrying the payout reference
+// among the volunteer's latest operations.
+func (h *MessageHandler) findPayoutOperation(ctx context.Context, rec *payoutRecord) (bool, error) {
+	resp, err := h.user.GetBalanceOperations(ctx, &userpb.GetBalanceOperationsRequest{MaxId: rec.VolunteerID, Limit: payoutHistoryWindow})
+	if err != nil {
+		return false, err
+	}
+	if svcErr := resp.GetError(); svcErr != nil {
+		return false, errors.New(svcErr.GetMessage())
+	}
+
+	for _, op := range resp.GetOperations() {
+		if op.GetType() == userpb.BalanceOperationType_BALANCE_OPERATION_TYPE_DEPOSIT && strings.Contains(op.GetDescription(), "#"+rec.Ref) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// retryPayout schedules the next attempt. uncertain says whether the failed
+// attempt may have credited the reward after all.
+func (h *MessageHandler) retryPayout(rec *payoutRecord, err error, uncertain bool) {
+	rec.Attempts++
+	rec.Uncertain = uncertain
+	rec.LastError = err.Error()
+
+	delay := payoutBaseDelay
+	for i := 1; i < rec.Attempts && delay < payoutMaxDelay; i++ {
+		delay *= 2
+	}
+	rec.NextAttempt = time.Now().Add(min(delay, payoutMaxDelay))
+
+	fields := []zap.Field{zap.Error(err), zap.String("task_id", rec.TaskID), zap.String("volunteer_id", rec.VolunteerID), zap.Int("attempts", rec.Attempts)}
+	if rec.Attempts == payoutStuckAttempts {
+		h.logger.Error("reward payout is stuck", fields...)
+		metrics.ObservePayout(metrics.PayoutStuck)
+	} else {
+		h.logger.Warn("reward payout failed, will retry", fields...)
+	}
+	metrics.ObservePayout(metrics.PayoutRetried)
+
+	h.payouts.save(rec)
+	h.payouts.schedule(rec.Key, rec.NextAttempt)
+}
+
+func (h *MessageHandler) finishPayout(ctx context.Context, rec *payoutRecord, result string) {
+	rec.State = payoutCredited
+	rec.LastError = ""
+	h.payouts.save(rec)
+	metrics.ObservePayout(result)
+
+	if rec.Amount > 0 {
+		h.notifyPayoutCredited(ctx, rec)
+	}
+}
+
+func (h *MessageHandler) notifyPayoutCredited(ctx context.Context, rec *payoutRecord) {
+	volunteerID, err := strconv.ParseInt(rec.VolunteerID, 10, 64)
+	if err != nil || volunteerID <= 0 {
+		h.logger.Warn("failed to parse volunteer id for reward notification", zap.String("volunteer_id", rec.VolunteerID), zap.Error(err))
+		return
+	}
+	h = h.forUser(volunteerID)
+
+	notification := strings.TrimSpace(h.volunteerTaskRewardNotification(rec.TaskName, rec.Amount))
+	if notification == "" {
+		return
+	}
+
+	if _, err := h.sendInteractiveMessage(ctx, volunteerID, volunteerID, notification, nil); errors.Is(err, outbox.ErrDeferred) {
+		h.logger.Info("reward notification queued for retry", zap.Error(err), zap.Int64("volunteer_id", volunteerID), zap.String("task_id", rec.TaskID))
+	} else if err != nil {
+		h.logger.Error("failed to send reward notification", zap.Error(err), zap.Int64("volunteer_id", volunteerID), zap.String("task_id", rec.TaskID))
+	}
+}
+
+// revertPayout is the saga's compensation: the reward is given up on and
+// the approval undone by rejecting the volunteer. A credit that may have
+// gone through is looked up first.
+func (h *MessageHandler) revertPayout(ctx context.Context, key string) (*payoutRecord, error) {
+	if !h.payouts.acquire(key) {
+		return nil, errPayoutBusy
+	}
+	defer h.payouts.release(key)
+
+	rec, ok := h.payouts.load(key)
+	if !ok || rec.State != payoutCrediting {
+		return rec, fmt.Errorf("payout %s cannot be reverted", key)
+	}
+
+	if rec.Uncertain && h.user != nil {
+		if found, err := h.findPayoutOperation(ctx, rec); err != nil {
+			return rec, fmt.Errorf("check balance history: %w", err)
+		} else if found {
+			h.finishPayout(ctx, rec, metrics.PayoutReconciled)
+			return rec, nil
+		}
+	}
+
+	if h.task == nil {
+		return rec, errors.New("task service is not configured")
+	}
+	resp, err := h.task.RejectTask(ctx, &taskpb.RejectTaskRequest{UserId: rec.VolunteerID, TaskId: rec.TaskID})
+	if err != nil {
+		return rec, err
+	}
+	if svcErr := resp.GetError(); svcErr != nil {
+		return rec, errors.New(svcErr.GetMessage())
+	}
+
+	rec.State = payoutReverted
+	h.payouts.save(rec)
+	metrics.ObservePayout(metrics.PayoutReverted)
+	h.logger.Warn("reward payout reverted", zap.String("task_id", rec.TaskID), zap.String("volunteer_id", rec.VolunteerID), zap.Int32("amount", rec.Amount))
+	return rec, nil
+}
+
+// retryPayoutNow runs an open payout immediately instead of waiting for its
+// backoff.
+func (h *MessageHandler) retryPayoutNow(key string) {
+	h.payouts.schedule(key, time.Now())
+}
+
+func (h *MessageHandler) payoutDescription(rec *payoutRecord) string {
+	return fmt.Sprintf("%s · #%s", h.customerTaskRewardDescription(rec.TaskName), rec.Ref)
+}
+
+func isApprovedStatus(status string) bool {
+	switch normalizeStatus(status) {
+	case "approved", "accept", "accepted", "completed", "done":
+		return true
+	default:
+		return false
+	}
+}
+
+func (h *MessageHandler) payoutDuplicateText() string {
+	if text := strings.TrimSpace(h.messages.PayoutDuplicateText); text != "" {
+		return text
+	}
+	return "Этот отклик уже подтверждён — награда начисляется только один раз."
+}
+
+func (h *MessageHandler) payoutInProgressText() string {
+	if text := strings.TrimSpace(h.messages.PayoutInProgressText); text != "" {
+		return text
+	}
+	return "Подтверждение уже обрабатывается. Загляни сюда через минуту."
+}
+
+func (h *MessageHandler) payoutDeferredText() string {
+	if text := strings.TrimSpace(h.messages.PayoutDeferredText); text != "" {
+		return text
+	}
+	return "⏳ Награда будет начислена чуть позже — мы уже повторяем попытку."
+}
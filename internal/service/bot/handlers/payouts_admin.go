@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
+	"go.uber.org/zap"
+)
+
+const (
+	adminPayoutsCommand   = "/payouts"
+	adminPayoutsListLimit = 10
+)
+
+func (h *MessageHandler) isAdminPayoutsCommand(message *schemes.MessageCreatedUpdate) bool {
+	return strings.TrimSpace(strings.ToLower(message.GetText())) == adminPayoutsCommand
+}
+
+// showAdminPayouts lists the payouts that are not finished yet, oldest
+// first, stuck ones marked.
+func (h *MessageHandler) showAdminPayouts(ctx context.Context, chatID, userID int64) {
+	records := h.payouts.pending()
+
+	stuck := 0
+	for _, rec := range records {
+		if rec.stuck() {
+			stuck++
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString(h.adminPayoutsTitle())
+	builder.WriteString("\n\n")
+	if len(records) == 0 {
+		builder.WriteString(h.adminPayoutsEmptyText())
+	} else {
+		builder.WriteString(fmt.Sprintf(h.adminPayoutsSummaryTemplate(), len(records), stuck))
+	}
+
+	keyboard := h.api.Messages.NewKeyboardBuilder()
+	for idx, rec := range records {
+		if idx >= adminPayoutsListLimit {
+			break
+		}
+		badge := "⏳"
+		if rec.stuck() {
+			badge = "⚠️"
+		}
+		keyboard.AddRow().
+			AddCallback(truncateLabel(fmt.Sprintf("%s %s · %d", badge, rec.TaskName, rec.Amount), 45), schemes.DEFAULT, fmt.Sprintf("%s:%s:%s", callbackAdminPayoutView, rec.TaskID, rec.VolunteerID))
+	}
+	keyboard.AddRow().
+		AddCallback(h.adminPayoutsRefreshButton(), schemes.DEFAULT, callbackAdminPayouts)
+	keyboard.AddRow().
+		AddCallback(h.adminBackButton(), schemes.DEFAULT, callbackAdminMenu)
+
+	h.renderMenu(ctx, chatID, userID, builder.String(), keyboard)
+}
+
+func (h *MessageHandler) showAdminPayout(ctx context.Context, chatID, userID int64, taskID, volunteerID string, intro ...string) {
+	rec, ok := h.payouts.load(payoutKey(taskID, volunteerID))
+	if !ok {
+		h.showAdminPayouts(ctx, chatID, userID)
+		return
+	}
+
+	lastError := escapeMarkdown(rec.LastError)
+	if lastError == "" {
+		lastError = "—"
+	}
+	nextAttempt := "—"
+	if rec.open() {
+		nextAttempt = formatPayoutTime(rec.NextAttempt)
+	}
+
+	text := fmt.Sprintf(h.adminPayoutDetailTemplate(),
+		escapeMarkdown(rec.TaskName),
+		escapeMarkdown(h.lookupUserName(ctx, rec.VolunteerID)),
+		rec.Amount,
+		h.adminPayoutStateLabel(rec),
+		rec.Attempts,
+		lastError,
+		formatPayoutTime(rec.CreatedAt),
+		nextAttempt,
+	)
+	if len(intro) > 0 && strings.TrimSpace(intro[0]) != "" {
+		text = strings.TrimSpace(intro[0]) + "\n\n" + text
+	}
+
+	keyboard := h.api.Messages.NewKeyboardBuilder()
+	if rec.open() {
+		keyboard.AddRow().
+			AddCallback(h.adminPayoutRetryButton(), schemes.POSITIVE, fmt.Sprintf("%s:%s:%s", callbackAdminPayoutRetry, rec.TaskID, rec.VolunteerID))
+	}
+	if rec.State == payoutCrediting {
+		keyboard.AddRow().
+			AddCallback(h.adminPayoutRevertButton(), schemes.NEGATIVE, fmt.Sprintf("%s:%s:%s", callbackAdminPayoutRevertAsk, rec.TaskID, rec.VolunteerID))
+	}
+	keyboard.AddRow().
+		AddCallback(h.adminPayoutsBackButton(), schemes.DEFAULT, callbackAdminPayouts)
+
+	h.renderMenu(ctx, chatID, userID, text, keyboard)
+}
+
+func (h *MessageHandler) handleAdminPayoutView(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, taskID, volunteerID string) {
+	if callbackQuery.Message == nil {
+		return
+	}
+	h.showAdminPayout(ctx, callbackQuery.Message.Recipient.ChatId, callbackQuery.Callback.User.UserId, taskID, volunteerID)
+}
+
+func (h *MessageHandler) handleAdminPayoutRetry(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, taskID, volunteerID string) {
+	if callbackQuery.Message == nil {
+		return
+	}
+
+	h.auditAdmin(callbackQuery.Callback.User.UserId, adminActionPayoutRetry, payoutKey(taskID, volunteerID), h.adminPayoutAuditSummary(ctx, h.adminAuditPayoutRetry(), taskID, volunteerID), zap.String("task_id", taskID), zap.String("volunteer_id", volunteerID))
+	h.retryPayoutNow(payoutKey(taskID, volunteerID))
+	h.showAdminPayout(ctx, callbackQuery.Message.Recipient.ChatId, callbackQuery.Callback.User.UserId, taskID, volunteerID, h.adminPayoutRetryText())
+}
+
+func (h *MessageHandler) handleAdminPayoutRevertAsk(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, taskID, volunteerID string) {
+	if callbackQuery.Message == nil {
+		return
+	}
+
+	chatID := callbackQuery.Message.Recipient.ChatId
+	userID := callbackQuery.Callback.User.UserId
+
+	rec, ok := h.payouts.load(payoutKey(taskID, volunteerID))
+	if !ok || rec.State != payoutCrediting {
+		h.showAdminPayout(ctx, chatID, userID, taskID, volunteerID)
+		return
+	}
+
+	text := fmt.Sprintf(h.adminPayoutRevertAskTemplate(), escapeMarkdown(rec.TaskName), escapeMarkdown(h.lookupUserName(ctx, rec.VolunteerID)), rec.Amount)
+	keyboard := h.api.Messages.NewKeyboardBuilder()
+	keyboard.AddRow().
+		AddCallback(h.adminPayoutRevertConfirmButton(), schemes.NEGATIVE, fmt.Sprintf("%s:%s:%s", callbackAdminPayoutRevert, taskID, volunteerID))
+	keyboard.AddRow().
+		AddCallback(h.adminPayoutsBackButton(), schemes.DEFAULT, fmt.Sprintf("%s:%s:%s", callbackAdminPayoutView, taskID, volunteerID))
+
+	h.renderMenu(ctx, chatID, userID, text, keyboard)
+}
+
+func (h *MessageHandler) handleAdminPayoutRevert(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, taskID, volunteerID string) {
+	if callbackQuery.Message == nil {
+		return
+	}
+
+	chatID := callbackQuery.Message.Recipient.ChatId
+	userID := callbackQuery.Callback.User.UserId
+
+	if _, err := h.revertPayout(ctx, payoutKey(taskID, volunteerID)); err != nil {
+		h.logger.Error("failed to revert payout", zap.Error(err), zap.String("task_id", taskID), zap.String("volunteer_id", volunteerID))
+		h.showAdminPayout(ctx, chatID, userID, taskID, volunteerID, h.adminPayoutRevertErrorText())
+		return
+	}
+
+	h.auditAdmin(userID, adminActionPayoutRevert, payoutKey(taskID, volunteerID), h.adminPayoutAuditSummary(ctx, h.adminAuditPayoutRevert(), taskID, volunteerID), zap.String("task_id", taskID), zap.String("volunteer_id", volunteerID))
+
+	h.showAdminPayout(ctx, chatID, userID, taskID, volunteerID, h.adminPayoutRevertedText())
+}
+
+func (h *MessageHandler) adminPayoutAuditSummary(ctx context.Context, template, taskID, volunteerID string) string {
+	taskName := taskID
+	if rec, ok := h.payouts.load(payoutKey(taskID, volunteerID)); ok && strings.TrimSpace(rec.TaskName) != "" {
+		taskName = rec.TaskName
+	}
+	return fmt.Sprintf(template, taskName, h.adminTargetName(ctx, volunteerID))
+}
+
+func (h *MessageHandler) adminPayoutStateLabel(rec *payoutRecord) string {
+	var text, fallback string
+	switch {
+	case rec.stuck():
+		text, fallback = h.messages.AdminPayoutStateStuck, "⚠️ зависла"
+	case rec.State == payoutApproving:
+		text, fallback = h.messages.AdminPayoutStateApproving, "⏳ проверяем одобрение"
+	case rec.State == payoutCrediting:
+		text, fallback = h.messages.AdminPayoutStateCrediting, "⏳ начисляем"
+	case rec.State == payoutReverted:
+		text, fallback = h.messages.AdminPayoutStateReverted, "↩️ одобрение откачено"
+	default:
+		text, fallback = h.messages.AdminPayoutStateCredited, "✅ начислена"
+	}
+
+	if text = strings.TrimSpace(text); text != "" {
+		return text
+	}
+	return fallback
+}
+
+func formatPayoutTime(at time.Time) string {
+	if at.IsZero() {
+		return "—"
+	}
+	return at.In(time.Local).Format("02.01.2006 15:04")
+}
+
+func (h *MessageHandler) adminPayoutsTitle() string {
+	if text := strings.TrimSpace(h.messages.AdminPayoutsTitle); text != "" {
+		return text
+	}
+	return "💸 *Выплаты*"
+}
+
+func (h *MessageHandler) adminPayoutsSummaryTemplate() string {
+	if text := strings.TrimSpace(h.messages.AdminPayoutsSummaryTemplate); text != "" {
+		return text
+	}
+	return "Незавершённых: %d, из них зависших: %d"
+}
+
+func (h *MessageHandler) adminPayoutsEmptyText() string {
+	if text := strings.TrimSpace(h.messages.AdminPayoutsEmptyText); text != "" {
+		return text
+	}
+	return "Все выплаты проведены 🎉"
+}
+
+func (h *MessageHandler) adminPayoutsRefreshButton() string {
+	if text := strings.TrimSpace(h.messages.AdminPayoutsRefreshButton); text != "" {
+		return text
+	}
+	return "🔄 Обновить"
+}
+
+func (h *MessageHandler) adminPayoutsBackButton() string {
+	if text := strings.TrimSpace(h.messages.AdminPayoutsBackButton); text != "" {
+		return text
+	}
+	return "⬅️ К выплатам"
+}
+
+func (h *MessageHandler) adminPayoutDetailTemplate() string {
+	if text := strings.TrimSpace(h.messages.AdminPayoutDetailTemplate); text != "" {
+		return text
+	}
+	return "💸 *%s*\n\nВолонтёр: %s\nСумма: %d\nСтатус: %s\nПопыток: %d\nПоследняя ошибка: %s\nСоздана: %s\nСледующая попытка: %s"
+}
+
+func (h *MessageHandler) adminPayoutRetryButton() string {
+	if text := strings.TrimSpace(h.messages.AdminPayoutRetryButton); text != "" {
+		return text
+	}
+	return "🔁 Повторить сейчас"
+}
+
+func (h *MessageHandler) adminPayoutRevertButton() string {
+	if text := strings.TrimSpace(h.messages.AdminPayoutRevertButton); text != "" {
+		return text
+	}
+	return "↩️ Откатить одобрение"
+}
+
+func (h *MessageHandler) adminPayoutRevertAskTemplate() string {
+	if text := strings.TrimSpace(h.messages.AdminPayoutRevertAskTemplate); text != "" {
+		return text
+	}
+	return "Откатить одобрение задачи «%s» для %s?\n\nОтклик будет отклонён, и %d монет начислены не будут."
+}
+
+func (h *MessageHandler) adminPayoutRevertConfirmButton() string {
+	if text := strings.TrimSpace(h.messages.AdminPayoutRevertConfirmButton); text != "" {
+		return text
+	}
+	return "Да, откатить"
+}
+
+func (h *MessageHandler) adminPayoutRetryText() string {
+	if text := strings.TrimSpace(h.messages.AdminPayoutRetryText); text != "" {
+		return text
+	}
+	return "🔁 Повторная попытка запущена."
+}
+
+func (h *MessageHandler) adminPayoutRevertedText() string {
+	if text := strings.TrimSpace(h.messages.AdminPayoutRevertedText); text != "" {
+		return text
+	}
+	return "↩️ Одобрение откачено."
+}
+
+func (h *MessageHandler) adminPayoutRevertErrorText() string {
+	if text := strings.TrimSpace(h.messages.AdminPayoutRevertErrorText); text != "" {
+		return text
+	}
+	return "Не удалось откатить одобрение. Попробуй ещё раз."
+}
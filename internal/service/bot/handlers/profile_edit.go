@@ -0,0 +1,495 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	userpb "DobrikaDev/max-bot/internal/generated/userpb"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
+	"go.uber.org/zap"
+)
+
+// startProfileEdit walks the volunteer through the registration steps again,
+// prefilled with the stored profile. Every step can be kept as is, and the
+// changes are only saved after the review step.
+func (h *MessageHandler) startProfileEdit(ctx context.Context, chatID, userID int64, messageID string) {
+	backKeyboard := h.singleButtonKeyboard(h.messages.ProfileBackButton, callbackMainMenuProfile)
+
+	if h.user == nil {
+		h.renderMenu(ctx, chatID, userID, h.profileEditErrorText(), backKeyboard)
+		return
+	}
+
+	maxID := fmt.Sprintf("%d", userID)
+	resp, err := h.user.GetUserByMaxID(ctx, &userpb.GetUserByMaxIDRequest{MaxId: maxID})
+	if err != nil || resp.GetError() != nil || resp.GetUser() == nil {
+		if err == nil && resp.GetError() != nil {
+			err = fmt.Errorf("user service error: %s", resp.GetError().GetMessage())
+		}
+		h.logger.Error("failed to load profile for editing", zap.Error(err), zap.Int64("user_id", userID))
+		h.renderMenu(ctx, chatID, userID, h.profileEditErrorText(), backKeyboard)
+		return
+	}
+
+	user := resp.GetUser()
+	session := &registrationSession{
+		UserID:    userID,
+		ChatID:    chatID,
+		UserName:  user.GetName(),
+		MaxUserID: maxID,
+		Current:   registrationStepAge,
+		MessageID: messageID,
+		Editing:   true,
+		Original: &profileSnapshot{
+			Age:         user.GetAge(),
+			Sex:         user.GetSex(),
+			Geolocation: strings.TrimSpace(user.GetGeolocation()),
+			About:       strings.TrimSpace(user.GetAbout()),
+		},
+	}
+	h.restoreProfileAge(session)
+	h.restoreProfileSex(session)
+	h.restoreProfileLocation(session)
+	h.restoreProfileAbout(session)
+	h.sessions.upsert(session)
+
+	if messageID != "" {
+		h.menus.delete(chatID)
+	}
+
+	h.promptForAge(ctx, session)
+}
+
+func (h *MessageHandler) handleProfileEditKeep(ctx context.Context, update *schemes.MessageCallbackUpdate) {
+	session, ok := h.profileEditSession(update)
+	if !ok {
+		return
+	}
+
+	switch session.Current {
+	case registrationStepAge:
+		h.restoreProfileAge(session)
+		session.Current = registrationStepSex
+		h.sessions.upsert(session)
+		h.promptForSex(ctx, session)
+	case registrationStepSex:
+		h.restoreProfileSex(session)
+		session.Current = registrationStepLocation
+		h.sessions.upsert(session)
+		h.promptForLocation(ctx, session)
+	case registrationStepLocation:
+		h.restoreProfileLocation(session)
+		session.Current = registrationStepAbout
+		h.sessions.upsert(session)
+		h.promptForAbout(ctx, session)
+	case registrationStepAbout:
+		h.restoreProfileAbout(session)
+		h.finalizeRegistration(ctx, session)
+	default:
+		h.logger.Debug("profile edit keep in unexpected step", zap.Int("step", int(session.Current)))
+	}
+}
+
+func (h *MessageHandler) handleProfileEditSave(ctx context.Context, update *schemes.MessageCallbackUpdate) {
+	session, ok := h.profileEditSession(update)
+	if !ok || session.Current != registrationStepReview {
+		return
+	}
+
+	changes := h.profileChanges(session)
+	if len(changes) == 0 {
+		h.finishProfileEdit(ctx, session, h.profileEditNoChangesText())
+		return
+	}
+
+	if err := h.updateUserProfile(ctx, session); err != nil {
+		h.logger.Error("failed to update profile", zap.Error(err), zap.Int64("user_id", session.UserID))
+		h.showProfileEditReview(ctx, session, h.profileEditErrorText())
+		return
+	}
+
+	h.finishProfileEdit(ctx, session, h.profileEditSuccessText())
+}
+
+func (h *MessageHandler) handleProfileEditCancel(ctx context.Context, update *schemes.MessageCallbackUpdate) {
+	session, ok := h.profileEditSession(update)
+	if !ok {
+		if update.Message != nil {
+			h.showProfile(ctx, update.Message.Recipient.ChatId, update.Callback.User.UserId)
+		}
+		return
+	}
+
+	h.finishProfileEdit(ctx, session, "")
+}
+
+func (h *MessageHandler) profileEditSession(update *schemes.MessageCallbackUpdate) (*registrationSession, bool) {
+	session, ok := h.sessions.get(update.Callback.User.UserId)
+	if !ok || !session.Editing || session.Original == nil {
+		h.logger.Debug("profile edit callback without active edit session")
+		return nil, false
+	}
+
+	if update.Message != nil && update.Message.Body.Mid != "" {
+		session.MessageID = update.Message.Body.Mid
+	}
+
+	return session, true
+}
+
+func (h *MessageHandler) finishProfileEdit(ctx context.Context, session *registrationSession, intro string) {
+	h.sessions.delete(session.UserID)
+	if session.MessageID != "" {
+		h.menus.set(session.ChatID, session.MessageID, session.UserID)
+	}
+	h.showProfile(ctx, session.ChatID, session.UserID, intro)
+}
+
+func (h *MessageHandler) showProfileEditReview(ctx context.Context, session *registrationSession, intro ...string) {
+	var builder strings.Builder
+	if len(intro) > 0 && strings.TrimSpace(intro[0]) != "" {
+		builder.WriteString(strings.TrimSpace(intro[0]))
+		builder.WriteString("\n\n")
+	}
+
+	keyboard := h.api.Messages.NewKeyboardBuilder()
+
+	changes := h.profileChanges(session)
+	if len(changes) == 0 {
+		builder.WriteString(h.profileEditNoChangesText())
+	} else {
+		builder.WriteString(h.profileEditReviewTitle())
+		builder.WriteString("\n\n")
+		for _, change := range changes {
+			builder.WriteString(change)
+			builder.WriteString("\n")
+		}
+
+		keyboard.AddRow().
+			AddCallback(h.profileEditSaveButton(), schemes.POSITIVE, callbackProfileEditSave)
+	}
+
+	keyboard.AddRow().
+		AddCallback(h.profileEditRestartButton(), schemes.DEFAULT, callbackProfileEdit)
+	keyboard.AddRow().
+		AddCallback(h.profileEditCancelButton(), schemes.NEGATIVE, callbackProfileEditCancel)
+
+	h.updateSessionMessage(ctx, session, builder.String(), keyboard)
+}
+
+// profileChanges lists the edited fields as "field: old → new" lines. It is
+// built from profileUpdate, so the review shows exactly what is saved.
+func (h *MessageHandler) profileChanges(session *registrationSession) []string {
+	original := session.Original
+	update := profileUpdate(session)
+	template := h.profileEditChangeTemplate()
+
+	var changes []string
+	if update.Age != 0 {
+		changes = append(changes, fmt.Sprintf(template, h.profileEditAgeField(), h.profileAgeLabel(original.Age), h.profileAgeLabel(update.Age)))
+	}
+	if update.Sex != userpb.Sex_SEX_UNSPECIFIED {
+		changes = append(changes, fmt.Sprintf(template, h.profileEditSexField(), h.profileSexLabel(original.Sex), h.profileSexLabel(update.Sex)))
+	}
+	if update.Geolocation != "" {
+		changes = append(changes, fmt.Sprintf(template, h.profileEditLocationField(), h.profileLocationLabel(original.Geolocation), h.profileLocationLabel(update.Geolocation)))
+	}
+	if update.About != "" {
+		changes = append(changes, fmt.Sprintf(template, h.profileEditAboutField(), profileValueOrDash(original.About), profileValueOrDash(update.About)))
+	}
+
+	return changes
+}
+
+// profileUpdate holds the changed fields of an edit. The user service leaves
+// empty fields untouched and cannot clear a value, so a field edited to
+// nothing is left out rather than shown as removed.
+func profileUpdate(session *registrationSession) *userpb.User {
+	original := session.Original
+	user := &userpb.User{MaxId: session.MaxUserID}
+
+	if session.Age > 0 && session.Age != original.Age {
+		user.Age = session.Age
+	}
+	if session.Sex != userpb.Sex_SEX_UNSPECIFIED && session.Sex != original.Sex {
+		user.Sex = session.Sex
+	}
+	if geo := session.geolocationAsString(); geo != "" && !sameLocation(geo, original.Geolocation) {
+		user.Geolocation = geo
+	}
+	if about := strings.TrimSpace(session.About); about != "" && about != original.About {
+		user.About = about
+	}
+
+	return user
+}
+
+// updateUserProfile sends the fields profileUpdate reports as changed.
+func (h *MessageHandler) updateUserProfile(ctx context.Context, session *registrationSession) error {
+	if h.user == nil {
+		return fmt.Errorf("user service client is not configured")
+	}
+
+	user := profileUpdate(session)
+	resp, err := h.user.UpdateUser(ctx, &userpb.UpdateUserRequest{User: user})
+	if err != nil {
+		return fmt.Errorf("update user request failed: %w", err)
+	}
+	if resp.GetError() != nil {
+		return fmt.Errorf("user service responded with error: %s", resp.GetError().GetMessage())
+	}
+
+	h.logger.Info("user profile updated", zap.String("max_id", session.MaxUserID))
+	return nil
+}
+
+func (h *MessageHandler) restoreProfileAge(session *registrationSession) {
+	session.Age = session.Original.Age
+}
+
+func (h *MessageHandler) restoreProfileSex(session *registrationSession) {
+	session.Sex = session.Original.Sex
+}
+
+func (h *MessageHandler) restoreProfileLocation(session *registrationSession) {
+	session.Latitude, session.Longitude, session.GeoLabel = 0, 0, ""
+
+	geo := session.Original.Geolocation
+	if lat, lon, ok := parseCoordinates(geo); ok {
+		session.Latitude, session.Longitude = lat, lon
+		return
+	}
+	session.GeoLabel = geo
+}
+
+// restoreProfileAbout selects the about options that match the stored text.
+// A free-form text that does not match the options is kept as is.
+func (h *MessageHandler) restoreProfileAbout(session *registrationSession) {
+	session.About = session.Original.About
+	session.Interests = make(map[int]bool)
+
+	if session.About == "" {
+		return
+	}
+
+	selected := make(map[int]bool)
+	for _, part := range strings.Split(session.About, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		matched := false
+		for idx, option := range h.messages.RegistrationAboutOptions {
+			if strings.TrimSpace(option) == part {
+				selected[idx] = true
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return
+		}
+	}
+
+	session.Interests = selected
+}
+
+func (h *MessageHandler) withProfileEditKeep(session *registrationSession, keyboard *maxbot.Keyboard) *maxbot.Keyboard {
+	if !session.Editing {
+		return keyboard
+	}
+
+	if keyboard == nil {
+		keyboard = h.api.Messages.NewKeyboardBuilder()
+	}
+	keyboard.AddRow().
+		AddCallback(h.profileEditKeepButton(), schemes.DEFAULT, callbackProfileEditKeep)
+	keyboard.AddRow().
+		AddCallback(h.profileEditCancelButton(), schemes.NEGATIVE, callbackProfileEditCancel)
+
+	return keyboard
+}
+
+func (h *MessageHandler) profileEditStepText(session *registrationSession, prompt, current string) string {
+	var builder strings.Builder
+	if session.Current == registrationStepAge {
+		if intro := strings.TrimSpace(h.messages.ProfileEditText); intro != "" {
+			builder.WriteString(intro)
+			builder.WriteString("\n\n")
+		}
+	}
+	builder.WriteString(prompt)
+	builder.WriteString("\n\n")
+	builder.WriteString(fmt.Sprintf(h.profileEditCurrentTemplate(), current))
+	return builder.String()
+}
+
+func parseCoordinates(value string) (float64, float64, bool) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return lat, lon, true
+}
+
+// sameLocation compares coordinates numerically, since the stored value may
+// use a different precision than geolocationAsString.
+func sameLocation(a, b string) bool {
+	latA, lonA, okA := parseCoordinates(a)
+	latB, lonB, okB := parseCoordinates(b)
+	if okA && okB {
+		const epsilon = 1e-6
+		return math.Abs(latA-latB) < epsilon && math.Abs(lonA-lonB) < epsilon
+	}
+	return strings.TrimSpace(a) == strings.TrimSpace(b)
+}
+
+func (h *MessageHandler) profileAgeLabel(age int32) string {
+	if age <= 0 {
+		return "—"
+	}
+	return strconv.Itoa(int(age))
+}
+
+func (h *MessageHandler) profileSexLabel(sex userpb.Sex) string {
+	switch sex {
+	case userpb.Sex_SEX_MALE:
+		return h.messages.RegistrationSexMaleText
+	case userpb.Sex_SEX_FEMALE:
+		return h.messages.RegistrationSexFemaleText
+	default:
+		return "—"
+	}
+}
+
+func (h *MessageHandler) profileLocationLabel(geo string) string {
+	if _, _, ok := parseCoordinates(geo); ok {
+		if text := strings.TrimSpace(h.messages.ProfileEditGeoLabel); text != "" {
+			return text
+		}
+		return "геопозиция"
+	}
+	return profileValueOrDash(geo)
+}
+
+// profileValueOrDash shows a value the user typed in, or a dash for none.
+func profileValueOrDash(value string) string {
+	if value = strings.TrimSpace(value); value == "" {
+		return "—"
+	}
+	return escapeMarkdown(value)
+}
+
+func (h *MessageHandler) profileEditCurrentTemplate() string {
+	if text := strings.TrimSpace(h.messages.ProfileEditCurrentTemplate); text != "" {
+		return text
+	}
+	return "Сейчас: %s"
+}
+
+func (h *MessageHandler) profileEditChangeTemplate() string {
+	if text := strings.TrimSpace(h.messages.ProfileEditChangeTemplate); text != "" {
+		return text
+	}
+	return "*%s:* %s → %s"
+}
+
+func (h *MessageHandler) profileEditAgeField() string {
+	if text := strings.TrimSpace(h.messages.ProfileEditAgeField); text != "" {
+		return text
+	}
+	return "Возраст"
+}
+
+func (h *MessageHandler) profileEditSexField() string {
+	if text := strings.TrimSpace(h.messages.ProfileEditSexField); text != "" {
+		return text
+	}
+	return "Пол"
+}
+
+func (h *MessageHandler) profileEditLocationField() string {
+	if text := strings.TrimSpace(h.messages.ProfileEditLocationField); text != "" {
+		return text
+	}
+	return "Локация"
+}
+
+func (h *MessageHandler) profileEditAboutField() string {
+	if text := strings.TrimSpace(h.messages.ProfileEditAboutField); text != "" {
+		return text
+	}
+	return "Чем помогаю"
+}
+
+func (h *MessageHandler) profileEditReviewTitle() string {
+	if text := strings.TrimSpace(h.messages.ProfileEditReviewTitle); text != "" {
+		return text
+	}
+	return "Проверьте изменения:"
+}
+
+func (h *MessageHandler) profileEditKeepButton() string {
+	if text := strings.TrimSpace(h.messages.ProfileEditKeepButton); text != "" {
+		return text
+	}
+	return "Оставить как есть"
+}
+
+func (h *MessageHandler) profileEditSaveButton() string {
+	if text := strings.TrimSpace(h.messages.ProfileEditSaveButton); text != "" {
+		return text
+	}
+	return "Сохранить"
+}
+
+func (h *MessageHandler) profileEditRestartButton() string {
+	if text := strings.TrimSpace(h.messages.ProfileEditRestartButton); text != "" {
+		return text
+	}
+	return "Изменить заново"
+}
+
+func (h *MessageHandler) profileEditCancelButton() string {
+	if text := strings.TrimSpace(h.messages.ProfileEditCancelButton); text != "" {
+		return text
+	}
+	return "Отмена"
+}
+
+func (h *MessageHandler) profileEditNoChangesText() string {
+	if text := strings.TrimSpace(h.messages.ProfileEditNoChangesText); text != "" {
+		return text
+	}
+	return "Изменений нет."
+}
+
+func (h *MessageHandler) profileEditSuccessText() string {
+	if text := strings.TrimSpace(h.messages.ProfileEditSuccessText); text != "" {
+		return text
+	}
+	return "Профиль обновлён."
+}
+
+func (h *MessageHandler) profileEditErrorText() string {
+	if text := strings.TrimSpace(h.messages.ProfileEditErrorText); text != "" {
+		return text
+	}
+	return "Не удалось обновить профиль. Попробуйте позже."
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"testing"
+
+	userpb "DobrikaDev/max-bot/internal/generated/userpb"
+	"DobrikaDev/max-bot/internal/locales"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestProfileUpdateMatchesReview(t *testing.T) {
+	original := &profileSnapshot{Age: 30, Sex: userpb.Sex_SEX_FEMALE, Geolocation: "Казань", About: "Выгул собак"}
+
+	tests := []struct {
+		name    string
+		edit    func(s *registrationSession)
+		want    *userpb.User
+		changes int
+	}{
+		{"nothing", func(*registrationSession) {}, &userpb.User{MaxId: "1"}, 0},
+		{"age", func(s *registrationSession) { s.Age = 31 }, &userpb.User{MaxId: "1", Age: 31}, 1},
+		{"city", func(s *registrationSession) { s.GeoLabel = "Уфа" }, &userpb.User{MaxId: "1", Geolocation: "Уфа"}, 1},
+		{"coordinates", func(s *registrationSession) { s.GeoLabel, s.Latitude, s.Longitude = "", 55.75, 37.62 }, &userpb.User{MaxId: "1", Geolocation: "55.750000,37.620000"}, 1},
+		{"cleared city", func(s *registrationSession) { s.GeoLabel = "" }, &userpb.User{MaxId: "1"}, 0},
+		{"cleared about", func(s *registrationSession) { s.About = " " }, &userpb.User{MaxId: "1"}, 0},
+		{"about and sex", func(s *registrationSession) { s.About, s.Sex = "Покупки", userpb.Sex_SEX_MALE }, &userpb.User{MaxId: "1", Sex: userpb.Sex_SEX_MALE, About: "Покупки"}, 2},
+	}
+
+	h := &MessageHandler{messages: locales.For(locales.DefaultLanguage)}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := &registrationSession{MaxUserID: "1", Editing: true, Original: original}
+			h.restoreProfileAge(session)
+			h.restoreProfileSex(session)
+			h.restoreProfileLocation(session)
+			session.About = original.About
+			tt.edit(session)
+
+			if got := profileUpdate(session); !proto.Equal(got, tt.want) {
+				t.Fatalf("update = %v, want %v", got, tt.want)
+			}
+			if got := h.profileChanges(session); len(got) != tt.changes {
+				t.Fatalf("review lists %q, want %d changes", got, tt.changes)
+			}
+		})
+	}
+}
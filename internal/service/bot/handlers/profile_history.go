@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	userpb "DobrikaDev/max-bot/internal/generated/userpb"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
+	"go.uber.org/zap"
+)
+
+const (
+	profileHistoryPageSize      = 5
+	profileHistoryFetchSize     = 100
+	profileHistoryMaxOperations = 1000
+)
+
+var russianMonths = [...]string{
+	"январь", "февраль", "март", "апрель", "май", "июнь",
+	"июль", "август", "сентябрь", "октябрь", "ноябрь", "декабрь",
+}
+
+// ledgerEntry is a balance operation together with the balance right after it.
+type ledgerEntry struct {
+	operation *userpb.BalanceOperation
+	amount    int32
+	balance   int32
+}
+
+// ledger is the whole coin history of a user, newest operation first.
+type ledger struct {
+	entries   []ledgerEntry
+	balance   int32
+	truncated bool
+}
+
+func (h *MessageHandler) handleProfileHistoryPage(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, payload string) {
+	h.answerCallback(ctx, callbackQuery.Callback.CallbackID)
+
+	if callbackQuery.Message == nil {
+		return
+	}
+
+	page, err := strconv.Atoi(strings.TrimSpace(payload))
+	if err != nil {
+		h.logger.Warn("failed to parse profile history page", zap.Error(err), zap.String("payload", payload))
+		page = 0
+	}
+	if page < 0 {
+		page = 0
+	}
+
+	h.showProfileHistoryPage(ctx, callbackQuery.Message.Recipient.ChatId, callbackQuery.Callback.User.UserId, page)
+}
+
+func (h *MessageHandler) showProfileHistory(ctx context.Context, chatID, userID int64) {
+	h.showProfileHistoryPage(ctx, chatID, userID, 0)
+}
+
+func (h *MessageHandler) showProfileHistoryPage(ctx context.Context, chatID, userID int64, page int) {
+	text, keyboard := h.buildProfileHistoryView(ctx, userID, page)
+	h.renderMenu(ctx, chatID, userID, text, keyboard)
+}
+
+func (h *MessageHandler) buildProfileHistoryView(ctx context.Context, userID int64, page int) (string, *maxbot.Keyboard) {
+	backKeyboard := h.singleButtonKeyboard(h.messages.ProfileBackButton, callbackProfileBack)
+
+	if h.user == nil {
+		return h.profileHistoryErrorText(), backKeyboard
+	}
+
+	history, err := h.fetchLedger(ctx, fmt.Sprintf("%d", userID))
+	if err != nil {
+		h.logger.Error("failed to fetch balance operations", zap.Error(err), zap.Int64("user_id", userID))
+		return h.profileHistoryErrorText(), backKeyboard
+	}
+
+	var builder strings.Builder
+	builder.WriteString(h.profileHistoryTitle())
+	builder.WriteString("\n\n")
+	builder.WriteString(fmt.Sprintf(h.profileHistoryBalanceTemplate(), history.balance))
+	builder.WriteString("\n")
+
+	if len(history.entries) == 0 {
+		builder.WriteString("\n")
+		builder.WriteString(h.messages.ProfileHistoryText)
+		return builder.String(), backKeyboard
+	}
+
+	now := time.Now()
+	income, spent := history.monthTotals(now)
+	builder.WriteString(fmt.Sprintf(h.profileHistoryMonthSummaryTemplate(), russianMonths[now.Month()-1], income, spent))
+	builder.WriteString("\n\n")
+
+	total := len(history.entries)
+	totalPages := (total + profileHistoryPageSize - 1) / profileHistoryPageSize
+	if page >= totalPages {
+		page = totalPages - 1
+	}
+
+	start := page * profileHistoryPageSize
+	end := start + profileHistoryPageSize
+	if end > total {
+		end = total
+	}
+
+	for _, entry := range history.entries[start:end] {
+		builder.WriteString(h.profileHistoryItemText(entry))
+		builder.WriteString("\n\n")
+	}
+
+	if totalPages > 1 {
+		builder.WriteString(h.pageFooter(h.messages.ProfileHistoryPageFooter, page, totalPages))
+		builder.WriteString("\n")
+	}
+	if history.truncated {
+		builder.WriteString(h.profileHistoryTruncatedText())
+		builder.WriteString("\n")
+	}
+
+	keyboard := h.api.Messages.NewKeyboardBuilder()
+
+	hasPrev := page > 0
+	hasNext := end < total
+	if hasPrev || hasNext {
+		prevLabel := strings.TrimSpace(h.messages.ProfileHistoryPrevButton)
+		if prevLabel == "" {
+			prevLabel = "⬅️ Назад"
+		}
+		nextLabel := strings.TrimSpace(h.messages.ProfileHistoryNextButton)
+		if nextLabel == "" {
+			nextLabel = "➡️ Далее"
+		}
+		row := keyboard.AddRow()
+		if hasPrev {
+			row.AddCallback(prevLabel, schemes.DEFAULT, fmt.Sprintf("%s:%d", callbackProfileHistoryPage, page-1))
+		}
+		if hasNext {
+			row.AddCallback(nextLabel, schemes.DEFAULT, fmt.Sprintf("%s:%d", callbackProfileHistoryPage, page+1))
+		}
+	}
+
+	keyboard.AddRow().
+		AddCallback(h.messages.ProfileBackButton, schemes.DEFAULT, callbackProfileBack)
+
+	return builder.String(), keyboard
+}
+
+// fetchLedger loads the operations of a user and computes the balance after
+// each of them. The running total is anchored on the current balance and
+// walked backwards, so it stays correct even when the oldest operations are
+// cut off by profileHistoryMaxOperations.
+func (h *MessageHandler) fetchLedger(ctx context.Context, maxID string) (*ledger, error) {
+	var (
+		operations []*userpb.BalanceOperation
+		total      int
+	)
+
+	for offset := 0; offset < profileHistoryMaxOperations; offset += profileHistoryFetchSize {
+		resp, err := h.user.GetBalanceOperations(ctx, &userpb.GetBalanceOperationsRequest{
+			MaxId:  maxID,
+			Limit:  profileHistoryFetchSize,
+			Offset: int32(offset),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if svcErr := resp.GetError(); svcErr != nil {
+			if svcErr.GetCode() == userpb.ErrorCode_ERROR_CODE_NOT_FOUND {
+				break
+			}
+			return nil, fmt.Errorf("user service error: %s", svcErr.GetMessage())
+		}
+
+		operations = append(operations, resp.GetOperations()...)
+		total = int(resp.GetTotal())
+
+		if len(resp.GetOperations()) < profileHistoryFetchSize || len(operations) >= total {
+			break
+		}
+	}
+
+	sort.SliceStable(operations, func(i, j int) bool {
+		return operations[i].GetCreatedAt() > operations[j].GetCreatedAt()
+	})
+
+	result := &ledger{
+		entries:   make([]ledgerEntry, len(operations)),
+		truncated: total > len(operations),
+	}
+
+	var sum int32
+	for i, op := range operations {
+		amount := signedOperationAmount(op)
+		result.entries[i] = ledgerEntry{operation: op, amount: amount}
+		sum += amount
+	}
+
+	result.balance = sum
+	if balanceResp, err := h.user.GetBalance(ctx, &userpb.GetBalanceRequest{MaxId: maxID}); err != nil {
+		h.logger.Warn("failed to fetch balance for history", zap.Error(err))
+	} else if balanceResp.GetError() != nil {
+		h.logger.Warn("balance response error for history", zap.String("message", balanceResp.GetError().GetMessage()))
+	} else {
+		result.balance = balanceResp.GetBalance()
+	}
+
+	running := result.balance
+	for i := range result.entries {
+		result.entries[i].balance = running
+		running -= result.entries[i].amount
+	}
+
+	return result, nil
+}
+
+// monthTotals sums the deposits and withdrawals made in the month of now.
+func (l *ledger) monthTotals(now time.Time) (income, spent int32) {
+	year, month, _ := now.Date()
+	for _, entry := range l.entries {
+		at := time.Unix(int64(entry.operation.GetCreatedAt()), 0).In(now.Location())
+		if y, m, _ := at.Date(); y != year || m != month {
+			continue
+		}
+		if entry.amount >= 0 {
+			income += entry.amount
+		} else {
+			spent -= entry.amount
+		}
+	}
+	return income, spent
+}
+
+func signedOperationAmount(op *userpb.BalanceOperation) int32 {
+	amount := op.GetAmount()
+	if amount < 0 {
+		amount = -amount
+	}
+
+	switch op.GetType() {
+	case userpb.BalanceOperationType_BALANCE_OPERATION_TYPE_WITHDRAW:
+		return -amount
+	case userpb.BalanceOperationType_BALANCE_OPERATION_TYPE_DEPOSIT:
+		return amount
+	default:
+		return op.GetAmount()
+	}
+}
+
+func (h *MessageHandler) profileHistoryItemText(entry ledgerEntry) string {
+	template := strings.TrimSpace(h.messages.ProfileHistoryItemTemplate)
+	if template == "" {
+		template = "%s *%s* · %s\n%s\n%s · баланс: %d"
+	}
+
+	icon := "➕"
+	label := h.profileHistoryDepositLabel()
+	amount := fmt.Sprintf("+%d", entry.amount)
+	if entry.amount < 0 {
+		icon = "➖"
+		label = h.profileHistoryWithdrawLabel()
+		amount = fmt.Sprintf("−%d", -entry.amount)
+	}
+
+	description := escapeMarkdown(strings.TrimSpace(entry.operation.GetDescription()))
+	if description == "" {
+		description = "—"
+	}
+
+	date := time.Unix(int64(entry.operation.GetCreatedAt()), 0).In(time.Local).Format("02.01.2006 15:04")
+
+	return fmt.Sprintf(template, icon, amount, label, description, date, entry.balance)
+}
+
+func (h *MessageHandler) profileHistoryTitle() string {
+	if text := strings.TrimSpace(h.messages.ProfileHistoryTitle); text != "" {
+		return text
+	}
+	return "📜 *История добриков*"
+}
+
+func (h *MessageHandler) profileHistoryBalanceTemplate() string {
+	if text := strings.TrimSpace(h.messages.ProfileHistoryBalanceTemplate); text != "" {
+		return text
+	}
+	return "Баланс: *%d* добриков"
+}
+
+func (h *MessageHandler) profileHistoryMonthSummaryTemplate() string {
+	if text := strings.TrimSpace(h.messages.ProfileHistoryMonthSummaryTemplate); text != "" {
+		return text
+	}
+	return "За %s: +%d / −%d"
+}
+
+func (h *MessageHandler) profileHistoryDepositLabel() string {
+	if text := strings.TrimSpace(h.messages.ProfileHistoryDepositLabel); text != "" {
+		return text
+	}
+	return "начисление"
+}
+
+func (h *MessageHandler) profileHistoryWithdrawLabel() string {
+	if text := strings.TrimSpace(h.messages.ProfileHistoryWithdrawLabel); text != "" {
+		return text
+	}
+	return "списание"
+}
+
+func (h *MessageHandler) profileHistoryTruncatedText() string {
+	if text := strings.TrimSpace(h.messages.ProfileHistoryTruncatedText); text != "" {
+		return text
+	}
+	return "Показаны последние операции."
+}
+
+func (h *MessageHandler) profileHistoryErrorText() string {
+	if text := strings.TrimSpace(h.messages.ProfileHistoryErrorText); text != "" {
+		return text
+	}
+	return "Не удалось загрузить историю. Попробуйте позже."
+}
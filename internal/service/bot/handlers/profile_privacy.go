@@ -0,0 +1,452 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	customerpb "DobrikaDev/max-bot/internal/generated/customerpb"
+	taskpb "DobrikaDev/max-bot/internal/generated/taskpb"
+	userpb "DobrikaDev/max-bot/internal/generated/userpb"
+	"DobrikaDev/max-bot/internal/outbox"
+
+	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	dataExportTaskPageSize = 100
+	dataExportMaxTasks     = 5000
+	dataExportFileName     = "dobrika-export.json"
+)
+
+// dataExport is the document sent to a user who asks for a copy of their
+// data. Service records are embedded as protojson so new fields show up in
+// the export without touching this file.
+type dataExport struct {
+	GeneratedAt       string                 `json:"generated_at"`
+	MaxID             string                 `json:"max_id"`
+	User              json.RawMessage        `json:"user"`
+	Balance           int32                  `json:"balance"`
+	BalanceOperations []json.RawMessage      `json:"balance_operations"`
+	Feedbacks         []json.RawMessage      `json:"feedbacks"`
+	TaskAssignments   []dataExportAssignment `json:"task_assignments"`
+	Preferences       dataExportPreferences  `json:"preferences"`
+}
+
+// dataExportPreferences are the settings the bot itself keeps for the user.
+type dataExportPreferences struct {
+	Language            string             `json:"language,omitempty"`
+	ReportedLocale      string             `json:"reported_locale,omitempty"`
+	Notifications       *notificationPrefs `json:"notifications,omitempty"`
+	QueuedNotifications []taskEvent        `json:"queued_notifications,omitempty"`
+}
+
+type dataExportAssignment struct {
+	TaskID     string `json:"task_id"`
+	TaskName   string `json:"task_name"`
+	CustomerID string `json:"customer_id"`
+	Status     string `json:"status"`
+}
+
+type uploadEndpoint struct {
+	URL   string `json:"url"`
+	Token string `json:"token"`
+}
+
+var exportMarshaler = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+
+func (h *MessageHandler) showProfileData(ctx context.Context, chatID, userID int64, intro ...string) {
+	text := h.messages.ProfileDataText
+	if len(intro) > 0 && strings.TrimSpace(intro[0]) != "" {
+		text = strings.TrimSpace(intro[0]) + "\n\n" + text
+	}
+
+	keyboard := h.api.Messages.NewKeyboardBuilder()
+	keyboard.AddRow().
+		AddCallback(h.messages.ProfileExportButton, schemes.DEFAULT, callbackProfileExport)
+	keyboard.AddRow().
+		AddCallback(h.messages.ProfileDeleteButton, schemes.NEGATIVE, callbackProfileDeleteAsk)
+	keyboard.AddRow().
+		AddCallback(h.messages.ProfileBackButton, schemes.DEFAULT, callbackMainMenuProfile)
+
+	h.renderMenu(ctx, chatID, userID, text, keyboard)
+}
+
+func (h *MessageHandler) handleProfileExport(ctx context.Context, chatID, userID int64) {
+	if h.user == nil {
+		h.showProfileData(ctx, chatID, userID, h.messages.ProfileExportErrorText)
+		return
+	}
+
+	data, err := h.buildDataExport(ctx, userID)
+	if err != nil {
+		h.logger.Error("failed to build data export", zap.Error(err), zap.Int64("user_id", userID))
+		h.showProfileData(ctx, chatID, userID, h.messages.ProfileExportErrorText)
+		return
+	}
+
+	token, err := h.uploadFile(ctx, dataExportFileName, data)
+	if err != nil {
+		h.logger.Error("failed to upload data export", zap.Error(err), zap.Int64("user_id", userID))
+		h.showProfileData(ctx, chatID, userID, h.messages.ProfileExportErrorText)
+		return
+	}
+
+	body := h.buildMessageBody(fmt.Sprintf(h.messages.ProfileExportCaption, time.Now().Format("02.01.2006 15:04")), nil)
+	body.Attachments = []interface{}{schemes.NewFileAttachmentRequest(schemes.UploadedInfo{Token: token})}
+
+	if _, err := h.sendMessage(ctx, chatID, userID, body, ""); err != nil && !errors.Is(err, outbox.ErrDeferred) {
+		h.logger.Error("failed to send data export", zap.Error(err), zap.Int64("user_id", userID))
+		h.showProfileData(ctx, chatID, userID, h.messages.ProfileExportErrorText)
+		return
+	}
+
+	// The file lands below the menu, so the menu is re-sent under it rather
+	// than edited in place. A deferred file still goes out first, as the
+	// outbox keeps the chat's messages in order.
+	h.menus.delete(chatID)
+	h.showProfileData(ctx, chatID, userID, h.messages.ProfileExportSentText)
+}
+
+// buildDataExport collects everything the services and the bot hold about a
+// user. The user record and balance are required; feedback and task lookups
+// are best effort and only logged on failure, so a flaky task service does
+// not block the export.
+func (h *MessageHandler) buildDataExport(ctx context.Context, userID int64) ([]byte, error) {
+	maxID := fmt.Sprintf("%d", userID)
+	userResp, err := h.user.GetUserByMaxID(ctx, &userpb.GetUserByMaxIDRequest{MaxId: maxID})
+	if err != nil {
+		return nil, err
+	}
+	if svcErr := userResp.GetError(); svcErr != nil {
+		return nil, fmt.Errorf("user service error: %s", svcErr.GetMessage())
+	}
+
+	export := dataExport{
+		GeneratedAt:       time.Now().UTC().Format(time.RFC3339),
+		MaxID:             maxID,
+		BalanceOperations: []json.RawMessage{},
+		Feedbacks:         []json.RawMessage{},
+		TaskAssignments:   []dataExportAssignment{},
+	}
+
+	if export.User, err = exportMarshaler.Marshal(userResp.GetUser()); err != nil {
+		return nil, err
+	}
+
+	history, err := h.fetchLedger(ctx, maxID)
+	if err != nil {
+		return nil, err
+	}
+	export.Balance = history.balance
+	for _, entry := range history.entries {
+		raw, err := exportMarshaler.Marshal(entry.operation)
+		if err != nil {
+			return nil, err
+		}
+		export.BalanceOperations = append(export.BalanceOperations, raw)
+	}
+
+	feedbacks, err := h.fetchUserFeedbacks(ctx, maxID)
+	if err != nil {
+		h.logger.Warn("failed to fetch feedbacks for export", zap.Error(err), zap.String("max_id", maxID))
+	}
+	for _, feedback := range feedbacks {
+		raw, err := exportMarshaler.Marshal(feedback)
+		if err != nil {
+			return nil, err
+		}
+		export.Feedbacks = append(export.Feedbacks, raw)
+	}
+
+	tasks, err := h.fetchUserAssignments(ctx, maxID)
+	if err != nil {
+		h.logger.Warn("failed to fetch task assignments for export", zap.Error(err), zap.String("max_id", maxID))
+	}
+	for _, task := range tasks {
+		export.TaskAssignments = append(export.TaskAssignments, dataExportAssignment{
+			TaskID:     task.GetId(),
+			TaskName:   task.GetName(),
+			CustomerID: task.GetCustomerId(),
+			Status:     assignmentStatusForUser(parseTaskAssignments(task), maxID),
+		})
+	}
+
+	export.Preferences = h.exportPreferences(userID)
+
+	return json.MarshalIndent(export, "", "  ")
+}
+
+// exportPreferences reads the settings kept in the session store: the chosen
+// language, the locale MAX reported and the notification settings with any
+// events still waiting to be sent.
+func (h *MessageHandler) exportPreferences(userID int64) dataExportPreferences {
+	var prefs dataExportPreferences
+	if pref, ok := h.languages.load(userID); ok {
+		prefs.Language = pref.Code
+	}
+	if hint, ok := h.localeHints.load(userID); ok {
+		prefs.ReportedLocale = hint.Locale
+	}
+	if h.notifier != nil {
+		notifications := h.notifier.preferences(userID)
+		prefs.Notifications = &notifications
+		prefs.QueuedNotifications = h.notifier.queued(userID)
+	}
+
+	return prefs
+}
+
+func (h *MessageHandler) fetchUserFeedbacks(ctx context.Context, maxID string) ([]*customerpb.Feedback, error) {
+	if h.customer == nil {
+		return nil, nil
+	}
+
+	var feedbacks []*customerpb.Feedback
+	for page := 0; page < feedbackMaxPages; page++ {
+		resp, err := h.customer.GetFeedbacks(ctx, &customerpb.GetFeedbacksRequest{
+			UserId: maxID,
+			Limit:  feedbackPageSize,
+			Offset: int32(page * feedbackPageSize),
+		})
+		if err != nil {
+			return feedbacks, err
+		}
+		if svcErr := resp.GetError(); svcErr != nil {
+			if svcErr.GetCode() == customerpb.ErrorCode_ERROR_CODE_NOT_FOUND {
+				break
+			}
+			return feedbacks, fmt.Errorf("customer service error: %s", svcErr.GetMessage())
+		}
+
+		feedbacks = append(feedbacks, resp.GetFeedbacks()...)
+		if len(resp.GetFeedbacks()) < feedbackPageSize || len(feedbacks) >= int(resp.GetTotal()) {
+			break
+		}
+	}
+
+	return feedbacks, nil
+}
+
+// fetchUserAssignments scans the task list for tasks the user has an
+// assignment on. The task service cannot filter by volunteer, so this walks
+// every page up to dataExportMaxTasks.
+func (h *MessageHandler) fetchUserAssignments(ctx context.Context, maxID string) ([]*taskpb.Task, error) {
+	if h.task == nil {
+		return nil, nil
+	}
+
+	var result []*taskpb.Task
+	for offset := 0; offset < dataExportMaxTasks; offset += dataExportTaskPageSize {
+		resp, err := h.task.GetTasks(ctx, &taskpb.GetTasksRequest{
+			Limit:  dataExportTaskPageSize,
+			Offset: int32(offset),
+		})
+		if err != nil {
+			return result, err
+		}
+		if svcErr := resp.GetError(); svcErr != nil {
+			if svcErr.GetCode() == taskpb.ErrorCode_ERROR_CODE_NOT_FOUND {
+				break
+			}
+			return result, fmt.Errorf("task service error: %s", svcErr.GetMessage())
+		}
+
+		for _, task := range resp.GetTasks() {
+			if assignmentStatusForUser(parseTaskAssignments(task), maxID) != "" {
+				result = append(result, task)
+			}
+		}
+
+		if len(resp.GetTasks()) < dataExportTaskPageSize || offset+len(resp.GetTasks()) >= int(resp.GetTotal()) {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// uploadFile uploads data as a named file attachment and returns its token.
+// The client library always names uploads "file", which loses the extension,
+// so the request is made directly like deliverOutbound does.
+func (h *MessageHandler) uploadFile(ctx context.Context, name string, data []byte) (string, error) {
+	if h.cfg.MaxToken == "" {
+		return "", fmt.Errorf("max token is empty")
+	}
+
+	query := url.Values{}
+	query.Set("type", string(schemes.FILE))
+	query.Set("access_token", h.cfg.MaxToken)
+	query.Set("v", h.apiVersion)
+
+	u := h.apiBaseURL
+	if !strings.HasSuffix(u, "/") {
+		u += "/"
+	}
+	u += "uploads"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s?%s", u, query.Encode()), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload url request: %w", err)
+	}
+
+	var endpoint uploadEndpoint
+	if err := h.doJSON(req, &endpoint); err != nil {
+		return "", fmt.Errorf("failed to get upload url: %w", err)
+	}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("data", name)
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	var uploaded uploadEndpoint
+	if err := h.doJSON(req, &uploaded); err != nil {
+		return "", fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	if uploaded.Token != "" {
+		return uploaded.Token, nil
+	}
+	if endpoint.Token != "" {
+		return endpoint.Token, nil
+	}
+	return "", fmt.Errorf("upload returned no token")
+}
+
+func (h *MessageHandler) doJSON(req *http.Request, result any) error {
+	req.Header.Set("User-Agent", "max-bot-dynamic-menu/1.0")
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("http %d: %s", resp.StatusCode, strings.TrimSpace(string(bodyBytes)))
+	}
+	if len(bytes.TrimSpace(bodyBytes)) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(bodyBytes, result)
+}
+
+func (h *MessageHandler) showProfileDeleteAsk(ctx context.Context, chatID, userID int64) {
+	keyboard := h.api.Messages.NewKeyboardBuilder()
+	keyboard.AddRow().
+		AddCallback(h.messages.ProfileExportButton, schemes.DEFAULT, callbackProfileExport)
+	keyboard.AddRow().
+		AddCallback(h.messages.ProfileDeleteContinueButton, schemes.NEGATIVE, callbackProfileDeleteConfirm).
+		AddCallback(h.messages.ProfileDeleteCancelButton, schemes.DEFAULT, callbackProfileData)
+
+	h.renderMenu(ctx, chatID, userID, h.messages.ProfileDeleteAskText, keyboard)
+}
+
+func (h *MessageHandler) showProfileDeleteConfirm(ctx context.Context, chatID, userID int64) {
+	keyboard := h.api.Messages.NewKeyboardBuilder()
+	keyboard.AddRow().
+		AddCallback(h.messages.ProfileDeleteConfirmButton, schemes.NEGATIVE, callbackProfileDeleteExecute)
+	keyboard.AddRow().
+		AddCallback(h.messages.ProfileDeleteCancelButton, schemes.DEFAULT, callbackProfileData)
+
+	h.renderMenu(ctx, chatID, userID, h.messages.ProfileDeleteConfirmText, keyboard)
+}
+
+// handleProfileDelete leaves every open task first, so customers are not left
+// waiting on a volunteer that no longer exists, then deletes the user and
+// forgets all local state for them.
+func (h *MessageHandler) handleProfileDelete(ctx context.Context, chatID, userID int64) {
+	if h.user == nil {
+		h.showProfileData(ctx, chatID, userID, h.messages.ProfileDeleteErrorText)
+		return
+	}
+
+	maxID := fmt.Sprintf("%d", userID)
+
+	// The account is only deleted once the user has left every task they
+	// are still assigned to, so nobody waits for a volunteer who is gone.
+	// Tasks already left stay left when the user retries.
+	tasks, err := h.fetchUserAssignments(ctx, maxID)
+	if err != nil {
+		h.logger.Error("failed to fetch tasks before account deletion", zap.Error(err), zap.String("max_id", maxID))
+		h.showProfileData(ctx, chatID, userID, h.messages.ProfileDeleteErrorText)
+		return
+	}
+	for _, task := range tasks {
+		if !allowVolunteerLeave(assignmentStatusForUser(parseTaskAssignments(task), maxID)) {
+			continue
+		}
+		resp, err := h.task.UserLeaveTask(ctx, &taskpb.UserLeaveTaskRequest{UserId: maxID, TaskId: task.GetId()})
+		if err == nil && resp.GetError() != nil {
+			err = fmt.Errorf("task service error: %s", resp.GetError().GetMessage())
+		}
+		if err != nil {
+			h.logger.Error("failed to leave task before account deletion", zap.Error(err), zap.String("task_id", task.GetId()), zap.String("max_id", maxID))
+			h.showProfileData(ctx, chatID, userID, h.messages.ProfileDeleteErrorText)
+			return
+		}
+	}
+
+	resp, err := h.user.DeleteUser(ctx, &userpb.DeleteUserRequest{MaxId: maxID})
+	if err == nil && resp.GetError() != nil && resp.GetError().GetCode() != userpb.ErrorCode_ERROR_CODE_NOT_FOUND {
+		err = fmt.Errorf("user service error: %s", resp.GetError().GetMessage())
+	}
+	if err != nil {
+		h.logger.Error("failed to delete user", zap.Error(err), zap.String("max_id", maxID))
+		h.showProfileData(ctx, chatID, userID, h.messages.ProfileDeleteErrorText)
+		return
+	}
+
+	h.clearUserSessions(chatID, userID)
+	h.accounts.invalidate(userID)
+	h.logger.Info("user deleted their account", zap.String("max_id", maxID), zap.Int("tasks_left", len(tasks)))
+
+	h.SendMainMenu(ctx, chatID, userID, h.messages.ProfileDeleteSuccessText)
+}
+
+// clearUserSessions forgets everything the session store holds for the
+// user: conversation state, the menu in their chat, their language and
+// notification settings and any notifications still queued for them.
+func (h *MessageHandler) clearUserSessions(chatID, userID int64) {
+	h.sessions.delete(userID)
+	h.customerSessions.delete(userID)
+	h.taskSessions.delete(userID)
+	h.feedbackSessions.delete(userID)
+	h.adminSessions.delete(userID)
+	h.menus.delete(chatID)
+	h.languages.remove(userID)
+	h.localeHints.remove(userID)
+	if h.notifier != nil {
+		h.notifier.forget(userID)
+	}
+}
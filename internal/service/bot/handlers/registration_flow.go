@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 
+	"DobrikaDev/max-bot/internal/callbackrouter"
 	userpb "DobrikaDev/max-bot/internal/generated/userpb"
 
 	maxbot "github.com/max-messenger/max-bot-api-client-go"
@@ -103,6 +104,8 @@ func (h *MessageHandler) resumeRegistration(ctx context.Context, session *regist
 		h.promptForLocation(ctx, session)
 	case registrationStepAbout:
 		h.promptForAbout(ctx, session)
+	case registrationStepReview:
+		h.showProfileEditReview(ctx, session)
 	default:
 		if session.MessageID != "" {
 			h.menus.set(session.ChatID, session.MessageID, session.UserID)
@@ -112,7 +115,11 @@ func (h *MessageHandler) resumeRegistration(ctx context.Context, session *regist
 }
 
 func (h *MessageHandler) promptForAge(ctx context.Context, session *registrationSession) {
-	h.updateSessionMessage(ctx, session, h.messages.RegistrationStartText, h.ageKeyboard())
+	text := h.messages.RegistrationStartText
+	if session.Editing {
+		text = h.profileEditStepText(session, text, h.profileAgeLabel(session.Original.Age))
+	}
+	h.updateSessionMessage(ctx, session, text, h.withProfileEditKeep(session, h.ageKeyboard()))
 }
 
 func (h *MessageHandler) promptForSex(ctx context.Context, session *registrationSession) {
@@ -121,17 +128,29 @@ func (h *MessageHandler) promptForSex(ctx context.Context, session *registration
 		AddCallback(h.messages.RegistrationSexMaleText, schemes.DEFAULT, callbackRegistrationSexMale).
 		AddCallback(h.messages.RegistrationSexFemaleText, schemes.DEFAULT, callbackRegistrationSexFemale)
 
-	h.updateSessionMessage(ctx, session, h.messages.RegistrationSexPrompt, keyboard)
+	text := h.messages.RegistrationSexPrompt
+	if session.Editing {
+		text = h.profileEditStepText(session, text, h.profileSexLabel(session.Original.Sex))
+	}
+	h.updateSessionMessage(ctx, session, text, h.withProfileEditKeep(session, keyboard))
 }
 
 func (h *MessageHandler) promptForLocation(ctx context.Context, session *registrationSession) {
 	keyboard := h.api.Messages.NewKeyboardBuilder()
 	keyboard.AddRow().
 		AddGeolocation(h.messages.RegistrationLocationGeoButton, true)
+
+	text := h.messages.RegistrationLocationPrompt
+	if session.Editing {
+		text = h.profileEditStepText(session, text, h.profileLocationLabel(session.Original.Geolocation))
+		h.updateSessionMessage(ctx, session, text, h.withProfileEditKeep(session, keyboard))
+		return
+	}
+
 	keyboard.AddRow().
 		AddCallback(h.messages.RegistrationLocationSkipButton, schemes.NEGATIVE, callbackRegistrationSkipLocation)
 
-	h.updateSessionMessage(ctx, session, h.messages.RegistrationLocationPrompt, keyboard)
+	h.updateSessionMessage(ctx, session, text, keyboard)
 }
 
 func (h *MessageHandler) promptForAbout(ctx context.Context, session *registrationSession) {
@@ -148,7 +167,7 @@ func (h *MessageHandler) tryHandleRegistrationMessage(ctx context.Context, updat
 	case registrationStepAge:
 		age, err := parseAge(update.GetText())
 		if err != nil {
-			h.updateSessionMessage(ctx, session, h.messages.RegistrationAgeRetryText, h.ageKeyboard())
+			h.updateSessionMessage(ctx, session, h.messages.RegistrationAgeRetryText, h.withProfileEditKeep(session, h.ageKeyboard()))
 			return true
 		}
 
@@ -163,12 +182,14 @@ func (h *MessageHandler) tryHandleRegistrationMessage(ctx context.Context, updat
 			session.Longitude = lon
 			session.GeoLabel = label
 			session.Current = registrationStepAbout
-			session.Interests = make(map[int]bool)
-			session.About = ""
+			if !session.Editing {
+				session.Interests = make(map[int]bool)
+				session.About = ""
+			}
 			h.sessions.upsert(session)
 			h.promptForAbout(ctx, session)
 		} else {
-			h.updateSessionMessage(ctx, session, h.messages.RegistrationLocationRetryText, emptyKeyboard())
+			h.updateSessionMessage(ctx, session, h.messages.RegistrationLocationRetryText, h.withProfileEditKeep(session, emptyKeyboard()))
 		}
 
 	case registrationStepAbout:
@@ -183,73 +204,29 @@ func (h *MessageHandler) tryHandleRegistrationMessage(ctx context.Context, updat
 
 	default:
 		h.logger.Debug("received message while in registration flow", zap.Int("step", int(session.Current)))
+		h.resumeRegistration(ctx, session)
 	}
 
 	return true
 }
 
-func (h *MessageHandler) tryHandleRegistrationCallback(ctx context.Context, update *schemes.MessageCallbackUpdate) bool {
-	payload := update.Callback.Payload
-	if payload == "" {
-		return false
-	}
-
-	if strings.HasPrefix(payload, callbackRegistrationAboutToggle+":") {
-		h.handleAboutToggle(ctx, update)
-		h.answerCallback(ctx, update.Callback.CallbackID)
-		return true
-	}
-
-	switch payload {
-	case callbackMainMenuRegistration:
-		var chatID int64
-		var messageID string
-		if update.Message != nil {
-			chatID = update.Message.Recipient.ChatId
-			messageID = update.Message.Body.Mid
-			if chatID != 0 {
-				h.menus.delete(chatID)
-			}
-		} else if session, ok := h.sessions.get(update.Callback.User.UserId); ok {
-			chatID = session.ChatID
-		}
-		if chatID == 0 {
-			h.logger.Warn("registration callback without chat context")
-			h.answerCallback(ctx, update.Callback.CallbackID)
-			return true
+func (h *MessageHandler) handleRegistrationStart(ctx context.Context, update *schemes.MessageCallbackUpdate) {
+	var chatID int64
+	var messageID string
+	if update.Message != nil {
+		chatID = update.Message.Recipient.ChatId
+		messageID = update.Message.Body.Mid
+		if chatID != 0 {
+			h.menus.delete(chatID)
 		}
-		h.startRegistration(ctx, update.Callback.User.UserId, chatID, update.Callback.User.Name, messageID)
-		h.answerCallback(ctx, update.Callback.CallbackID)
-		return true
-	case callbackRegistrationSexMale, callbackRegistrationSexFemale:
-		h.handleSexSelection(ctx, update, payload)
-		h.answerCallback(ctx, update.Callback.CallbackID)
-		return true
-	case callbackRegistrationAboutConfirm:
-		h.handleAboutConfirm(ctx, update)
-		h.answerCallback(ctx, update.Callback.CallbackID)
-		return true
-	case callbackRegistrationSkipLocation:
-		h.handleLocationSkip(ctx, update)
-		h.answerCallback(ctx, update.Callback.CallbackID)
-		return true
-	case callbackRegistrationAgeUnder18,
-		callbackRegistrationAge18_24,
-		callbackRegistrationAge25_34,
-		callbackRegistrationAge35_44,
-		callbackRegistrationAge45_54,
-		callbackRegistrationAge55_64,
-		callbackRegistrationAge65Plus:
-		h.handleAgeSelection(ctx, update, payload)
-		h.answerCallback(ctx, update.Callback.CallbackID)
-		return true
-	case callbackRegistrationAboutToggle:
-		h.handleAboutToggle(ctx, update)
-		h.answerCallback(ctx, update.Callback.CallbackID)
-		return true
-	default:
-		return false
+	} else if session, ok := h.sessions.get(update.Callback.User.UserId); ok {
+		chatID = session.ChatID
+	}
+	if chatID == 0 {
+		h.logger.Warn("registration callback without chat context")
+		return
 	}
+	h.startRegistration(ctx, update.Callback.User.UserId, chatID, update.Callback.User.Name, messageID)
 }
 
 func (h *MessageHandler) handleSexSelection(ctx context.Context, update *schemes.MessageCallbackUpdate, payload string) {
@@ -321,6 +298,16 @@ func (h *MessageHandler) handleLocationSkip(ctx context.Context, update *schemes
 		session.MessageID = update.Message.Body.Mid
 	}
 
+	// A profile edit has no skip button, and the user service cannot clear
+	// a location, so a stale skip keeps the stored one.
+	if session.Editing && session.Original != nil {
+		h.restoreProfileLocation(session)
+		session.Current = registrationStepAbout
+		h.sessions.upsert(session)
+		h.promptForAbout(ctx, session)
+		return
+	}
+
 	session.GeoLabel = ""
 	session.Current = registrationStepAbout
 	session.Interests = make(map[int]bool)
@@ -329,7 +316,7 @@ func (h *MessageHandler) handleLocationSkip(ctx context.Context, update *schemes
 	h.promptForAbout(ctx, session)
 }
 
-func (h *MessageHandler) handleAboutToggle(ctx context.Context, update *schemes.MessageCallbackUpdate) {
+func (h *MessageHandler) handleAboutToggle(ctx context.Context, update *schemes.MessageCallbackUpdate, idxStr string) {
 	session, ok := h.sessions.get(update.Callback.User.UserId)
 	if !ok {
 		h.logger.Debug("about toggle without active session")
@@ -340,7 +327,6 @@ func (h *MessageHandler) handleAboutToggle(ctx context.Context, update *schemes.
 		return
 	}
 
-	idxStr := update.Callback.Payload[len(callbackRegistrationAboutToggle)+1:]
 	if idxStr == "" {
 		h.logger.Warn("empty about option payload")
 		return
@@ -411,6 +397,13 @@ func (h *MessageHandler) handleAboutConfirm(ctx context.Context, update *schemes
 }
 
 func (h *MessageHandler) finalizeRegistration(ctx context.Context, session *registrationSession) {
+	if session.Editing {
+		session.Current = registrationStepReview
+		h.sessions.upsert(session)
+		h.showProfileEditReview(ctx, session)
+		return
+	}
+
 	if err := h.sendRegistrationToUserService(ctx, session); err != nil {
 		h.logger.Error("failed to save registration", zap.Error(err), zap.Int64("user_id", session.UserID))
 		h.updateSessionMessage(ctx, session, h.messages.RegistrationErrorText, emptyKeyboard())
@@ -446,7 +439,16 @@ func extractLocation(update *schemes.MessageCreatedUpdate) (float64, float64, st
 	return 0, 0, "", false
 }
 
+// answerCallback acknowledges a callback unless something already answered
+// it during this dispatch.
 func (h *MessageHandler) answerCallback(ctx context.Context, callbackID string) {
+	if !callbackrouter.ClaimAnswer(ctx) {
+		return
+	}
+	h.postCallbackAnswer(ctx, callbackID)
+}
+
+func (h *MessageHandler) postCallbackAnswer(ctx context.Context, callbackID string) {
 	if callbackID == "" {
 		return
 	}
@@ -514,5 +516,5 @@ func (h *MessageHandler) aboutKeyboard(session *registrationSession) *maxbot.Key
 	keyboard.AddRow().
 		AddCallback(h.messages.RegistrationAboutConfirmButton, schemes.POSITIVE, callbackRegistrationAboutConfirm)
 
-	return keyboard
+	return h.withProfileEditKeep(session, keyboard)
 }
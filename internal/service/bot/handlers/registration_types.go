@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"fmt"
-	"sync"
+	"time"
 
 	userpb "DobrikaDev/max-bot/internal/generated/userpb"
+	"DobrikaDev/max-bot/internal/storage"
+
+	"go.uber.org/zap"
 )
 
 type registrationStep int
@@ -16,8 +19,20 @@ const (
 	registrationStepLocation
 	registrationStepAbout
 	registrationStepComplete
+	// registrationStepReview is only used by the profile edit flow. It is
+	// declared last so that persisted sessions keep their step numbers.
+	registrationStepReview
 )
 
+// profileSnapshot keeps the values a profile had when editing started, so the
+// review step can show what changed.
+type profileSnapshot struct {
+	Age         int32
+	Sex         userpb.Sex
+	Geolocation string
+	About       string
+}
+
 type registrationSession struct {
 	UserID    int64
 	ChatID    int64
@@ -34,6 +49,9 @@ type registrationSession struct {
 	OriginalAboutOptionPrefix map[int]string
 	Current                   registrationStep
 	MessageID                 string
+
+	Editing  bool
+	Original *profileSnapshot
 }
 
 func (s *registrationSession) geolocationAsString() string {
@@ -52,32 +70,21 @@ func (s *registrationSession) isInProgress() bool {
 }
 
 type sessionStore struct {
-	mu       sync.RWMutex
-	sessions map[int64]*registrationSession
+	table sessionTable[registrationSession]
 }
 
-func newSessionStore() *sessionStore {
-	return &sessionStore{sessions: make(map[int64]*registrationSession)}
+func newSessionStore(store storage.Store, ttl time.Duration, logger *zap.Logger) *sessionStore {
+	return &sessionStore{table: newSessionTable[registrationSession](store, sessionBucketRegistration, ttl, logger)}
 }
 
 func (s *sessionStore) get(userID int64) (*registrationSession, bool) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	session, ok := s.sessions[userID]
-	return session, ok
+	return s.table.load(userID)
 }
 
 func (s *sessionStore) upsert(session *registrationSession) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	s.sessions[session.UserID] = session
+	s.table.save(session.UserID, session)
 }
 
 func (s *sessionStore) delete(userID int64) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	delete(s.sessions, userID)
+	s.table.remove(userID)
 }
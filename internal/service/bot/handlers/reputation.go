@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	userpb "DobrikaDev/max-bot/internal/generated/userpb"
+
+	"go.uber.org/zap"
+)
+
+const progressBarWidth = 10
+
+// reputationLadder is the list of reputation groups ordered by the
+// reputation they require, together with where the user stands on it.
+type reputationLadder struct {
+	groups     []*userpb.ReputationGroup
+	current    int
+	reputation int32
+}
+
+func (h *MessageHandler) showCoinsLevels(ctx context.Context, chatID, userID int64) {
+	h.renderMenu(ctx, chatID, userID, h.buildCoinsLevelsText(ctx, userID), h.coinsDetailKeyboard())
+}
+
+func (h *MessageHandler) buildCoinsLevelsText(ctx context.Context, userID int64) string {
+	if h.user == nil {
+		return h.messages.CoinsLevelsText
+	}
+
+	ladder, err := h.fetchReputationLadder(ctx, fmt.Sprintf("%d", userID))
+	if err != nil {
+		h.logger.Error("failed to build reputation ladder", zap.Error(err), zap.Int64("user_id", userID))
+		return h.messages.CoinsLevelsText + "\n\n" + h.coinsLevelsErrorText()
+	}
+	if len(ladder.groups) == 0 {
+		return h.messages.CoinsLevelsText
+	}
+
+	var builder strings.Builder
+	builder.WriteString(h.coinsLevelsTitle())
+	builder.WriteString("\n\n")
+	builder.WriteString(fmt.Sprintf(h.coinsLevelsReputationTemplate(), ladder.reputation))
+	builder.WriteString("\n\n")
+
+	for idx, group := range ladder.groups {
+		marker := "🔒"
+		switch {
+		case idx == ladder.current:
+			marker = "👉"
+		case idx < ladder.current:
+			marker = "✅"
+		}
+
+		builder.WriteString(fmt.Sprintf(h.coinsLevelsItemTemplate(), marker, escapeMarkdown(strings.TrimSpace(group.GetName())), group.GetReputationNeed(), formatCoefficient(group.GetCoefficient())))
+		if idx == ladder.current {
+			builder.WriteString(" · ")
+			builder.WriteString(h.coinsLevelsCurrentLabel())
+		}
+		builder.WriteString("\n")
+
+		if description := strings.TrimSpace(group.GetDescription()); description != "" {
+			builder.WriteString("_")
+			builder.WriteString(escapeMarkdown(description))
+			builder.WriteString("_\n")
+		}
+	}
+
+	builder.WriteString("\n")
+	if next := ladder.next(); next != nil {
+		from := int32(0)
+		if ladder.current >= 0 {
+			from = ladder.groups[ladder.current].GetReputationNeed()
+		}
+		bar := progressBar(ladder.reputation-from, next.GetReputationNeed()-from, progressBarWidth)
+		builder.WriteString(fmt.Sprintf(h.coinsLevelsProgressTemplate(), escapeMarkdown(strings.TrimSpace(next.GetName())), ladder.reputation, next.GetReputationNeed(), bar))
+	} else {
+		builder.WriteString(h.coinsLevelsMaxText())
+	}
+
+	return builder.String()
+}
+
+// fetchReputationLadder loads the groups and places the user on them. The
+// group stored on the user wins; when it is missing or unknown, the highest
+// group whose threshold the user's reputation reaches is used instead.
+func (h *MessageHandler) fetchReputationLadder(ctx context.Context, maxID string) (*reputationLadder, error) {
+	groups, err := h.fetchReputationGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ladder := &reputationLadder{groups: groups, current: -1}
+	if len(groups) == 0 {
+		return ladder, nil
+	}
+
+	ladder.reputation, err = h.userReputation(ctx, maxID)
+	if err != nil {
+		h.logger.Warn("failed to compute reputation", zap.Error(err), zap.String("max_id", maxID))
+	}
+
+	var currentID int32
+	if resp, err := h.user.GetUserByMaxID(ctx, &userpb.GetUserByMaxIDRequest{MaxId: maxID}); err != nil {
+		h.logger.Warn("failed to fetch user for reputation ladder", zap.Error(err), zap.String("max_id", maxID))
+	} else if group := resp.GetUser().GetReputationGroup(); group != nil {
+		currentID = group.GetId()
+	}
+
+	for idx, group := range groups {
+		if currentID != 0 && group.GetId() == currentID {
+			ladder.current = idx
+			return ladder, nil
+		}
+	}
+	for idx, group := range groups {
+		if ladder.reputation >= group.GetReputationNeed() {
+			ladder.current = idx
+		}
+	}
+
+	return ladder, nil
+}
+
+func (l *reputationLadder) next() *userpb.ReputationGroup {
+	if l.current+1 < len(l.groups) {
+		return l.groups[l.current+1]
+	}
+	return nil
+}
+
+func (h *MessageHandler) fetchReputationGroups(ctx context.Context) ([]*userpb.ReputationGroup, error) {
+	resp, err := h.user.GetReputationGroups(ctx, &userpb.GetReputationGroupsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	if svcErr := resp.GetError(); svcErr != nil {
+		if svcErr.GetCode() == userpb.ErrorCode_ERROR_CODE_NOT_FOUND {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("user service error: %s", svcErr.GetMessage())
+	}
+
+	groups := make([]*userpb.ReputationGroup, 0, len(resp.GetReputationGroups()))
+	for _, group := range resp.GetReputationGroups() {
+		if group != nil {
+			groups = append(groups, group)
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].GetReputationNeed() < groups[j].GetReputationNeed()
+	})
+
+	return groups, nil
+}
+
+// userReputation is the number of coins the user has earned over all time.
+// Spending coins does not lower it, so buying something never costs a level.
+func (h *MessageHandler) userReputation(ctx context.Context, maxID string) (int32, error) {
+	history, err := h.fetchLedger(ctx, maxID)
+	if err != nil {
+		return 0, err
+	}
+
+	var earned int32
+	for _, entry := range history.entries {
+		if entry.amount > 0 {
+			earned += entry.amount
+		}
+	}
+	return earned, nil
+}
+
+// rewardCoefficient returns the multiplier of the user's reputation group,
+// or 1 when the group is unknown.
+func (h *MessageHandler) rewardCoefficient(ctx context.Context, maxID string) float64 {
+	if h.user == nil {
+		return 1
+	}
+
+	resp, err := h.user.GetUserByMaxID(ctx, &userpb.GetUserByMaxIDRequest{MaxId: maxID})
+	if err != nil || resp.GetError() != nil {
+		return 1
+	}
+
+	coefficient := resp.GetUser().GetReputationGroup().GetCoefficient()
+	if coefficient <= 0 {
+		return 1
+	}
+	return coefficient
+}
+
+// expectedReward is the task cost times the coefficient of the volunteer's
+// level. It is only shown in task lists; approvals credit the cost.
+func expectedReward(cost int32, coefficient float64) int32 {
+	if coefficient <= 0 {
+		return cost
+	}
+	return int32(math.Round(float64(cost) * coefficient))
+}
+
+// formatCoefficient renders 1.25 as "1,25" and 1.5 as "1,5".
+func formatCoefficient(coefficient float64) string {
+	text := strconv.FormatFloat(coefficient, 'f', 2, 64)
+	text = strings.TrimRight(strings.TrimRight(text, "0"), ".")
+	return strings.Replace(text, ".", ",", 1)
+}
+
+func progressBar(value, total int32, width int) string {
+	if total <= 0 {
+		total = 1
+	}
+	if value < 0 {
+		value = 0
+	}
+	if value > total {
+		value = total
+	}
+
+	filled := int(int64(value) * int64(width) / int64(total))
+	percent := int(int64(value) * 100 / int64(total))
+	return fmt.Sprintf("%s%s %d%%", strings.Repeat("▓", filled), strings.Repeat("░", width-filled), percent)
+}
+
+func (h *MessageHandler) coinsLevelsTitle() string {
+	if text := strings.TrimSpace(h.messages.CoinsLevelsTitle); text != "" {
+		return text
+	}
+	return "🏆 *Уровни Добрики*"
+}
+
+func (h *MessageHandler) coinsLevelsReputationTemplate() string {
+	if text := strings.TrimSpace(h.messages.CoinsLevelsReputationTemplate); text != "" {
+		return text
+	}
+	return "Твоя репутация: *%d*"
+}
+
+func (h *MessageHandler) coinsLevelsItemTemplate() string {
+	if text := strings.TrimSpace(h.messages.CoinsLevelsItemTemplate); text != "" {
+		return text
+	}
+	return "%s *%s* — от %d · награды ×%s"
+}
+
+func (h *MessageHandler) coinsLevelsCurrentLabel() string {
+	if text := strings.TrimSpace(h.messages.CoinsLevelsCurrentLabel); text != "" {
+		return text
+	}
+	return "ты здесь"
+}
+
+func (h *MessageHandler) coinsLevelsProgressTemplate() string {
+	if text := strings.TrimSpace(h.messages.CoinsLevelsProgressTemplate); text != "" {
+		return text
+	}
+	return "До уровня «%s»: %d из %d\n%s"
+}
+
+func (h *MessageHandler) coinsLevelsMaxText() string {
+	if text := strings.TrimSpace(h.messages.CoinsLevelsMaxText); text != "" {
+		return text
+	}
+	return "🌟 Ты на самом высоком уровне!"
+}
+
+func (h *MessageHandler) coinsLevelsErrorText() string {
+	if text := strings.TrimSpace(h.messages.CoinsLevelsErrorText); text != "" {
+		return text
+	}
+	return "Не удалось загрузить уровни. Попробуй позже."
+}
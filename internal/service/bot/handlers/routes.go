@@ -0,0 +1,340 @@
+package handlers
+
+//go:generate go run DobrikaDev/max-bot/internal/callbackrouter/cmd/callbackgen -in routes.go -out navigation.go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"DobrikaDev/max-bot/internal/callbackrouter"
+
+	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
+	"go.uber.org/zap"
+)
+
+// callbackRoute binds a payload pattern to its handler. The name is the
+// constant navigation.go declares for the pattern's prefix; keep the first two
+// fields string literals so callbackgen can read them.
+type callbackRoute struct {
+	name    string
+	pattern string
+	handle  callbackrouter.HandlerFunc
+}
+
+func (h *MessageHandler) callbackRouteTable() []callbackRoute {
+	return []callbackRoute{
+		// Registration and profile editing.
+		{"callbackMainMenuRegistration", "nav:registration:start", h.onUpdate(h.handleRegistrationStart)},
+		{"callbackProfileEditKeep", "profile:edit:keep", h.onUpdate(h.handleProfileEditKeep)},
+		{"callbackProfileEditSave", "profile:edit:save", h.onUpdate(h.handleProfileEditSave)},
+		{"callbackProfileEditCancel", "profile:edit:cancel", h.onUpdate(h.handleProfileEditCancel)},
+		{"callbackRegistrationSexMale", "registration:sex:male", h.onPayload(h.handleSexSelection)},
+		{"callbackRegistrationSexFemale", "registration:sex:female", h.onPayload(h.handleSexSelection)},
+		{"callbackRegistrationAgeUnder18", "registration:age:under18", h.onPayload(h.handleAgeSelection)},
+		{"callbackRegistrationAge18_24", "registration:age:18_24", h.onPayload(h.handleAgeSelection)},
+		{"callbackRegistrationAge25_34", "registration:age:25_34", h.onPayload(h.handleAgeSelection)},
+		{"callbackRegistrationAge35_44", "registration:age:35_44", h.onPayload(h.handleAgeSelection)},
+		{"callbackRegistrationAge45_54", "registration:age:45_54", h.onPayload(h.handleAgeSelection)},
+		{"callbackRegistrationAge55_64", "registration:age:55_64", h.onPayload(h.handleAgeSelection)},
+		{"callbackRegistrationAge65Plus", "registration:age:65_plus", h.onPayload(h.handleAgeSelection)},
+		{"callbackRegistrationSkipLocation", "registration:location:skip", h.onUpdate(h.handleLocationSkip)},
+		{"callbackRegistrationAboutConfirm", "registration:about:confirm", h.onUpdate(h.handleAboutConfirm)},
+		{"callbackRegistrationAboutToggle", "registration:about:toggle", h.onPayload(func(ctx context.Context, update *schemes.MessageCallbackUpdate, _ string) {
+			h.handleAboutToggle(ctx, update, "")
+		})},
+		{"callbackRegistrationAboutToggle", "registration:about:toggle/{index}", func(ctx context.Context, req *callbackrouter.Request) error {
+			h.handleAboutToggle(ctx, req.Update, req.Param("index"))
+			return nil
+		}},
+
+		// Customer profile.
+		{"callbackMainMenuNeedHelp", "nav:main:need_help", h.onUpdate(h.handleCustomerNeedHelp)},
+		{"callbackCustomerTypeIndividual", "customer:type:individual", h.onPayload(h.handleCustomerTypeSelection)},
+		{"callbackCustomerTypeBusiness", "customer:type:business", h.onPayload(h.handleCustomerTypeSelection)},
+		{"callbackCustomerManageCreate", "customer:manage:create", h.onUpdate(h.handleCustomerManageCreate)},
+		{"callbackCustomerManageUpdate", "customer:manage:update", h.onUpdate(h.handleCustomerManageUpdate)},
+		{"callbackCustomerManageDelete", "customer:manage:delete", h.onUpdate(h.handleCustomerManageDelete)},
+		{"callbackCustomerDeleteConfirm", "customer:delete:confirm", h.onUpdate(h.handleCustomerDeleteConfirm)},
+		{"callbackCustomerDeleteCancel", "customer:delete:cancel", h.onUpdate(h.handleCustomerDeleteCancel)},
+		{"callbackCustomerManageBack", "customer:manage:back", h.onUpdate(h.handleCustomerManageBack)},
+		{"callbackCustomerManageTasks", "customer:manage:tasks", h.onUpdate(h.handleCustomerManageTasks)},
+		{"callbackCustomerManageCreateTask", "customer:manage:create_task", h.onUpdate(h.handleCustomerManageCreateTask)},
+
+		// Task creation. These only apply while a draft is in progress.
+		{"callbackTaskCreateModeOnline", "task:create:mode:online", h.onFlow(func(ctx context.Context, update *schemes.MessageCallbackUpdate) bool {
+			return h.handleTaskCreateMode(ctx, update, true)
+		})},
+		{"callbackTaskCreateModeOffline", "task:create:mode:offline", h.onFlow(func(ctx context.Context, update *schemes.MessageCallbackUpdate) bool {
+			return h.handleTaskCreateMode(ctx, update, false)
+		})},
+		{"callbackTaskCreateSkipLocation", "task:create:location:skip", h.onFlow(h.handleTaskCreateSkipLocation)},
+		{"callbackTaskCreateSkipMembers", "task:create:members:skip", h.onFlow(h.handleTaskCreateSkipMembers)},
+		{"callbackTaskCreateConfirm", "task:create:confirm", h.onFlow(h.handleTaskCreateConfirm)},
+		{"callbackTaskCreateRestart", "task:create:restart", h.onFlow(h.handleTaskCreateRestart)},
+		{"callbackTaskEditKeep", "task:edit:keep", h.onFlow(h.handleTaskEditKeep)},
+		{"callbackTaskEditCancel", "task:edit:cancel", h.onFlow(h.handleTaskEditCancel)},
+
+		// Volunteer tasks.
+		{"callbackMainMenuHelp", "nav:main:help", h.onScreen(h.showVolunteerMenu)},
+		{"callbackVolunteerBack", "volunteer:back", h.onScreen(h.showVolunteerMenu)},
+		{"callbackVolunteerOnDemand", "volunteer:on_demand", h.onScreen(func(ctx context.Context, chatID, userID int64) {
+			h.showVolunteerTasksList(ctx, chatID, userID, volunteerTasksViewModeOnDemand, volunteerTasksFilterAll, "", 0)
+		})},
+		{"callbackVolunteerTasks", "volunteer:tasks", h.onScreen(func(ctx context.Context, chatID, userID int64) {
+			h.showVolunteerTasksList(ctx, chatID, userID, volunteerTasksViewModeAll, volunteerTasksFilterAll, "", 0)
+		})},
+		{"callbackVolunteerLocationSkip", "volunteer:location:skip", h.onScreen(h.handleVolunteerLocationSkip)},
+		{"callbackVolunteerTasksFilter", "volunteer:tasks:filter/{mode}/{filter}", func(ctx context.Context, req *callbackrouter.Request) error {
+			h.handleVolunteerTasksFilter(ctx, req.Update, req.Param("mode"), req.Param("filter"))
+			return nil
+		}},
+		{"callbackVolunteerTasksPage", "volunteer:tasks:page/{mode}/{filter}/{page}", func(ctx context.Context, req *callbackrouter.Request) error {
+			h.handleVolunteerTasksPage(ctx, req.Update, req.Param("mode"), req.Param("filter"), req.Param("page"))
+			return nil
+		}},
+		{"callbackVolunteerTaskView", "volunteer:task:view/{taskID}", h.onTask(h.handleVolunteerTaskView)},
+		{"callbackVolunteerTaskJoin", "volunteer:task:join/{taskID}", h.onTask(h.handleVolunteerTaskJoin)},
+		{"callbackVolunteerTaskLeave", "volunteer:task:leave/{taskID}", h.onTask(h.handleVolunteerTaskLeave)},
+		{"callbackVolunteerTaskConfirm", "volunteer:task:confirm/{taskID}", h.onTask(h.handleVolunteerTaskConfirm)},
+
+		// Customer tasks and feedback. Every pattern starts with "customer:", so
+		// the task, volunteer and customer it names are checked, see
+		// customerCallbackNamespace.
+		{"callbackCustomerTasksPage", "customer:tasks:page/{customerID}/{page}", func(ctx context.Context, req *callbackrouter.Request) error {
+			h.handleCustomerTasksPage(ctx, req.Update, req.Param("customerID"), req.Param("page"))
+			return nil
+		}},
+		{"callbackCustomerTaskView", "customer:task:view/{taskID}", h.onTask(h.handleCustomerTaskView)},
+		{"callbackCustomerTaskEdit", "customer:task:edit/{taskID}", h.onTask(h.handleCustomerTaskEdit)},
+		{"callbackCustomerTaskCancelAsk", "customer:task:cancel:ask/{taskID}", h.onTask(h.handleCustomerTaskCancelAsk)},
+		{"callbackCustomerTaskCancelConfirm", "customer:task:cancel:confirm/{taskID}", h.onTask(h.handleCustomerTaskCancelConfirm)},
+		{"callbackCustomerTaskAssignment", "customer:task:assignment/{taskID}/{volunteerID}", h.onAssignment(h.handleCustomerTaskAssignment)},
+		{"callbackCustomerTaskApprove", "customer:task:approve/{taskID}/{volunteerID}", h.onAssignment(h.handleCustomerTaskApprove)},
+		{"callbackCustomerTaskReject", "customer:task:reject/{taskID}/{volunteerID}", h.onAssignment(h.handleCustomerTaskReject)},
+		{"callbackCustomerFeedbackStart", "customer:feedback:start/{taskID}/{volunteerID}", h.onAssignment(h.handleCustomerFeedbackStart)},
+		{"callbackCustomerFeedbackSkip", "customer:feedback:skip/{taskID}/{volunteerID}", h.onAssignment(h.handleCustomerFeedbackSkip)},
+		{"callbackCustomerFeedbackRate", "customer:feedback:rate/{taskID}/{volunteerID}/{rating}", func(ctx context.Context, req *callbackrouter.Request) error {
+			h.handleCustomerFeedbackRate(ctx, req.Update, req.Param("taskID"), req.Param("volunteerID"), req.Param("rating"))
+			return nil
+		}},
+		{"callbackCustomerFeedbackSubmit", "customer:feedback:submit", h.onUpdate(h.handleCustomerFeedbackNoComment)},
+
+		// Profile.
+		{"callbackMainMenuProfile", "nav:main:profile", h.onScreen(func(ctx context.Context, chatID, userID int64) {
+			h.showProfile(ctx, chatID, userID)
+		})},
+		{"callbackProfileBack", "profile:back", h.onScreen(func(ctx context.Context, chatID, userID int64) {
+			h.SendMainMenu(ctx, chatID, userID)
+		})},
+		{"callbackProfileHistory", "profile:history", h.onScreen(h.showProfileHistory)},
+		{"callbackProfileHistoryPage", "profile:history:page/{page}", func(ctx context.Context, req *callbackrouter.Request) error {
+			h.handleProfileHistoryPage(ctx, req.Update, req.Param("page"))
+			return nil
+		}},
+		{"callbackProfileEdit", "profile:edit", func(ctx context.Context, req *callbackrouter.Request) error {
+			if req.Update.Message == nil {
+				return callbackrouter.ErrNotHandled
+			}
+			h.startProfileEdit(ctx, req.ChatID(), req.UserID(), req.Update.Message.Body.Mid)
+			return nil
+		}},
+		{"callbackProfileSecurity", "profile:security", h.onScreen(h.showProfileSecurity)},
+		{"callbackProfileData", "profile:data", h.onScreen(func(ctx context.Context, chatID, userID int64) {
+			h.showProfileData(ctx, chatID, userID)
+		})},
+		{"callbackProfileExport", "profile:data:export", h.onScreen(h.handleProfileExport)},
+		{"callbackProfileDeleteAsk", "profile:data:delete", h.onScreen(h.showProfileDeleteAsk)},
+		{"callbackProfileDeleteConfirm", "profile:data:delete:confirm", h.onScreen(h.showProfileDeleteConfirm)},
+		{"callbackProfileDeleteExecute", "profile:data:delete:execute", h.onScreen(h.handleProfileDelete)},
+		{"callbackProfileCoins", "profile:coins", h.onScreen(h.showProfileCoinsMenu)},
+		{"callbackCoinsHowToGet", "profile:coins:get", h.onScreen(h.showCoinsHowToGet)},
+		{"callbackCoinsHowToSpend", "profile:coins:spend", h.onScreen(h.showCoinsHowToSpend)},
+		{"callbackCoinsLevels", "profile:coins:levels", h.onScreen(h.showCoinsLevels)},
+		{"callbackProfileNotify", "profile:notify", h.onScreen(h.showNotificationSettings)},
+		{"callbackProfileNotifyToggle", "profile:notify:toggle", h.onScreen(h.toggleNotifications)},
+		{"callbackProfileNotifyQuiet", "profile:notify:quiet", h.onScreen(h.cycleQuietHours)},
+		{"callbackProfileLanguage", "profile:language", h.onScreen(h.showLanguageSettings)},
+		{"callbackProfileLanguageSet", "profile:language:set/{lang}", h.handleLanguageSet},
+
+		// Admin. Every pattern starts with "admin:", which limits it to admins,
+		// see adminCallbackNamespace.
+		{"callbackAdminMenu", "admin:menu", h.onScreen(h.showAdminMenu)},
+		{"callbackAdminUsers", "admin:users/{status}/{role}/{page}", func(ctx context.Context, req *callbackrouter.Request) error {
+			h.handleAdminUsers(ctx, req.Update, req.Param("status"), req.Param("role"), req.Param("page"))
+			return nil
+		}},
+		{"callbackAdminUsersSearch", "admin:users:search", h.onScreen(h.startAdminUserSearch)},
+		{"callbackAdminUser", "admin:user/{userID}", func(ctx context.Context, req *callbackrouter.Request) error {
+			h.handleAdminUserView(ctx, req.Update, req.Param("userID"))
+			return nil
+		}},
+		{"callbackAdminUserStatus", "admin:user:status/{userID}/{status}", func(ctx context.Context, req *callbackrouter.Request) error {
+			h.handleAdminUserStatus(ctx, req.Update, req.Param("userID"), req.Param("status"))
+			return nil
+		}},
+		{"callbackAdminCoins", "admin:coins/{userID}/{direction}", func(ctx context.Context, req *callbackrouter.Request) error {
+			h.handleAdminCoinsStart(ctx, req.Update, req.Param("userID"), req.Param("direction"))
+			return nil
+		}},
+		{"callbackAdminCoinsConfirm", "admin:coins:confirm", h.onUpdate(h.handleAdminCoinsConfirm)},
+		{"callbackAdminTasks", "admin:tasks/{page}", func(ctx context.Context, req *callbackrouter.Request) error {
+			h.handleAdminTasks(ctx, req.Update, req.Param("page"))
+			return nil
+		}},
+		{"callbackAdminTask", "admin:task/{taskID}", h.onTask(h.handleAdminTaskView)},
+		{"callbackAdminTaskDeleteAsk", "admin:task:delete:ask/{taskID}", h.onTask(h.handleAdminTaskDeleteAsk)},
+		{"callbackAdminTaskDelete", "admin:task:delete:do/{taskID}", h.onTask(h.handleAdminTaskDelete)},
+		{"callbackAdminAudit", "admin:audit", h.onScreen(h.showAdminAudit)},
+		{"callbackAdminPayouts", "admin:payouts", h.onScreen(h.showAdminPayouts)},
+		{"callbackAdminPayoutView", "admin:payouts:view/{taskID}/{volunteerID}", h.onAssignment(h.handleAdminPayoutView)},
+		{"callbackAdminPayoutRetry", "admin:payouts:retry/{taskID}/{volunteerID}", h.onAssignment(h.handleAdminPayoutRetry)},
+		{"callbackAdminPayoutRevertAsk", "admin:payouts:revert:ask/{taskID}/{volunteerID}", h.onAssignment(h.handleAdminPayoutRevertAsk)},
+		{"callbackAdminPayoutRevert", "admin:payouts:revert:do/{taskID}/{volunteerID}", h.onAssignment(h.handleAdminPayoutRevert)},
+
+		// About.
+		{"callbackMainMenuAbout", "nav:main:about", h.onScreen(h.showAboutDobrikaMenu)},
+		{"callbackAboutHowItWorks", "about:how", h.onScreen(func(ctx context.Context, chatID, userID int64) {
+			h.renderMenu(ctx, chatID, userID, h.messages.AboutDobrikaHowText, h.aboutMenuKeyboard())
+		})},
+		{"callbackAboutRules", "about:rules", h.onScreen(func(ctx context.Context, chatID, userID int64) {
+			h.renderMenu(ctx, chatID, userID, h.messages.AboutDobrikaRulesText, h.aboutMenuKeyboard())
+		})},
+		{"callbackAboutInitiator", "about:initiator", h.onScreen(func(ctx context.Context, chatID, userID int64) {
+			h.renderMenu(ctx, chatID, userID, h.messages.AboutDobrikaInitiatorText, h.aboutMenuKeyboard())
+		})},
+		{"callbackAboutSupport", "about:support", h.onScreen(func(ctx context.Context, chatID, userID int64) {
+			h.renderMenu(ctx, chatID, userID, h.messages.AboutDobrikaSupportText, h.aboutMenuKeyboard())
+		})},
+		{"callbackAboutBack", "about:back", h.onScreen(func(ctx context.Context, chatID, userID int64) {
+			h.SendMainMenu(ctx, chatID, userID)
+		})},
+	}
+}
+
+// newCallbackRouter registers the route table behind the shared middleware.
+// Conflicting patterns and a navigation.go that no longer matches the table
+// are reported together so they can be fixed in one go.
+func (h *MessageHandler) newCallbackRouter() (*callbackrouter.Router, error) {
+	router := callbackrouter.New()
+	router.Use(
+		callbackrouter.Recover(h.logger),
+		callbackrouter.AutoAnswer(func(ctx context.Context, req *callbackrouter.Request) {
+			h.postCallbackAnswer(ctx, req.Update.Callback.CallbackID)
+		}),
+		callbackrouter.Logging(h.logger),
+		h.authorizeMiddleware,
+	)
+
+	var stale []string
+	names := make(map[string]bool)
+	for _, route := range h.callbackRouteTable() {
+		if err := router.Handle(route.pattern, route.handle); err != nil {
+			continue
+		}
+		names[route.name] = true
+		prefix, _, _ := callbackrouter.ParsePattern(route.pattern)
+		if callbackPrefixByName[route.name] != prefix {
+			stale = append(stale, route.name)
+		}
+	}
+	for name := range callbackPrefixByName {
+		if !names[name] {
+			stale = append(stale, name)
+		}
+	}
+
+	err := router.Err()
+	if len(stale) > 0 {
+		err = fmt.Errorf("%w\nnavigation.go is stale for %v, run go generate", err, stale)
+	}
+	return router, err
+}
+
+// authorizeMiddleware stops callbacks whose route parameters point at a
+// task or customer the user does not own. The denial is answered and audited by
+// authorizeCallback, so the callback counts as handled.
+func (h *MessageHandler) authorizeMiddleware(next callbackrouter.HandlerFunc) callbackrouter.HandlerFunc {
+	return func(ctx context.Context, req *callbackrouter.Request) error {
+		if !h.authorizeCallback(ctx, req) {
+			return nil
+		}
+		return next(ctx, req)
+	}
+}
+
+// dispatchCallback routes a callback. Anything the router cannot place, a
+// stale button or a flow that is no longer active, falls back to the main
+// menu in place of the message the button was on.
+func (h *MessageHandler) dispatchCallback(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate) {
+	err := h.router.Dispatch(ctx, callbackQuery)
+	if err == nil {
+		return
+	}
+
+	if errors.Is(err, callbackrouter.ErrNoRoute) {
+		h.logger.Debug("no route for callback", zap.String("payload", callbackQuery.Callback.Payload))
+		h.answerCallback(ctx, callbackQuery.Callback.CallbackID)
+	}
+
+	if callbackQuery.Message != nil {
+		chatID := callbackQuery.Message.Recipient.ChatId
+		userID := callbackQuery.Message.Recipient.UserId
+		if callbackQuery.Message.Body.Mid != "" {
+			h.menus.set(chatID, callbackQuery.Message.Body.Mid, userID)
+		}
+		h.SendMainMenu(ctx, chatID, userID)
+	}
+}
+
+// onUpdate adapts handlers that read everything from the update.
+func (h *MessageHandler) onUpdate(fn func(ctx context.Context, update *schemes.MessageCallbackUpdate)) callbackrouter.HandlerFunc {
+	return func(ctx context.Context, req *callbackrouter.Request) error {
+		fn(ctx, req.Update)
+		return nil
+	}
+}
+
+// onPayload adapts handlers that share one function across several exact
+// routes and tell them apart by payload.
+func (h *MessageHandler) onPayload(fn func(ctx context.Context, update *schemes.MessageCallbackUpdate, payload string)) callbackrouter.HandlerFunc {
+	return func(ctx context.Context, req *callbackrouter.Request) error {
+		fn(ctx, req.Update, req.Payload)
+		return nil
+	}
+}
+
+// onFlow adapts handlers that report whether their flow was active.
+func (h *MessageHandler) onFlow(fn func(ctx context.Context, update *schemes.MessageCallbackUpdate) bool) callbackrouter.HandlerFunc {
+	return func(ctx context.Context, req *callbackrouter.Request) error {
+		if !fn(ctx, req.Update) {
+			return callbackrouter.ErrNotHandled
+		}
+		return nil
+	}
+}
+
+// onScreen adapts menu screens, which need the chat the button was in.
+func (h *MessageHandler) onScreen(fn func(ctx context.Context, chatID, userID int64)) callbackrouter.HandlerFunc {
+	return func(ctx context.Context, req *callbackrouter.Request) error {
+		if req.Update.Message == nil {
+			return callbackrouter.ErrNotHandled
+		}
+		fn(ctx, req.ChatID(), req.UserID())
+		return nil
+	}
+}
+
+func (h *MessageHandler) onTask(fn func(ctx context.Context, update *schemes.MessageCallbackUpdate, taskID string)) callbackrouter.HandlerFunc {
+	return func(ctx context.Context, req *callbackrouter.Request) error {
+		fn(ctx, req.Update, req.Param("taskID"))
+		return nil
+	}
+}
+
+func (h *MessageHandler) onAssignment(fn func(ctx context.Context, update *schemes.MessageCallbackUpdate, taskID, volunteerID string)) callbackrouter.HandlerFunc {
+	return func(ctx context.Context, req *callbackrouter.Request) error {
+		fn(ctx, req.Update, req.Param("taskID"), req.Param("volunteerID"))
+		return nil
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"DobrikaDev/max-bot/internal/callbackrouter"
+
+	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
+)
+
+// TestCallbackRouteTable registers the real route table: no two patterns may
+// overlap, navigation.go must be up to date and every pattern must be the
+// one its own payloads reach.
+func TestCallbackRouteTable(t *testing.T) {
+	h, _ := newTestHandler(t)
+
+	router, err := h.newCallbackRouter()
+	if err != nil {
+		t.Fatalf("route table: %v", err)
+	}
+
+	for _, route := range h.callbackRouteTable() {
+		prefix, params, _ := callbackrouter.ParsePattern(route.pattern)
+		payload := prefix + strings.Repeat(":x", len(params))
+
+		got, _, ok := router.Match(payload)
+		if !ok || got.Pattern != route.pattern {
+			t.Errorf("payload %q of %q matches %v", payload, route.pattern, got)
+		}
+	}
+}
+
+// TestCallbackRouterAnswersPanics checks the middleware order: Recover sits
+// outside AutoAnswer, so a handler that panics still gets its button
+// answered and the panic comes back as an error.
+func TestCallbackRouterAnswersPanics(t *testing.T) {
+	h, fake := newTestHandler(t)
+
+	router, err := h.newCallbackRouter()
+	if err != nil {
+		t.Fatalf("route table: %v", err)
+	}
+	if err := router.Handle("test:panic", func(context.Context, *callbackrouter.Request) error {
+		panic("boom")
+	}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	err = router.Dispatch(context.Background(), &schemes.MessageCallbackUpdate{
+		Callback: schemes.Callback{CallbackID: "cb", Payload: "test:panic", User: schemes.User{UserId: 4242}},
+	})
+	if !errors.Is(err, callbackrouter.ErrPanic) {
+		t.Fatalf("Dispatch: got %v, want ErrPanic", err)
+	}
+	if answers := fake.callbackAnswers(); len(answers) != 1 {
+		t.Fatalf("callback answers: got %q, want exactly one", answers)
+	}
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"DobrikaDev/max-bot/internal/storage"
+
+	"go.uber.org/zap"
+)
+
+const (
+	sessionBucketRegistration = "registration"
+	sessionBucketCustomer     = "customer"
+	sessionBucketTask         = "task"
+	sessionBucketMenu         = "menu"
+	sessionBucketFeedback     = "feedback"
+	sessionBucketNotifyPrefs  = "notify_prefs"
+	sessionBucketNotifyQueue  = "notify_queue"
+	sessionBucketNotifyIndex  = "notify_index"
+	sessionBucketPayouts      = "payouts"
+	sessionBucketPayoutIndex  = "payout_index"
+	sessionBucketAdmin        = "admin"
+	sessionBucketAdminAudit   = "admin_audit"
+	sessionBucketLanguage     = "language"
+	sessionBucketLocaleHint   = "locale_hint"
+
+	defaultSessionTTL = 24 * time.Hour
+)
+
+// sessionTable stores JSON-encoded values of a single kind in one bucket of
+// the configured storage backend. Backend failures are logged and treated as
+// a missing entry so a broken store degrades to "start over" instead of
+// breaking the chat.
+type sessionTable[T any] struct {
+	store  storage.Store
+	bucket string
+	ttl    time.Duration
+	logger *zap.Logger
+}
+
+func newSessionTable[T any](store storage.Store, bucket string, ttl time.Duration, logger *zap.Logger) sessionTable[T] {
+	if ttl <= 0 {
+		ttl = defaultSessionTTL
+	}
+	return sessionTable[T]{store: store, bucket: bucket, ttl: ttl, logger: logger}
+}
+
+// newPersistentTable is a sessionTable whose entries never expire, for
+// settings the user chose rather than conversation state.
+func newPersistentTable[T any](store storage.Store, bucket string, logger *zap.Logger) sessionTable[T] {
+	return sessionTable[T]{store: store, bucket: bucket, logger: logger}
+}
+
+func (t sessionTable[T]) load(id int64) (*T, bool) {
+	data, ok, err := t.store.Get(t.bucket, strconv.FormatInt(id, 10))
+	if err != nil {
+		t.logger.Warn("failed to load session", zap.Error(err), zap.String("bucket", t.bucket), zap.Int64("id", id))
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+
+	value := new(T)
+	if err := json.Unmarshal(data, value); err != nil {
+		t.logger.Warn("failed to decode session", zap.Error(err), zap.String("bucket", t.bucket), zap.Int64("id", id))
+		return nil, false
+	}
+
+	return value, true
+}
+
+func (t sessionTable[T]) save(id int64, value *T) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.logger.Error("failed to encode session", zap.Error(err), zap.String("bucket", t.bucket), zap.Int64("id", id))
+		return
+	}
+
+	if err := t.store.Set(t.bucket, strconv.FormatInt(id, 10), data, t.ttl); err != nil {
+		t.logger.Error("failed to save session", zap.Error(err), zap.String("bucket", t.bucket), zap.Int64("id", id))
+	}
+}
+
+func (t sessionTable[T]) remove(id int64) {
+	if err := t.store.Delete(t.bucket, strconv.FormatInt(id, 10)); err != nil {
+		t.logger.Warn("failed to delete session", zap.Error(err), zap.String("bucket", t.bucket), zap.Int64("id", id))
+	}
+}
+
+// keyIndex is a storage.Index that logs its failures, like sessionTable
+// does, and reads as empty when it cannot be loaded.
+type keyIndex struct {
+	index  storage.Index
+	bucket string
+	logger *zap.Logger
+}
+
+func newKeyIndex(store storage.Store, bucket string, logger *zap.Logger) keyIndex {
+	return keyIndex{index: storage.NewIndex(store, bucket, "pending"), bucket: bucket, logger: logger}
+}
+
+func (x keyIndex) list() []string {
+	keys, err := x.index.List()
+	if err != nil {
+		x.logger.Warn("failed to load index", zap.Error(err), zap.String("bucket", x.bucket))
+		return nil
+	}
+	return keys
+}
+
+// set adds or removes key. Callers serialise their own updates.
+func (x keyIndex) set(key string, present bool) {
+	if err := x.index.Set(key, present); err != nil {
+		x.logger.Error("failed to save index", zap.Error(err), zap.String("bucket", x.bucket))
+	}
+}
@@ -0,0 +1,433 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	taskpb "DobrikaDev/max-bot/internal/generated/taskpb"
+
+	maxbot "github.com/max-messenger/max-bot-api-client-go"
+	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
+	"go.uber.org/zap"
+)
+
+// taskSessionMetaKeys are the meta entries rebuilt from the edit session. Any
+// other entry, volunteer assignments in particular, is carried over as is.
+var taskSessionMetaKeys = map[string]bool{
+	"task_type":       true,
+	"type":            true,
+	"online":          true,
+	"geo_data":        true,
+	"location_label":  true,
+	"reward":          true,
+	"members_planned": true,
+}
+
+// handleCustomerTaskEdit walks the customer through the task creation steps
+// again, prefilled with the stored task. Every step can be kept as is, and
+// the task is only updated after the review step.
+func (h *MessageHandler) handleCustomerTaskEdit(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, taskID string) {
+	h.answerCallback(ctx, callbackQuery.Callback.CallbackID)
+
+	if callbackQuery.Message == nil || taskID == "" {
+		return
+	}
+
+	chatID := callbackQuery.Message.Recipient.ChatId
+	userID := callbackQuery.Callback.User.UserId
+
+	task, err := h.getTaskByID(ctx, taskID)
+	if err != nil || task == nil {
+		h.logger.Error("failed to load task for editing", zap.Error(err), zap.String("task_id", taskID))
+		h.renderMenu(ctx, chatID, userID, h.taskFetchErrorText(), h.customerBackKeyboard())
+		return
+	}
+
+	session := newTaskEditSession(task, userID, chatID)
+	session.MessageID = callbackQuery.Message.Body.Mid
+	h.menus.delete(chatID)
+	h.startTaskCreationFlow(ctx, session)
+}
+
+func newTaskEditSession(task *taskpb.Task, userID, chatID int64) *taskCreationSession {
+	meta := taskMetaMap(task)
+	session := &taskCreationSession{
+		UserID:        userID,
+		ChatID:        chatID,
+		CustomerID:    strings.TrimSpace(task.GetCustomerId()),
+		TaskID:        task.GetId(),
+		Name:          strings.TrimSpace(task.GetName()),
+		Description:   strings.TrimSpace(task.GetDescription()),
+		IsOnline:      isOnlineTask(task),
+		LocationLabel: meta["location_label"],
+		Reward:        int(task.GetCost()),
+		Members:       int(task.GetMembersCount()),
+		Current:       taskStepName,
+	}
+
+	if geo, ok := normalizeGeoCoordinates(meta["geo_data"]); ok {
+		parts := strings.Split(geo, ",")
+		session.Latitude, _ = strconv.ParseFloat(parts[0], 64)
+		session.Longitude, _ = strconv.ParseFloat(parts[1], 64)
+	}
+	if session.Members <= 0 {
+		if planned, err := parsePositiveInt(meta["members_planned"]); err == nil {
+			session.Members = planned
+		}
+	}
+	if session.Members <= 0 {
+		session.Members = 1
+	}
+
+	return session
+}
+
+func (h *MessageHandler) handleTaskEditKeep(ctx context.Context, update *schemes.MessageCallbackUpdate) bool {
+	session, ok := h.taskSessionFromCallback(update)
+	if !ok || !session.isInProgress() || !session.editing() {
+		return false
+	}
+
+	switch session.Current {
+	case taskStepName:
+		session.Current = taskStepDescription
+		h.taskSessions.upsert(session)
+		h.promptTaskDescription(ctx, session)
+	case taskStepDescription:
+		session.Current = taskStepFormat
+		h.taskSessions.upsert(session)
+		h.promptTaskFormat(ctx, session)
+	case taskStepFormat:
+		if session.IsOnline {
+			session.Current = taskStepReward
+			h.taskSessions.upsert(session)
+			h.promptTaskReward(ctx, session)
+		} else {
+			session.Current = taskStepLocation
+			h.taskSessions.upsert(session)
+			h.promptTaskLocation(ctx, session)
+		}
+	case taskStepLocation:
+		session.Current = taskStepReward
+		h.taskSessions.upsert(session)
+		h.promptTaskReward(ctx, session)
+	case taskStepReward:
+		session.Current = taskStepMembers
+		h.taskSessions.upsert(session)
+		h.promptTaskMembers(ctx, session)
+	case taskStepMembers:
+		session.Current = taskStepReview
+		h.taskSessions.upsert(session)
+		h.showTaskReview(ctx, session)
+	default:
+		return false
+	}
+
+	return true
+}
+
+func (h *MessageHandler) handleTaskEditCancel(ctx context.Context, update *schemes.MessageCallbackUpdate) bool {
+	session, ok := h.taskSessionFromCallback(update)
+	if !ok || !session.editing() {
+		return false
+	}
+
+	h.finishTaskEdit(ctx, session, "")
+	return true
+}
+
+func (h *MessageHandler) finalizeTaskEdit(ctx context.Context, session *taskCreationSession) {
+	// Reload the task so assignments made while the customer was editing are
+	// not overwritten.
+	task, err := h.getTaskByID(ctx, session.TaskID)
+	if err == nil && task != nil {
+		err = checkTaskAccess(task, callbackTarget{resource: resourceTask, taskID: session.TaskID}, fmt.Sprintf("%d", session.UserID))
+	}
+	if err != nil || task == nil {
+		h.logger.Error("failed to load task before update", zap.Error(err), zap.String("task_id", session.TaskID), zap.Int64("user_id", session.UserID))
+		h.sendTaskSessionMessage(ctx, session, h.taskEditErrorText(), h.customerBackKeyboard())
+		h.taskSessions.delete(session.UserID)
+		return
+	}
+
+	if session.Members <= 0 {
+		session.Members = 1
+	}
+	if session.Reward < 0 {
+		session.Reward = 0
+	}
+
+	meta := session.taskMeta()
+	for _, item := range task.GetMeta() {
+		if item == nil || taskSessionMetaKeys[strings.ToLower(strings.TrimSpace(item.GetKey()))] {
+			continue
+		}
+		meta = append(meta, item)
+	}
+
+	req := &taskpb.UpdateTaskRequest{
+		Task: &taskpb.Task{
+			Id:               task.GetId(),
+			CustomerId:       task.GetCustomerId(),
+			Name:             strings.TrimSpace(session.Name),
+			Description:      strings.TrimSpace(session.Description),
+			VerificationType: task.GetVerificationType(),
+			Cost:             int32(session.Reward),
+			MembersCount:     int32(session.Members),
+			Meta:             meta,
+			CreatedAt:        task.GetCreatedAt(),
+		},
+	}
+
+	resp, err := h.task.UpdateTask(ctx, req)
+	if err == nil && resp.GetError() != nil {
+		err = fmt.Errorf("task service error: %s", resp.GetError().GetMessage())
+	}
+	if err != nil {
+		h.logger.Error("failed to update task", zap.Error(err), zap.String("task_id", session.TaskID))
+		h.sendTaskSessionMessage(ctx, session, h.taskEditErrorText(), h.customerBackKeyboard())
+		h.taskSessions.delete(session.UserID)
+		return
+	}
+
+	h.finishTaskEdit(ctx, session, h.taskEditSuccessText())
+}
+
+func (h *MessageHandler) finishTaskEdit(ctx context.Context, session *taskCreationSession, intro string) {
+	h.taskSessions.delete(session.UserID)
+	if session.MessageID != "" {
+		h.menus.set(session.ChatID, session.MessageID, session.UserID)
+	}
+	h.showCustomerTaskDetail(ctx, session.ChatID, session.UserID, session.TaskID, intro)
+}
+
+func (h *MessageHandler) handleCustomerTaskCancelAsk(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, taskID string) {
+	h.answerCallback(ctx, callbackQuery.Callback.CallbackID)
+
+	if callbackQuery.Message == nil || taskID == "" {
+		return
+	}
+
+	chatID := callbackQuery.Message.Recipient.ChatId
+	userID := callbackQuery.Callback.User.UserId
+
+	task, err := h.getTaskByID(ctx, taskID)
+	if err != nil || task == nil {
+		h.logger.Error("failed to load task for cancellation", zap.Error(err), zap.String("task_id", taskID))
+		h.renderMenu(ctx, chatID, userID, h.taskFetchErrorText(), h.customerBackKeyboard())
+		return
+	}
+
+	text := fmt.Sprintf(h.taskCancelAskTemplate(), escapeMarkdown(safeTaskName(task.GetName())), len(taskCancelRecipients(task)))
+
+	keyboard := h.api.Messages.NewKeyboardBuilder()
+	keyboard.AddRow().
+		AddCallback(h.taskCancelConfirmButton(), schemes.NEGATIVE, fmt.Sprintf("%s:%s", callbackCustomerTaskCancelConfirm, taskID))
+	keyboard.AddRow().
+		AddCallback(h.taskCancelBackButton(), schemes.DEFAULT, fmt.Sprintf("%s:%s", callbackCustomerTaskView, taskID))
+
+	h.renderMenu(ctx, chatID, userID, text, keyboard)
+}
+
+// handleCustomerTaskCancelConfirm deletes the task and tells everyone who
+// responded to it. The recipients are read before the delete, since the
+// assignments go with the task, but only notified once the delete succeeded.
+func (h *MessageHandler) handleCustomerTaskCancelConfirm(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, taskID string) {
+	h.answerCallback(ctx, callbackQuery.Callback.CallbackID)
+
+	if callbackQuery.Message == nil || taskID == "" {
+		return
+	}
+
+	chatID := callbackQuery.Message.Recipient.ChatId
+	userID := callbackQuery.Callback.User.UserId
+
+	if h.task == nil {
+		h.renderMenu(ctx, chatID, userID, h.taskServiceUnavailableText(), h.customerBackKeyboard())
+		return
+	}
+
+	task, err := h.getTaskByID(ctx, taskID)
+	if err != nil || task == nil {
+		h.logger.Error("failed to load task for cancellation", zap.Error(err), zap.String("task_id", taskID))
+		h.renderMenu(ctx, chatID, userID, h.taskCancelErrorText(), h.customerBackKeyboard())
+		return
+	}
+
+	recipients := taskCancelRecipients(task)
+
+	resp, err := h.task.DeleteTask(ctx, &taskpb.DeleteTaskRequest{Id: taskID})
+	if err == nil && resp.GetError() != nil {
+		err = fmt.Errorf("task service error: %s", resp.GetError().GetMessage())
+	}
+	if err != nil {
+		h.logger.Error("failed to delete task", zap.Error(err), zap.String("task_id", taskID))
+		h.showCustomerTaskDetail(ctx, chatID, userID, taskID, h.taskCancelErrorText())
+		return
+	}
+
+	for _, volunteerID := range recipients {
+		h.notifyTaskVolunteer(ctx, volunteerID, taskID, h.forMaxID(volunteerID).taskCancelledNotification(task.GetName()))
+	}
+
+	h.logger.Info("task cancelled by customer", zap.String("task_id", taskID), zap.Int64("user_id", userID), zap.Int("notified", len(recipients)))
+	h.showCustomerTasksMenu(ctx, chatID, userID, strings.TrimSpace(task.GetCustomerId()), 0, h.taskCancelSuccessText(task.GetName()))
+}
+
+// taskCancelRecipients lists the volunteers who responded to the task and
+// were not turned down.
+func taskCancelRecipients(task *taskpb.Task) []string {
+	var recipients []string
+	for _, assignment := range parseTaskAssignments(task) {
+		if assignment.UserID == "" || isStatusRejected(assignment.Status) {
+			continue
+		}
+		recipients = append(recipients, assignment.UserID)
+	}
+	return recipients
+}
+
+func (h *MessageHandler) notifyTaskVolunteer(ctx context.Context, volunteerID, taskID, text string) {
+	chatID, err := strconv.ParseInt(volunteerID, 10, 64)
+	if err != nil || chatID <= 0 {
+		h.logger.Warn("failed to parse volunteer id for task notification", zap.String("volunteer_id", volunteerID), zap.Error(err))
+		return
+	}
+
+	if _, err := h.sendInteractiveMessage(ctx, chatID, chatID, text, nil); err != nil {
+		h.logger.Error("failed to send task notification", zap.Error(err), zap.Int64("volunteer_id", chatID), zap.String("task_id", taskID))
+	}
+}
+
+// taskStepPrompt adds the current value to a step prompt while editing.
+func (h *MessageHandler) taskStepPrompt(session *taskCreationSession, prompt, current string) string {
+	current = strings.TrimSpace(current)
+	if !session.editing() || current == "" {
+		return prompt
+	}
+	return prompt + "\n\n" + fmt.Sprintf(h.taskEditCurrentTemplate(), current)
+}
+
+// taskStepKeyboard adds the keep button to a step keyboard while editing.
+func (h *MessageHandler) taskStepKeyboard(session *taskCreationSession, keyboard *maxbot.Keyboard) *maxbot.Keyboard {
+	if !session.editing() {
+		return keyboard
+	}
+	if keyboard == nil {
+		keyboard = h.api.Messages.NewKeyboardBuilder()
+	}
+	keyboard.AddRow().
+		AddCallback(h.taskEditKeepButton(), schemes.POSITIVE, callbackTaskEditKeep)
+	keyboard.AddRow().
+		AddCallback(h.taskEditCancelButton(), schemes.NEGATIVE, callbackTaskEditCancel)
+	return keyboard
+}
+
+func (h *MessageHandler) customerTaskEditButton() string {
+	if text := strings.TrimSpace(h.messages.CustomerTaskEditButton); text != "" {
+		return text
+	}
+	return "✏️ Изменить"
+}
+
+func (h *MessageHandler) customerTaskCancelButton() string {
+	if text := strings.TrimSpace(h.messages.CustomerTaskCancelButton); text != "" {
+		return text
+	}
+	return "🗑 Отменить задачу"
+}
+
+func (h *MessageHandler) taskEditCurrentTemplate() string {
+	if text := strings.TrimSpace(h.messages.TaskEditCurrentTemplate); text != "" {
+		return text
+	}
+	return "Сейчас: %s"
+}
+
+func (h *MessageHandler) taskEditKeepButton() string {
+	if text := strings.TrimSpace(h.messages.TaskEditKeepButton); text != "" {
+		return text
+	}
+	return "Оставить как есть"
+}
+
+func (h *MessageHandler) taskEditRewardPromptText() string {
+	if text := strings.TrimSpace(h.messages.TaskEditRewardPrompt); text != "" {
+		return text
+	}
+	return "Сколько добриков получит волонтёр за задачу? Отправь число."
+}
+
+func (h *MessageHandler) taskEditSaveButton() string {
+	if text := strings.TrimSpace(h.messages.TaskEditSaveButton); text != "" {
+		return text
+	}
+	return "💾 Сохранить изменения"
+}
+
+func (h *MessageHandler) taskEditCancelButton() string {
+	if text := strings.TrimSpace(h.messages.TaskEditCancelButton); text != "" {
+		return text
+	}
+	return "Отменить редактирование"
+}
+
+func (h *MessageHandler) taskEditSuccessText() string {
+	if text := strings.TrimSpace(h.messages.TaskEditSuccessText); text != "" {
+		return text
+	}
+	return "Изменения сохранены ✅"
+}
+
+func (h *MessageHandler) taskEditErrorText() string {
+	if text := strings.TrimSpace(h.messages.TaskEditErrorText); text != "" {
+		return text
+	}
+	return "Не удалось сохранить изменения. Попробуйте позже."
+}
+
+func (h *MessageHandler) taskCancelAskTemplate() string {
+	if text := strings.TrimSpace(h.messages.TaskCancelAskTemplate); text != "" {
+		return text
+	}
+	return "Отменить задачу «%s»?\n\nОткликнувшихся волонтёров: %d. Мы сообщим им об отмене. Вернуть задачу потом не получится."
+}
+
+func (h *MessageHandler) taskCancelConfirmButton() string {
+	if text := strings.TrimSpace(h.messages.TaskCancelConfirmButton); text != "" {
+		return text
+	}
+	return "Да, отменить задачу"
+}
+
+func (h *MessageHandler) taskCancelBackButton() string {
+	if text := strings.TrimSpace(h.messages.TaskCancelBackButton); text != "" {
+		return text
+	}
+	return "⬅️ Не отменять"
+}
+
+func (h *MessageHandler) taskCancelSuccessText(name string) string {
+	template := strings.TrimSpace(h.messages.TaskCancelSuccessText)
+	if template == "" {
+		template = "Задача «%s» отменена. Откликнувшиеся волонтёры получили уведомление."
+	}
+	return fmt.Sprintf(template, escapeMarkdown(safeTaskName(name)))
+}
+
+func (h *MessageHandler) taskCancelErrorText() string {
+	if text := strings.TrimSpace(h.messages.TaskCancelErrorText); text != "" {
+		return text
+	}
+	return "Не удалось отменить задачу. Попробуйте позже."
+}
+
+func (h *MessageHandler) taskCancelledNotification(name string) string {
+	template := strings.TrimSpace(h.messages.TaskCancelledNotification)
+	if template == "" {
+		template = "Заказчик отменил задачу «%s». Спасибо, что откликнулся — загляни в список, там есть и другие добрые дела 💚"
+	}
+	return fmt.Sprintf(template, escapeMarkdown(safeTaskName(name)))
+}
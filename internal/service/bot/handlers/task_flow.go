@@ -8,11 +8,11 @@ import (
 	"sort"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	taskpb "DobrikaDev/max-bot/internal/generated/taskpb"
 	userpb "DobrikaDev/max-bot/internal/generated/userpb"
+	"DobrikaDev/max-bot/internal/storage"
 
 	maxbot "github.com/max-messenger/max-bot-api-client-go"
 	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
@@ -73,6 +73,7 @@ type taskCreationSession struct {
 	ChatID        int64
 	MessageID     string
 	CustomerID    string
+	TaskID        string
 	Name          string
 	Description   string
 	IsOnline      bool
@@ -88,9 +89,14 @@ func (s *taskCreationSession) isInProgress() bool {
 	return s != nil && s.Current != taskStepNone && s.Current != taskStepComplete
 }
 
+// editing reports whether the session changes an existing task rather than
+// creating a new one.
+func (s *taskCreationSession) editing() bool {
+	return s != nil && s.TaskID != ""
+}
+
 type taskSessionStore struct {
-	mu       sync.RWMutex
-	sessions map[int64]*taskCreationSession
+	table sessionTable[taskCreationSession]
 }
 
 type taskAssignment struct {
@@ -103,30 +109,20 @@ type taskAssignmentJSON struct {
 	Status string `json:"status"`
 }
 
-func newTaskSessionStore() *taskSessionStore {
-	return &taskSessionStore{sessions: make(map[int64]*taskCreationSession)}
+func newTaskSessionStore(store storage.Store, ttl time.Duration, logger *zap.Logger) *taskSessionStore {
+	return &taskSessionStore{table: newSessionTable[taskCreationSession](store, sessionBucketTask, ttl, logger)}
 }
 
 func (s *taskSessionStore) get(userID int64) (*taskCreationSession, bool) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	session, ok := s.sessions[userID]
-	return session, ok
+	return s.table.load(userID)
 }
 
 func (s *taskSessionStore) upsert(session *taskCreationSession) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	s.sessions[session.UserID] = session
+	s.table.save(session.UserID, session)
 }
 
 func (s *taskSessionStore) delete(userID int64) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	delete(s.sessions, userID)
+	s.table.remove(userID)
 }
 
 func (h *MessageHandler) tryHandleTaskCreationMessage(ctx context.Context, update *schemes.MessageCreatedUpdate) bool {
@@ -140,7 +136,7 @@ func (h *MessageHandler) tryHandleTaskCreationMessage(ctx context.Context, updat
 	switch session.Current {
 	case taskStepName:
 		if text == "" {
-			h.sendTaskSessionMessage(ctx, session, h.taskCreateNameRetryText(), emptyKeyboard())
+			h.sendTaskSessionMessage(ctx, session, h.taskCreateNameRetryText(), h.taskStepKeyboard(session, emptyKeyboard()))
 			return true
 		}
 		session.Name = text
@@ -149,7 +145,7 @@ func (h *MessageHandler) tryHandleTaskCreationMessage(ctx context.Context, updat
 		h.promptTaskDescription(ctx, session)
 	case taskStepDescription:
 		if text == "" {
-			h.sendTaskSessionMessage(ctx, session, h.taskCreateDescriptionRetryText(), emptyKeyboard())
+			h.sendTaskSessionMessage(ctx, session, h.taskCreateDescriptionRetryText(), h.taskStepKeyboard(session, emptyKeyboard()))
 			return true
 		}
 		session.Description = text
@@ -165,10 +161,17 @@ func (h *MessageHandler) tryHandleTaskCreationMessage(ctx context.Context, updat
 			h.taskSessions.upsert(session)
 			h.promptTaskReward(ctx, session)
 		} else {
-			h.sendTaskSessionMessage(ctx, session, h.taskCreateLocationRetryText(), h.taskCreateLocationKeyboard())
+			h.sendTaskSessionMessage(ctx, session, h.taskCreateLocationRetryText(), h.taskStepKeyboard(session, h.taskCreateLocationKeyboard()))
 		}
 	case taskStepReward:
-		session.Reward = h.defaultTaskReward()
+		if !session.editing() {
+			session.Reward = h.defaultTaskReward()
+		} else if reward, err := parsePositiveInt(text); err == nil {
+			session.Reward = reward
+		} else {
+			h.sendTaskSessionMessage(ctx, session, h.taskCreateRewardRetryText(), h.taskStepKeyboard(session, emptyKeyboard()))
+			return true
+		}
 		session.Current = taskStepMembers
 		h.taskSessions.upsert(session)
 		h.promptTaskMembers(ctx, session)
@@ -179,7 +182,7 @@ func (h *MessageHandler) tryHandleTaskCreationMessage(ctx context.Context, updat
 			h.taskSessions.upsert(session)
 			h.showTaskReview(ctx, session)
 		} else {
-			h.sendTaskSessionMessage(ctx, session, h.taskCreateMembersRetryText(), h.taskCreateMembersKeyboard())
+			h.sendTaskSessionMessage(ctx, session, h.taskCreateMembersRetryText(), h.taskStepKeyboard(session, h.taskCreateMembersKeyboard()))
 		}
 	default:
 		h.logger.Debug("task creation message in unexpected step", zap.Int("step", int(session.Current)))
@@ -188,38 +191,6 @@ func (h *MessageHandler) tryHandleTaskCreationMessage(ctx context.Context, updat
 	return true
 }
 
-func (h *MessageHandler) tryHandleTaskCreationCallback(ctx context.Context, update *schemes.MessageCallbackUpdate) bool {
-	payload := update.Callback.Payload
-	if payload == "" {
-		return false
-	}
-
-	var handled bool
-
-	switch payload {
-	case callbackTaskCreateModeOnline:
-		handled = h.handleTaskCreateMode(ctx, update, true)
-	case callbackTaskCreateModeOffline:
-		handled = h.handleTaskCreateMode(ctx, update, false)
-	case callbackTaskCreateSkipLocation:
-		handled = h.handleTaskCreateSkipLocation(ctx, update)
-	case callbackTaskCreateSkipMembers:
-		handled = h.handleTaskCreateSkipMembers(ctx, update)
-	case callbackTaskCreateConfirm:
-		handled = h.handleTaskCreateConfirm(ctx, update)
-	case callbackTaskCreateRestart:
-		handled = h.handleTaskCreateRestart(ctx, update)
-	default:
-		return false
-	}
-
-	if handled {
-		h.answerCallback(ctx, update.Callback.CallbackID)
-	}
-
-	return handled
-}
-
 func (h *MessageHandler) taskSessionFromCallback(update *schemes.MessageCallbackUpdate) (*taskCreationSession, bool) {
 	if update == nil {
 		return nil, false
@@ -358,23 +329,17 @@ func (h *MessageHandler) handleCustomerManageTasks(ctx context.Context, update *
 	h.showCustomerTasksMenu(ctx, chatID, userID, strings.TrimSpace(customer.GetMaxId()), 0)
 }
 
-func (h *MessageHandler) handleCustomerTasksPage(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, payload string) {
+func (h *MessageHandler) handleCustomerTasksPage(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, customerID, pageValue string) {
 	h.answerCallback(ctx, callbackQuery.Callback.CallbackID)
 
 	if callbackQuery.Message == nil {
 		return
 	}
 
-	parts := strings.Split(payload, ":")
-	if len(parts) != 2 {
-		h.logger.Debug("invalid customer tasks page payload", zap.String("payload", payload))
-		return
-	}
-
-	customerID := strings.TrimSpace(parts[0])
-	page, err := strconv.Atoi(parts[1])
+	customerID = strings.TrimSpace(customerID)
+	page, err := strconv.Atoi(pageValue)
 	if err != nil {
-		h.logger.Warn("failed to parse customer tasks page", zap.Error(err), zap.String("payload", payload))
+		h.logger.Warn("failed to parse customer tasks page", zap.Error(err), zap.String("page", pageValue))
 		page = 0
 	}
 	if page < 0 {
@@ -436,11 +401,11 @@ func (h *MessageHandler) handleCustomerManageCreateTask(ctx context.Context, upd
 
 func (h *MessageHandler) startTaskCreationFlow(ctx context.Context, session *taskCreationSession) {
 	h.taskSessions.upsert(session)
-	h.sendTaskSessionMessage(ctx, session, h.taskCreateNamePromptText(), emptyKeyboard())
+	h.sendTaskSessionMessage(ctx, session, h.taskStepPrompt(session, h.taskCreateNamePromptText(), escapeMarkdown(session.Name)), h.taskStepKeyboard(session, emptyKeyboard()))
 }
 
 func (h *MessageHandler) promptTaskDescription(ctx context.Context, session *taskCreationSession) {
-	h.sendTaskSessionMessage(ctx, session, h.taskCreateDescriptionPromptText(), emptyKeyboard())
+	h.sendTaskSessionMessage(ctx, session, h.taskStepPrompt(session, h.taskCreateDescriptionPromptText(), escapeMarkdown(session.Description)), h.taskStepKeyboard(session, emptyKeyboard()))
 }
 
 func (h *MessageHandler) promptTaskFormat(ctx context.Context, session *taskCreationSession) {
@@ -449,14 +414,21 @@ func (h *MessageHandler) promptTaskFormat(ctx context.Context, session *taskCrea
 		AddCallback(h.taskCreateFormatOfflineButton(), schemes.DEFAULT, callbackTaskCreateModeOffline).
 		AddCallback(h.taskCreateFormatOnlineButton(), schemes.DEFAULT, callbackTaskCreateModeOnline)
 
-	h.sendTaskSessionMessage(ctx, session, h.taskCreateFormatPromptText(), keyboard)
+	h.sendTaskSessionMessage(ctx, session, h.taskStepPrompt(session, h.taskCreateFormatPromptText(), h.taskSessionFormatLabel(session)), h.taskStepKeyboard(session, keyboard))
 }
 
 func (h *MessageHandler) promptTaskLocation(ctx context.Context, session *taskCreationSession) {
-	h.sendTaskSessionMessage(ctx, session, h.taskCreateLocationPromptText(), h.taskCreateLocationKeyboard())
+	h.sendTaskSessionMessage(ctx, session, h.taskStepPrompt(session, h.taskCreateLocationPromptText(), h.taskSessionLocationLabel(session)), h.taskStepKeyboard(session, h.taskCreateLocationKeyboard()))
 }
 
+// promptTaskReward asks for the reward only when editing; new tasks get the
+// default reward and move straight on to the members step.
 func (h *MessageHandler) promptTaskReward(ctx context.Context, session *taskCreationSession) {
+	if session.editing() {
+		h.sendTaskSessionMessage(ctx, session, h.taskStepPrompt(session, h.taskEditRewardPromptText(), h.taskSessionRewardLabel(session)), h.taskStepKeyboard(session, emptyKeyboard()))
+		return
+	}
+
 	session.Reward = h.defaultTaskReward()
 	session.Current = taskStepMembers
 	h.taskSessions.upsert(session)
@@ -464,11 +436,11 @@ func (h *MessageHandler) promptTaskReward(ctx context.Context, session *taskCrea
 }
 
 func (h *MessageHandler) promptTaskMembers(ctx context.Context, session *taskCreationSession) {
-	h.sendTaskSessionMessage(ctx, session, h.taskCreateMembersPromptText(), h.taskCreateMembersKeyboard())
+	h.sendTaskSessionMessage(ctx, session, h.taskStepPrompt(session, h.taskCreateMembersPromptText(), strconv.Itoa(max(session.Members, 1))), h.taskStepKeyboard(session, h.taskCreateMembersKeyboard()))
 }
 
 func (h *MessageHandler) showTaskReview(ctx context.Context, session *taskCreationSession) {
-	h.sendTaskSessionMessage(ctx, session, h.taskCreateReviewText(session), h.taskCreateReviewKeyboard())
+	h.sendTaskSessionMessage(ctx, session, h.taskCreateReviewText(session), h.taskCreateReviewKeyboard(session))
 }
 
 func (h *MessageHandler) finalizeTaskCreation(ctx context.Context, session *taskCreationSession) {
@@ -478,29 +450,12 @@ func (h *MessageHandler) finalizeTaskCreation(ctx context.Context, session *task
 		return
 	}
 
-	meta := make([]*taskpb.Meta, 0, 4)
-	taskType := "TT_OfflineTask"
-	if session.IsOnline {
-		taskType = "TT_OnlineTask"
-	}
-	meta = append(meta, &taskpb.Meta{Key: "task_type", Value: taskType})
-
-	if !session.IsOnline {
-		if geo := session.geoData(); geo != "" {
-			meta = append(meta, &taskpb.Meta{Key: "geo_data", Value: geo})
-		}
-		if label := strings.TrimSpace(session.LocationLabel); label != "" {
-			meta = append(meta, &taskpb.Meta{Key: "location_label", Value: label})
-		}
-	}
-
-	if session.Reward > 0 {
-		meta = append(meta, &taskpb.Meta{Key: "reward", Value: strconv.Itoa(session.Reward)})
+	if session.editing() {
+		h.finalizeTaskEdit(ctx, session)
+		return
 	}
 
-	if session.Members > 0 {
-		meta = append(meta, &taskpb.Meta{Key: "members_planned", Value: strconv.Itoa(session.Members)})
-	}
+	meta := session.taskMeta()
 
 	if session.Members <= 0 {
 		session.Members = 1
@@ -642,16 +597,12 @@ func (h *MessageHandler) buildCustomerTasksView(ctx context.Context, customerID
 		}
 
 		if total > taskListPageSize {
-			footerTemplate := strings.TrimSpace(h.messages.CustomerTasksPageFooter)
-			if footerTemplate == "" {
-				footerTemplate = "Страница %d из %d"
-			}
 			totalPages := 1
 			if total > 0 {
 				totalPages = (total + taskListPageSize - 1) / taskListPageSize
 			}
 			builder.WriteString("\n")
-			builder.WriteString(fmt.Sprintf(footerTemplate, page+1, totalPages))
+			builder.WriteString(h.pageFooter(h.messages.CustomerTasksPageFooter, page, totalPages))
 			builder.WriteString("\n")
 		}
 	}
@@ -834,16 +785,12 @@ func (h *MessageHandler) buildVolunteerOnDemandView(ctx context.Context, userID
 	}
 
 	if total > taskListPageSize {
-		footer := strings.TrimSpace(h.messages.VolunteerTasksPageFooter)
-		if footer == "" {
-			footer = "Страница %d из %d"
-		}
 		totalPages := (total + taskListPageSize - 1) / taskListPageSize
 		if totalPages < 1 {
 			totalPages = 1
 		}
 		builder.WriteString("\n")
-		builder.WriteString(fmt.Sprintf(footer, page+1, totalPages))
+		builder.WriteString(h.pageFooter(h.messages.VolunteerTasksPageFooter, page, totalPages))
 		builder.WriteString("\n")
 	}
 
@@ -940,7 +887,8 @@ func (h *MessageHandler) buildVolunteerGeoTasksView(ctx context.Context, userID
 		end = total
 	}
 
-	builder.WriteString(fmt.Sprintf("Найдено дел рядом: %d\n", total))
+	builder.WriteString(h.volunteerTasksNearbyFoundText(total))
+	builder.WriteString("\n")
 	if filter != volunteerTasksFilterAll {
 		builder.WriteString(fmt.Sprintf("Фильтр: %s\n", h.currentFilterLabel(filter)))
 	}
@@ -949,11 +897,12 @@ func (h *MessageHandler) buildVolunteerGeoTasksView(ctx context.Context, userID
 	}
 	builder.WriteString("\nВыбери дело ниже:\n\n")
 
+	coefficient := h.rewardCoefficient(ctx, fmt.Sprintf("%d", userID))
 	for idx, entry := range filtered[start:end] {
 		if idx > 0 {
 			builder.WriteString("\n")
 		}
-		builder.WriteString(h.volunteerTaskListItemText(entry, start+idx+1))
+		builder.WriteString(h.volunteerTaskListItemText(entry, start+idx+1, coefficient))
 		builder.WriteString("\n")
 	}
 	builder.WriteString("\n")
@@ -974,11 +923,7 @@ func (h *MessageHandler) buildVolunteerGeoTasksView(ctx context.Context, userID
 
 	if totalPages > 1 {
 		builder.WriteString("\n")
-		footerTemplate := strings.TrimSpace(h.messages.VolunteerTasksPageFooter)
-		if footerTemplate == "" {
-			footerTemplate = "Страница %d из %d"
-		}
-		builder.WriteString(fmt.Sprintf(footerTemplate, page+1, totalPages))
+		builder.WriteString(h.pageFooter(h.messages.VolunteerTasksPageFooter, page, totalPages))
 		builder.WriteString("\n")
 
 		row := keyboard.AddRow()
@@ -1282,11 +1227,13 @@ func (h *MessageHandler) volunteerLocationRequestKeyboard() *maxbot.Keyboard {
 	return keyboard
 }
 
-func (h *MessageHandler) volunteerTaskListItemText(entry volunteerTaskDisplayEntry, number int) string {
+// volunteerTaskListItemText renders a task card. The reward is shown as the
+// volunteer would receive it, multiplied by the coefficient of their level.
+func (h *MessageHandler) volunteerTaskListItemText(entry volunteerTaskDisplayEntry, number int, coefficient float64) string {
 	var builder strings.Builder
 
-	name := safeTaskName(entry.task.GetName())
-	desc := safeTaskDescription(entry.task.GetDescription())
+	name := escapeMarkdown(safeTaskName(entry.task.GetName()))
+	desc := escapeMarkdown(safeTaskDescription(entry.task.GetDescription()))
 
 	builder.WriteString(fmt.Sprintf("*%d. %s*", number, name))
 	builder.WriteString("\n")
@@ -1304,7 +1251,7 @@ func (h *MessageHandler) volunteerTaskListItemText(entry volunteerTaskDisplayEnt
 	if entry.online {
 		locationText = h.taskCreateFormatOnlineLabel()
 	} else if label := strings.TrimSpace(meta["location_label"]); label != "" {
-		locationText = label
+		locationText = escapeMarkdown(label)
 	} else if geo := strings.TrimSpace(meta["geo_data"]); geo != "" {
 		locationText = fmt.Sprintf("%s (%s)", h.taskCreateLocationFallbackLabel(), geo)
 	}
@@ -1315,7 +1262,9 @@ func (h *MessageHandler) volunteerTaskListItemText(entry volunteerTaskDisplayEnt
 
 	rewardAmount := entry.task.GetCost()
 	builder.WriteString("\n")
-	if rewardAmount > 0 {
+	if rewardAmount > 0 && coefficient > 0 && coefficient != 1 {
+		builder.WriteString(h.volunteerTasksListItemBoostedReward(expectedReward(rewardAmount, coefficient), coefficient))
+	} else if rewardAmount > 0 {
 		builder.WriteString(h.volunteerTasksListItemReward(rewardAmount))
 	} else {
 		builder.WriteString(h.volunteerTasksListItemNoReward())
@@ -1567,8 +1516,16 @@ func (h *MessageHandler) taskCreateLocationFallbackLabel() string {
 	return "точка на карте"
 }
 
-func (h *MessageHandler) taskCreateReviewKeyboard() *maxbot.Keyboard {
+func (h *MessageHandler) taskCreateReviewKeyboard(session *taskCreationSession) *maxbot.Keyboard {
 	keyboard := h.api.Messages.NewKeyboardBuilder()
+	if session.editing() {
+		keyboard.AddRow().
+			AddCallback(h.taskEditSaveButton(), schemes.POSITIVE, callbackTaskCreateConfirm)
+		keyboard.AddRow().
+			AddCallback(h.taskEditCancelButton(), schemes.NEGATIVE, callbackTaskEditCancel)
+		return keyboard
+	}
+
 	keyboard.AddRow().
 		AddCallback(h.taskCreateReviewConfirmButton(), schemes.POSITIVE, callbackTaskCreateConfirm)
 	keyboard.AddRow().
@@ -1598,24 +1555,9 @@ func (h *MessageHandler) taskCreateMembersKeyboard() *maxbot.Keyboard {
 }
 
 func (h *MessageHandler) taskCreateReviewText(session *taskCreationSession) string {
-	formatLabel := h.taskCreateFormatOfflineLabel()
-	if session.IsOnline {
-		formatLabel = h.taskCreateFormatOnlineLabel()
-	}
-
-	locationText := h.taskCreateLocationFallbackLabel()
-	if session.IsOnline {
-		locationText = h.taskCreateFormatOnlineLabel()
-	} else if label := strings.TrimSpace(session.LocationLabel); label != "" {
-		locationText = label
-	} else if geo := session.geoData(); geo != "" {
-		locationText = fmt.Sprintf("%s (%s)", h.taskCreateLocationFallbackLabel(), geo)
-	}
-
-	rewardText := h.taskCreateReviewNoRewardText()
-	if session.Reward > 0 {
-		rewardText = fmt.Sprintf("%d добриков", session.Reward)
-	}
+	formatLabel := h.taskSessionFormatLabel(session)
+	locationText := h.taskSessionLocationLabel(session)
+	rewardText := h.taskSessionRewardLabel(session)
 
 	members := session.Members
 	if members <= 0 {
@@ -1624,8 +1566,8 @@ func (h *MessageHandler) taskCreateReviewText(session *taskCreationSession) stri
 
 	template := h.taskCreateReviewTemplate()
 	return fmt.Sprintf(template,
-		strings.TrimSpace(session.Name),
-		strings.TrimSpace(session.Description),
+		escapeMarkdown(strings.TrimSpace(session.Name)),
+		escapeMarkdown(strings.TrimSpace(session.Description)),
 		formatLabel,
 		locationText,
 		rewardText,
@@ -1633,6 +1575,33 @@ func (h *MessageHandler) taskCreateReviewText(session *taskCreationSession) stri
 	)
 }
 
+func (h *MessageHandler) taskSessionFormatLabel(session *taskCreationSession) string {
+	if session.IsOnline {
+		return h.taskCreateFormatOnlineLabel()
+	}
+	return h.taskCreateFormatOfflineLabel()
+}
+
+func (h *MessageHandler) taskSessionLocationLabel(session *taskCreationSession) string {
+	if session.IsOnline {
+		return h.taskCreateFormatOnlineLabel()
+	}
+	if label := strings.TrimSpace(session.LocationLabel); label != "" {
+		return escapeMarkdown(label)
+	}
+	if geo := session.geoData(); geo != "" {
+		return fmt.Sprintf("%s (%s)", h.taskCreateLocationFallbackLabel(), geo)
+	}
+	return h.taskCreateLocationFallbackLabel()
+}
+
+func (h *MessageHandler) taskSessionRewardLabel(session *taskCreationSession) string {
+	if session.Reward > 0 {
+		return h.render(h.messages.TaskCreateReviewRewardText, defaultRewardAmountText, rewardData{Amount: int32(session.Reward)})
+	}
+	return h.taskCreateReviewNoRewardText()
+}
+
 func parsePositiveInt(text string) (int, error) {
 	text = strings.TrimSpace(text)
 	if text == "" {
@@ -1669,11 +1638,13 @@ func (h *MessageHandler) volunteerTasksListItemLocation(location string) string
 }
 
 func (h *MessageHandler) volunteerTasksListItemReward(amount int32) string {
-	template := strings.TrimSpace(h.messages.VolunteerTasksListItemReward)
-	if template == "" {
-		template = "Награда: %d добриков"
-	}
-	return fmt.Sprintf(template, amount)
+	return h.render(h.messages.VolunteerTasksListItemReward, "Награда: "+defaultRewardAmountText, rewardData{Amount: amount})
+}
+
+func (h *MessageHandler) volunteerTasksListItemBoostedReward(amount int32, coefficient float64) string {
+	return h.render(h.messages.VolunteerTasksListItemBoostedReward,
+		"Награда: "+defaultRewardAmountText+" (×{{.Coefficient}} за твой уровень)",
+		rewardData{Amount: amount, Coefficient: formatCoefficient(coefficient)})
 }
 
 func (h *MessageHandler) volunteerTasksListItemNoReward() string {
@@ -1684,17 +1655,20 @@ func (h *MessageHandler) volunteerTasksListItemNoReward() string {
 }
 
 func (h *MessageHandler) volunteerTasksListItemVolunteers(count int32) string {
-	if count <= 1 {
-		if text := strings.TrimSpace(h.messages.VolunteerTasksListItemVolunteersOne); text != "" {
-			return text
-		}
-		return "Нужен 1 волонтёр"
-	}
-	template := strings.TrimSpace(h.messages.VolunteerTasksListItemVolunteersMany)
-	if template == "" {
-		template = "Нужно волонтёров: %d"
+	return h.render(h.messages.VolunteerTasksListItemVolunteers, defaultVolunteersNeededText, countData{Count: volunteersNeeded(count)})
+}
+
+// volunteersNeeded reads a task's member count; tasks saved without one
+// need a single volunteer.
+func volunteersNeeded(members int32) int {
+	if members < 1 {
+		return 1
 	}
-	return fmt.Sprintf(template, count)
+	return int(members)
+}
+
+func (h *MessageHandler) volunteerTasksNearbyFoundText(total int) string {
+	return h.render(h.messages.VolunteerTasksNearbyFoundText, "Рядом нашлось {{number .Count}} {{plural .Count \"дело\" \"дела\" \"дел\"}}", countData{Count: total})
 }
 
 func (h *MessageHandler) volunteerTaskAssignmentsEmptyText() string {
@@ -1714,6 +1688,36 @@ func (s *taskCreationSession) geoData() string {
 	return fmt.Sprintf("%.6f,%.6f", s.Latitude, s.Longitude)
 }
 
+// taskMeta describes the format, location, reward and planned members the
+// way the task service stores them.
+func (s *taskCreationSession) taskMeta() []*taskpb.Meta {
+	meta := make([]*taskpb.Meta, 0, 4)
+	taskType := "TT_OfflineTask"
+	if s.IsOnline {
+		taskType = "TT_OnlineTask"
+	}
+	meta = append(meta, &taskpb.Meta{Key: "task_type", Value: taskType})
+
+	if !s.IsOnline {
+		if geo := s.geoData(); geo != "" {
+			meta = append(meta, &taskpb.Meta{Key: "geo_data", Value: geo})
+		}
+		if label := strings.TrimSpace(s.LocationLabel); label != "" {
+			meta = append(meta, &taskpb.Meta{Key: "location_label", Value: label})
+		}
+	}
+
+	if s.Reward > 0 {
+		meta = append(meta, &taskpb.Meta{Key: "reward", Value: strconv.Itoa(s.Reward)})
+	}
+
+	if s.Members > 0 {
+		meta = append(meta, &taskpb.Meta{Key: "members_planned", Value: strconv.Itoa(s.Members)})
+	}
+
+	return meta
+}
+
 func taskMetaMap(task *taskpb.Task) map[string]string {
 	result := make(map[string]string)
 	if task == nil {
@@ -1738,11 +1742,11 @@ func taskMetaMap(task *taskpb.Task) map[string]string {
 func (h *MessageHandler) taskCreateSuccessText(name string) string {
 	if text := strings.TrimSpace(h.messages.TaskCreateSuccessText); text != "" {
 		if strings.Contains(text, "%s") {
-			return fmt.Sprintf(text, strings.TrimSpace(name))
+			return fmt.Sprintf(text, escapeMarkdown(strings.TrimSpace(name)))
 		}
 		return text
 	}
-	return fmt.Sprintf("Доброе дело «%s» создано 💚", strings.TrimSpace(name))
+	return fmt.Sprintf("Доброе дело «%s» создано 💚", escapeMarkdown(strings.TrimSpace(name)))
 }
 
 func (h *MessageHandler) taskCreateErrorText() string {
@@ -1802,44 +1806,15 @@ func (h *MessageHandler) customerTaskRewardDescription(taskName string) string {
 }
 
 func (h *MessageHandler) customerTaskApproveSuccessText(taskName string, amount int32) string {
-	name := strings.TrimSpace(taskName)
-	text := strings.TrimSpace(h.messages.CustomerTaskApproveSuccessText)
-	if text == "" {
-		if amount > 0 {
-			return fmt.Sprintf("Выполнение задачи подтверждено 💚\nНаграда %d добриков отправлена исполнителю.", amount)
-		}
-		return "Выполнение задачи подтверждено 💚"
-	}
-
-	switch {
-	case strings.Contains(text, "%d") && strings.Contains(text, "%s"):
-		return fmt.Sprintf(text, amount, name)
-	case strings.Contains(text, "%d"):
-		return fmt.Sprintf(text, amount)
-	case strings.Contains(text, "%s"):
-		return fmt.Sprintf(text, name)
-	default:
-		return text
-	}
+	return h.render(h.messages.CustomerTaskApproveSuccessText,
+		"Выполнение задачи подтверждено 💚\nВолонтёр получил "+defaultRewardAmountText+" за задачу «{{.TaskName}}».",
+		rewardData{TaskName: escapeMarkdown(strings.TrimSpace(taskName)), Amount: amount})
 }
 
 func (h *MessageHandler) volunteerTaskRewardNotification(taskName string, amount int32) string {
-	name := strings.TrimSpace(taskName)
-	text := strings.TrimSpace(h.messages.VolunteerTaskRewardNotification)
-	if text == "" {
-		return fmt.Sprintf("Ты получил(а) %d добриков за доброе дело «%s» 💚", amount, name)
-	}
-
-	switch {
-	case strings.Contains(text, "%d") && strings.Contains(text, "%s"):
-		return fmt.Sprintf(text, amount, name)
-	case strings.Contains(text, "%d"):
-		return fmt.Sprintf(text, amount)
-	case strings.Contains(text, "%s"):
-		return fmt.Sprintf(text, name)
-	default:
-		return text
-	}
+	return h.render(h.messages.VolunteerTaskRewardNotification,
+		"Ты получил(а) "+defaultRewardAmountText+" за доброе дело «{{.TaskName}}» 💚",
+		rewardData{TaskName: escapeMarkdown(strings.TrimSpace(taskName)), Amount: amount})
 }
 
 func (h *MessageHandler) volunteerTasksUnavailableText() string {
@@ -1877,35 +1852,25 @@ func (h *MessageHandler) volunteerTaskItemTemplate() string {
 	return "• *%s*\n%s"
 }
 
-func (h *MessageHandler) handleVolunteerTasksPage(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, payload string) {
+func (h *MessageHandler) handleVolunteerTasksPage(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, modeValue, filterValue, pageValue string) {
 	h.answerCallback(ctx, callbackQuery.Callback.CallbackID)
 
 	if callbackQuery.Message == nil {
 		return
 	}
 
-	parts := strings.Split(payload, ":")
-	if len(parts) < 2 {
-		h.logger.Debug("invalid volunteer tasks page payload", zap.String("payload", payload))
-		return
-	}
-
-	mode := volunteerTasksViewMode(strings.TrimSpace(parts[0]))
+	mode := volunteerTasksViewMode(strings.TrimSpace(modeValue))
 	switch mode {
 	case volunteerTasksViewModeAll, volunteerTasksViewModeOnDemand:
 	default:
 		mode = volunteerTasksViewModeAll
 	}
 
-	filter := volunteerTasksFilterAll
-	pagePart := parts[len(parts)-1]
-	if len(parts) >= 3 {
-		filter = parseVolunteerTasksFilter(parts[1])
-	}
+	filter := parseVolunteerTasksFilter(filterValue)
 
-	page, err := strconv.Atoi(pagePart)
+	page, err := strconv.Atoi(pageValue)
 	if err != nil {
-		h.logger.Warn("failed to parse volunteer tasks page", zap.Error(err), zap.String("payload", payload))
+		h.logger.Warn("failed to parse volunteer tasks page", zap.Error(err), zap.String("page", pageValue))
 		page = 0
 	}
 	if page < 0 {
@@ -1918,27 +1883,21 @@ func (h *MessageHandler) handleVolunteerTasksPage(ctx context.Context, callbackQ
 	h.showVolunteerTasksList(ctx, chatID, userID, mode, filter, "", page)
 }
 
-func (h *MessageHandler) handleVolunteerTasksFilter(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, payload string) {
+func (h *MessageHandler) handleVolunteerTasksFilter(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, modeValue, filterValue string) {
 	h.answerCallback(ctx, callbackQuery.Callback.CallbackID)
 
 	if callbackQuery.Message == nil {
 		return
 	}
 
-	parts := strings.Split(payload, ":")
-	if len(parts) != 2 {
-		h.logger.Debug("invalid volunteer tasks filter payload", zap.String("payload", payload))
-		return
-	}
-
-	mode := volunteerTasksViewMode(strings.TrimSpace(parts[0]))
+	mode := volunteerTasksViewMode(strings.TrimSpace(modeValue))
 	switch mode {
 	case volunteerTasksViewModeAll, volunteerTasksViewModeOnDemand:
 	default:
 		mode = volunteerTasksViewModeAll
 	}
 
-	filter := parseVolunteerTasksFilter(parts[1])
+	filter := parseVolunteerTasksFilter(filterValue)
 
 	chatID := callbackQuery.Message.Recipient.ChatId
 	userID := callbackQuery.Callback.User.UserId
@@ -1985,6 +1944,7 @@ func (h *MessageHandler) handleVolunteerTaskJoin(ctx context.Context, callbackQu
 	}
 
 	h.showVolunteerTaskDetail(ctx, chatID, callbackQuery.Callback.User.UserId, taskID, h.messages.VolunteerTaskJoinSuccessText)
+	h.notifyTaskOwner(ctx, taskID, callbackQuery.Callback.User.UserId, taskEventJoined)
 }
 
 func (h *MessageHandler) handleVolunteerTaskLeave(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, taskID string) {
@@ -2009,6 +1969,7 @@ func (h *MessageHandler) handleVolunteerTaskLeave(ctx context.Context, callbackQ
 	}
 
 	h.showVolunteerTaskDetail(ctx, chatID, callbackQuery.Callback.User.UserId, taskID, h.messages.VolunteerTaskLeaveSuccessText)
+	h.notifyTaskOwner(ctx, taskID, callbackQuery.Callback.User.UserId, taskEventLeft)
 }
 
 func (h *MessageHandler) handleVolunteerTaskConfirm(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, taskID string) {
@@ -2033,6 +1994,7 @@ func (h *MessageHandler) handleVolunteerTaskConfirm(ctx context.Context, callbac
 	}
 
 	h.showVolunteerTaskDetail(ctx, chatID, callbackQuery.Callback.User.UserId, taskID, h.messages.VolunteerTaskConfirmSuccessText)
+	h.notifyTaskOwner(ctx, taskID, callbackQuery.Callback.User.UserId, taskEventConfirmed)
 }
 
 func (h *MessageHandler) showVolunteerTaskDetail(ctx context.Context, chatID, userID int64, taskID string, intro ...string) {
@@ -2060,10 +2022,10 @@ func (h *MessageHandler) showVolunteerTaskDetail(ctx context.Context, chatID, us
 	}
 
 	name := safeTaskName(task.GetName())
-	builder.WriteString(fmt.Sprintf(title, name))
+	builder.WriteString(fmt.Sprintf(title, escapeMarkdown(name)))
 	builder.WriteString("\n\n")
 
-	builder.WriteString(safeTaskDescription(task.GetDescription()))
+	builder.WriteString(escapeMarkdown(safeTaskDescription(task.GetDescription())))
 	builder.WriteString("\n\n")
 	builder.WriteString(h.customerTaskDetailAttributes(task))
 	builder.WriteString("\n\n")
@@ -2088,7 +2050,7 @@ func (h *MessageHandler) showVolunteerTaskDetail(ctx context.Context, chatID, us
 				displayName = h.lookupUserName(ctx, assignment.UserID)
 				namesCache[assignment.UserID] = displayName
 			}
-			builder.WriteString(fmt.Sprintf("%d. %s — %s\n", idx+1, displayName, customerStatusLabel(assignment.Status)))
+			builder.WriteString(fmt.Sprintf("%d. %s — %s\n", idx+1, escapeMarkdown(displayName), customerStatusLabel(assignment.Status)))
 
 			buttonLabel := truncateLabel(fmt.Sprintf("%d. %s %s", idx+1, displayName, volunteerStatusBadge(assignment.Status)), 45)
 			keyboard.AddRow().
@@ -2150,15 +2112,10 @@ func (h *MessageHandler) handleCustomerTaskView(ctx context.Context, callbackQue
 	h.showCustomerTaskDetail(ctx, chatID, userID, taskID)
 }
 
-func (h *MessageHandler) handleCustomerTaskApprove(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, data string) {
+func (h *MessageHandler) handleCustomerTaskApprove(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, taskID, volunteerID string) {
 	h.answerCallback(ctx, callbackQuery.Callback.CallbackID)
 
-	if callbackQuery.Message == nil || data == "" {
-		return
-	}
-
-	taskID, volunteerID, ok := splitTaskAssignmentData(data)
-	if !ok {
+	if callbackQuery.Message == nil || taskID == "" || volunteerID == "" {
 		return
 	}
 
@@ -2179,67 +2136,39 @@ func (h *MessageHandler) handleCustomerTaskApprove(ctx context.Context, callback
 	}
 
 	chatID := callbackQuery.Message.Recipient.ChatId
+	userID := callbackQuery.Callback.User.UserId
 
-	resp, err := h.task.ApproveTask(ctx, &taskpb.ApproveTaskRequest{UserId: volunteerID, TaskId: taskID})
-	if err != nil || (resp != nil && resp.GetError() != nil) {
-		h.showCustomerTaskAssignmentDetail(ctx, chatID, callbackQuery.Callback.User.UserId, taskID, volunteerID, h.messages.CustomerTaskDecisionErrorText)
+	if h.accountSuspended(ctx, volunteerID) {
+		h.showCustomerTaskAssignmentDetail(ctx, chatID, userID, taskID, volunteerID, h.customerTaskVolunteerSuspendedText())
 		return
 	}
 
-	successText := h.customerTaskApproveSuccessText(task.GetName(), task.GetCost())
-
-	if cost := task.GetCost(); cost > 0 {
-		if h.user == nil {
-			h.logger.Error("user service client is not configured for reward credit", zap.String("task_id", taskID), zap.String("volunteer_id", volunteerID))
-			h.showCustomerTaskAssignmentDetail(ctx, chatID, callbackQuery.Callback.User.UserId, taskID, volunteerID, h.messages.CustomerTaskDecisionErrorText)
-			return
-		}
-
-		opReq := &userpb.CreateOperationRequest{
-			MaxId:       volunteerID,
-			Amount:      cost,
-			Type:        userpb.BalanceOperationType_BALANCE_OPERATION_TYPE_DEPOSIT,
-			Description: h.customerTaskRewardDescription(task.GetName()),
-		}
-
-		opResp, err := h.user.CreateOperation(ctx, opReq)
-		if err != nil {
-			h.logger.Error("failed to credit volunteer reward", zap.Error(err), zap.String("task_id", taskID), zap.String("volunteer_id", volunteerID))
-			h.showCustomerTaskAssignmentDetail(ctx, chatID, callbackQuery.Callback.User.UserId, taskID, volunteerID, h.messages.CustomerTaskDecisionErrorText)
-			return
-		}
-
-		if svcErr := opResp.GetError(); svcErr != nil {
-			h.logger.Warn("user service returned error when crediting reward", zap.String("task_id", taskID), zap.String("volunteer_id", volunteerID), zap.String("message", svcErr.GetMessage()))
-			h.showCustomerTaskAssignmentDetail(ctx, chatID, callbackQuery.Callback.User.UserId, taskID, volunteerID, h.messages.CustomerTaskDecisionErrorText)
-			return
-		}
+	outcome, err := h.approveWithPayout(ctx, task, volunteerID, userID)
+	if err != nil {
+		h.logger.Warn("failed to approve task", zap.Error(err), zap.String("task_id", taskID), zap.String("volunteer_id", volunteerID))
+		h.showCustomerTaskAssignmentDetail(ctx, chatID, userID, taskID, volunteerID, h.messages.CustomerTaskDecisionErrorText)
+		return
+	}
 
-		volunteerNumericID, err := strconv.ParseInt(volunteerID, 10, 64)
-		if err != nil || volunteerNumericID <= 0 {
-			h.logger.Warn("failed to parse volunteer id for reward notification", zap.String("volunteer_id", volunteerID), zap.Error(err))
-		} else {
-			notification := strings.TrimSpace(h.volunteerTaskRewardNotification(task.GetName(), cost))
-			if notification != "" {
-				if _, err := h.sendInteractiveMessage(ctx, volunteerNumericID, volunteerNumericID, notification, nil); err != nil {
-					h.logger.Error("failed to send reward notification", zap.Error(err), zap.Int64("volunteer_id", volunteerNumericID), zap.String("task_id", taskID))
-				}
-			}
-		}
+	successText := h.customerTaskApproveSuccessText(task.GetName(), task.GetCost())
+	switch outcome {
+	case payoutDuplicate:
+		h.showCustomerTaskAssignmentDetail(ctx, chatID, userID, taskID, volunteerID, h.payoutDuplicateText())
+		return
+	case payoutInProgress:
+		h.showCustomerTaskAssignmentDetail(ctx, chatID, userID, taskID, volunteerID, h.payoutInProgressText())
+		return
+	case payoutDeferred:
+		successText += "\n\n" + h.payoutDeferredText()
 	}
 
-	h.showCustomerTaskAssignmentDetail(ctx, chatID, callbackQuery.Callback.User.UserId, taskID, volunteerID, successText)
+	h.promptVolunteerFeedback(ctx, chatID, userID, taskID, volunteerID, successText)
 }
 
-func (h *MessageHandler) handleCustomerTaskReject(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, data string) {
+func (h *MessageHandler) handleCustomerTaskReject(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, taskID, volunteerID string) {
 	h.answerCallback(ctx, callbackQuery.Callback.CallbackID)
 
-	if callbackQuery.Message == nil || data == "" {
-		return
-	}
-
-	taskID, volunteerID, ok := splitTaskAssignmentData(data)
-	if !ok {
+	if callbackQuery.Message == nil || taskID == "" || volunteerID == "" {
 		return
 	}
 
@@ -2279,9 +2208,9 @@ func (h *MessageHandler) showCustomerTaskDetail(ctx context.Context, chatID, use
 	}
 
 	name := safeTaskName(task.GetName())
-	builder.WriteString(fmt.Sprintf(title, name))
+	builder.WriteString(fmt.Sprintf(title, escapeMarkdown(name)))
 	builder.WriteString("\n\n")
-	builder.WriteString(safeTaskDescription(task.GetDescription()))
+	builder.WriteString(escapeMarkdown(safeTaskDescription(task.GetDescription())))
 	builder.WriteString("\n\n")
 	builder.WriteString(h.customerTaskDetailAttributes(task))
 	builder.WriteString("\n\n")
@@ -2304,7 +2233,7 @@ func (h *MessageHandler) showCustomerTaskDetail(ctx context.Context, chatID, use
 				displayName = h.lookupUserName(ctx, assignment.UserID)
 				namesCache[assignment.UserID] = displayName
 			}
-			builder.WriteString(fmt.Sprintf("%d. %s — %s\n", idx+1, displayName, customerStatusLabel(assignment.Status)))
+			builder.WriteString(fmt.Sprintf("%d. %s — %s\n", idx+1, escapeMarkdown(displayName), customerStatusLabel(assignment.Status)))
 
 			buttonLabel := truncateLabel(fmt.Sprintf("%d. %s %s", idx+1, displayName, volunteerStatusBadge(assignment.Status)), 45)
 			keyboard.AddRow().
@@ -2322,6 +2251,9 @@ func (h *MessageHandler) showCustomerTaskDetail(ctx context.Context, chatID, use
 		backLabel = "⬅️ Назад"
 	}
 
+	keyboard.AddRow().
+		AddCallback(h.customerTaskEditButton(), schemes.DEFAULT, fmt.Sprintf("%s:%s", callbackCustomerTaskEdit, taskID)).
+		AddCallback(h.customerTaskCancelButton(), schemes.NEGATIVE, fmt.Sprintf("%s:%s", callbackCustomerTaskCancelAsk, taskID))
 	keyboard.AddRow().
 		AddCallback(createLabel, schemes.POSITIVE, callbackCustomerManageCreateTask)
 	keyboard.AddRow().
@@ -2338,15 +2270,10 @@ func truncateLabel(label string, max int) string {
 	return string(runes[:max-1]) + "…"
 }
 
-func (h *MessageHandler) handleCustomerTaskAssignment(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, data string) {
+func (h *MessageHandler) handleCustomerTaskAssignment(ctx context.Context, callbackQuery *schemes.MessageCallbackUpdate, taskID, volunteerID string) {
 	h.answerCallback(ctx, callbackQuery.Callback.CallbackID)
 
-	if callbackQuery.Message == nil || data == "" {
-		return
-	}
-
-	taskID, volunteerID, ok := splitTaskAssignmentData(data)
-	if !ok {
+	if callbackQuery.Message == nil || taskID == "" || volunteerID == "" {
 		return
 	}
 
@@ -2380,13 +2307,17 @@ func (h *MessageHandler) showCustomerTaskAssignmentDetail(ctx context.Context, c
 	}
 
 	taskName := safeTaskName(task.GetName())
-	builder.WriteString(fmt.Sprintf(title, taskName))
+	builder.WriteString(fmt.Sprintf(title, escapeMarkdown(taskName)))
 	builder.WriteString("\n\n")
 
 	displayName := h.lookupUserName(ctx, volunteerID)
-	builder.WriteString(fmt.Sprintf("*Волонтёр:* %s\n", displayName))
+	builder.WriteString(fmt.Sprintf("*Волонтёр:* %s\n", escapeMarkdown(displayName)))
+	if rating := h.volunteerRatingLine(ctx, volunteerID); rating != "" {
+		builder.WriteString(rating)
+		builder.WriteString("\n")
+	}
 	builder.WriteString(fmt.Sprintf("*Статус:* %s\n\n", customerStatusLabel(status)))
-	builder.WriteString(safeTaskDescription(task.GetDescription()))
+	builder.WriteString(escapeMarkdown(safeTaskDescription(task.GetDescription())))
 	builder.WriteString("\n\n")
 	builder.WriteString(h.customerTaskDetailAttributes(task))
 
@@ -2403,6 +2334,10 @@ func (h *MessageHandler) showCustomerTaskAssignmentDetail(ctx context.Context, c
 		AddCallback(approveLabel, schemes.POSITIVE, fmt.Sprintf("%s:%s:%s", callbackCustomerTaskApprove, taskID, volunteerID))
 	keyboard.AddRow().
 		AddCallback(rejectLabel, schemes.NEGATIVE, fmt.Sprintf("%s:%s:%s", callbackCustomerTaskReject, taskID, volunteerID))
+	if h.canLeaveFeedback(ctx, taskID, volunteerID, status) {
+		keyboard.AddRow().
+			AddCallback(h.feedbackRateButton(), schemes.DEFAULT, fmt.Sprintf("%s:%s:%s", callbackCustomerFeedbackStart, taskID, volunteerID))
+	}
 	keyboard.AddRow().
 		AddCallback(h.messages.CustomerManageTasksButton, schemes.DEFAULT, callbackCustomerManageTasks)
 	keyboard.AddRow().
@@ -2411,19 +2346,6 @@ func (h *MessageHandler) showCustomerTaskAssignmentDetail(ctx context.Context, c
 	h.renderMenu(ctx, chatID, userID, builder.String(), keyboard)
 }
 
-func splitTaskAssignmentData(data string) (string, string, bool) {
-	parts := strings.SplitN(strings.TrimSpace(data), ":", 2)
-	if len(parts) != 2 {
-		return "", "", false
-	}
-	taskID := strings.TrimSpace(parts[0])
-	volunteerID := strings.TrimSpace(parts[1])
-	if taskID == "" || volunteerID == "" {
-		return "", "", false
-	}
-	return taskID, volunteerID, true
-}
-
 func parseTaskAssignments(task *taskpb.Task) []taskAssignment {
 	if task == nil {
 		return nil
@@ -2787,7 +2709,7 @@ func (h *MessageHandler) customerTaskLocationText(task *taskpb.Task, meta map[st
 	if isOnlineTask(task) {
 		location = h.taskCreateFormatOnlineLabel()
 	} else if label := strings.TrimSpace(meta["location_label"]); label != "" {
-		location = label
+		location = escapeMarkdown(label)
 	} else if geo := strings.TrimSpace(meta["geo_data"]); geo != "" {
 		location = fmt.Sprintf("%s (%s)", h.taskCreateLocationFallbackLabel(), geo)
 	}
@@ -2807,11 +2729,7 @@ func (h *MessageHandler) customerTaskLocationText(task *taskpb.Task, meta map[st
 func (h *MessageHandler) customerTaskRewardText(task *taskpb.Task) string {
 	reward := task.GetCost()
 	if reward > 0 {
-		template := strings.TrimSpace(h.messages.CustomerTaskDetailReward)
-		if template == "" {
-			template = "Награда: %d добриков"
-		}
-		return fmt.Sprintf(template, reward)
+		return h.render(h.messages.CustomerTaskDetailReward, "Награда: "+defaultRewardAmountText, rewardData{Amount: reward})
 	}
 
 	if text := strings.TrimSpace(h.messages.CustomerTaskDetailNoReward); text != "" {
@@ -2822,30 +2740,12 @@ func (h *MessageHandler) customerTaskRewardText(task *taskpb.Task) string {
 }
 
 func (h *MessageHandler) customerTaskVolunteersText(task *taskpb.Task) string {
-	members := task.GetMembersCount()
-	if members <= 1 {
-		if text := strings.TrimSpace(h.messages.CustomerTaskDetailVolunteersOne); text != "" {
-			return text
-		}
-		return "Нужен 1 волонтёр"
-	}
-
-	template := strings.TrimSpace(h.messages.CustomerTaskDetailVolunteersMany)
-	if template == "" {
-		template = "Нужно волонтёров: %d"
-	}
-
-	return fmt.Sprintf(template, members)
+	return h.render(h.messages.CustomerTaskDetailVolunteers, defaultVolunteersNeededText, countData{Count: volunteersNeeded(task.GetMembersCount())})
 }
 
 func (h *MessageHandler) customerTaskCreatedAtText(created int32) string {
-	layout := "02.01.2006 15:04"
 	timestamp := time.Unix(int64(created), 0).In(time.Local)
-	template := strings.TrimSpace(h.messages.CustomerTaskDetailCreatedAt)
-	if template == "" {
-		template = "Создано: %s"
-	}
-	return fmt.Sprintf(template, timestamp.Format(layout))
+	return h.render(h.messages.CustomerTaskDetailCreatedAt, "Создано: {{date .CreatedAt}}", createdData{CreatedAt: timestamp})
 }
 
 func (h *MessageHandler) customerTaskAssignmentsEmptyText() string {
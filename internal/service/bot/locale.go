@@ -0,0 +1,48 @@
+package bot
+
+import (
+	"encoding/json"
+
+	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
+)
+
+// rememberUserLocale hands the user_locale of an update to the message
+// handler. The SDK's update types have no field for it, so it is read from
+// the raw JSON the client keeps in debug mode, which works the same for
+// polling and webhook updates.
+func (b *Bot) rememberUserLocale(update schemes.UpdateInterface) {
+	if userID, locale := updateLocale([]byte(update.GetDebugRaw())); locale != "" {
+		b.messageHandler.RememberUserLocale(userID, locale)
+	}
+}
+
+// updateLocale pulls the sender and user_locale out of a raw update. For a
+// callback the sender is the user who pressed the button, not the author of
+// the message it was on.
+func updateLocale(body []byte) (int64, string) {
+	if len(body) == 0 {
+		return 0, ""
+	}
+
+	var update struct {
+		UserLocale string `json:"user_locale"`
+		Callback   *struct {
+			User schemes.User `json:"user"`
+		} `json:"callback"`
+		Message *struct {
+			Sender schemes.User `json:"sender"`
+		} `json:"message"`
+	}
+	if err := json.Unmarshal(body, &update); err != nil || update.UserLocale == "" {
+		return 0, ""
+	}
+
+	switch {
+	case update.Callback != nil:
+		return update.Callback.User.UserId, update.UserLocale
+	case update.Message != nil:
+		return update.Message.Sender.UserId, update.UserLocale
+	default:
+		return 0, ""
+	}
+}
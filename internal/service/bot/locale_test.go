@@ -0,0 +1,48 @@
+package bot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"DobrikaDev/max-bot/utils/config"
+
+	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
+)
+
+// TestUpdateKeepsUserLocale decodes an update the way both update modes do
+// and checks its user_locale can still be read afterwards.
+func TestUpdateKeepsUserLocale(t *testing.T) {
+	api, err := NewAPI(&config.Config{MaxToken: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		userID int64
+		locale string
+	}{
+		{"message", `{"update_type":"message_created","timestamp":1,"message":{"sender":{"user_id":7},"body":{"mid":"m","text":"hi"}},"user_locale":"en-GB"}`, 7, "en-GB"},
+		{"callback", `{"update_type":"message_callback","timestamp":1,"callback":{"callback_id":"c","payload":"p","user":{"user_id":8}},"message":{"sender":{"user_id":1},"body":{"mid":"m"}},"user_locale":"tt-RU"}`, 8, "tt-RU"},
+		{"no locale", `{"update_type":"message_created","timestamp":1,"message":{"sender":{"user_id":7},"body":{"mid":"m"}}}`, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updates := make(chan schemes.UpdateInterface, 1)
+			rec := httptest.NewRecorder()
+			api.GetHandler(updates).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+			}
+
+			userID, locale := updateLocale([]byte((<-updates).GetDebugRaw()))
+			if userID != tt.userID || locale != tt.locale {
+				t.Fatalf("got %d %q, want %d %q", userID, locale, tt.userID, tt.locale)
+			}
+		})
+	}
+}
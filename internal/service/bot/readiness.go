@@ -0,0 +1,57 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	apiCheckTimeout  = 5 * time.Second
+	apiCheckCacheTTL = 30 * time.Second
+)
+
+// apiCheck caches the result of the MAX API reachability probe so that
+// frequent readiness checks do not turn into a request per probe.
+type apiCheck struct {
+	mu        sync.Mutex
+	checkedAt time.Time
+	err       error
+}
+
+// Ready reports whether the bot can serve updates: the service connections
+// are usable and the MAX API answers.
+func (b *Bot) Ready(ctx context.Context) error {
+	var errs []error
+
+	if err := b.messageHandler.CheckServices(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := b.checkAPI(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (b *Bot) checkAPI(ctx context.Context) error {
+	b.apiCheck.mu.Lock()
+	defer b.apiCheck.mu.Unlock()
+
+	if !b.apiCheck.checkedAt.IsZero() && time.Since(b.apiCheck.checkedAt) < apiCheckCacheTTL {
+		return b.apiCheck.err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, apiCheckTimeout)
+	defer cancel()
+
+	b.apiCheck.err = nil
+	if _, err := b.api.Bots.GetBot(ctx); err != nil {
+		b.apiCheck.err = fmt.Errorf("MAX API is unreachable: %w", err)
+	}
+	b.apiCheck.checkedAt = time.Now()
+
+	return b.apiCheck.err
+}
@@ -0,0 +1,186 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	schemes "github.com/max-messenger/max-bot-api-client-go/schemes"
+	"go.uber.org/zap"
+)
+
+const (
+	updateModePolling = "polling"
+	updateModeWebhook = "webhook"
+
+	apiBaseURL = "https://botapi.max.ru"
+	apiVersion = "1.2.5"
+
+	webhookSecretHeader    = "X-Max-Bot-Api-Secret"
+	webhookQueueSize       = 100
+	webhookShutdownTimeout = 10 * time.Second
+	webhookRequestTimeout  = 10 * time.Second
+)
+
+var (
+	webhookSecretPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{5,256}$`)
+	webhookUpdateTypes   = []string{string(schemes.TypeMessageCreated), string(schemes.TypeMessageCallback)}
+	subscriptionClient   = &http.Client{Timeout: webhookRequestTimeout}
+)
+
+func (b *Bot) updateMode() string {
+	mode := strings.ToLower(strings.TrimSpace(b.cfg.UpdateMode))
+	if mode == "" {
+		return updateModePolling
+	}
+	return mode
+}
+
+// startWebhook serves MAX update payloads over HTTP and registers the webhook
+// subscription. The returned channel is closed after the context is cancelled,
+// the server has drained and the subscription has been removed.
+func (b *Bot) startWebhook() (<-chan schemes.UpdateInterface, error) {
+	if strings.TrimSpace(b.cfg.WebhookURL) == "" {
+		return nil, errors.New("webhook url is not configured")
+	}
+	if !webhookSecretPattern.MatchString(b.cfg.WebhookSecret) {
+		return nil, errors.New("webhook secret must be 5-256 characters of A-Z, a-z, 0-9, _ and -")
+	}
+
+	path := b.cfg.WebhookPath
+	if path == "" {
+		path = "/webhook"
+	}
+
+	updates := make(chan schemes.UpdateInterface, webhookQueueSize)
+
+	mux := http.NewServeMux()
+	mux.Handle(path, b.verifyWebhookSecret(b.api.GetHandler(updates)))
+
+	server := &http.Server{
+		Addr:              b.cfg.WebhookListenAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		b.logger.Info("Webhook server listening", zap.String("addr", server.Addr), zap.String("path", path))
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			b.logger.Error("webhook server stopped", zap.Error(err))
+		}
+	}()
+
+	if err := b.subscribeWebhook(b.ctx); err != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), webhookShutdownTimeout)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+		return nil, err
+	}
+	b.logger.Info("Webhook subscription registered", zap.String("url", b.cfg.WebhookURL))
+
+	go func() {
+		<-b.ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), webhookShutdownTimeout)
+		defer cancel()
+
+		if err := b.unsubscribeWebhook(shutdownCtx); err != nil {
+			b.logger.Warn("failed to remove webhook subscription", zap.Error(err))
+		} else {
+			b.logger.Info("Webhook subscription removed", zap.String("url", b.cfg.WebhookURL))
+		}
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			b.logger.Warn("failed to shut down webhook server", zap.Error(err))
+		}
+
+		close(updates)
+	}()
+
+	return updates, nil
+}
+
+func (b *Bot) verifyWebhookSecret(next http.Handler) http.Handler {
+	expected := []byte(b.cfg.WebhookSecret)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get(webhookSecretHeader))
+		if subtle.ConstantTimeCompare(got, expected) != 1 {
+			b.logger.Warn("rejected webhook request with invalid secret", zap.String("remote_addr", r.RemoteAddr))
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (b *Bot) subscribeWebhook(ctx context.Context) error {
+	body := &schemes.SubscriptionRequestBody{
+		Url:         b.cfg.WebhookURL,
+		Secret:      b.cfg.WebhookSecret,
+		UpdateTypes: webhookUpdateTypes,
+		Version:     apiVersion,
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal subscription: %w", err)
+	}
+
+	return b.subscriptionRequest(ctx, http.MethodPost, url.Values{}, bytes.NewReader(data))
+}
+
+func (b *Bot) unsubscribeWebhook(ctx context.Context) error {
+	query := url.Values{}
+	query.Set("url", b.cfg.WebhookURL)
+	return b.subscriptionRequest(ctx, http.MethodDelete, query, nil)
+}
+
+func (b *Bot) subscriptionRequest(ctx context.Context, method string, query url.Values, body io.Reader) error {
+	query.Set("access_token", b.cfg.MaxToken)
+	query.Set("v", apiVersion)
+
+	base := strings.TrimSuffix(b.cfg.MaxAPIURL, "/")
+	if base == "" {
+		base = apiBaseURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s/subscriptions?%s", base, query.Encode()), body)
+	if err != nil {
+		return fmt.Errorf("failed to create subscription request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := subscriptionClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute subscription request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("http %d: %s", resp.StatusCode, strings.TrimSpace(string(bodyBytes)))
+	}
+
+	var result schemes.SimpleQueryResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode subscription response: %w", err)
+	}
+	if !result.Success {
+		return fmt.Errorf("subscription request unsuccessful: %s", result.Message)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+const readinessTimeout = 10 * time.Second
+
+// ReadinessFunc returns nil when the service is ready to take traffic.
+type ReadinessFunc func(ctx context.Context) error
+
+// Server exposes liveness, readiness and Prometheus metrics on an internal
+// address that is not meant to be published outside the cluster.
+type Server struct {
+	logger *zap.Logger
+	ready  ReadinessFunc
+	server *http.Server
+}
+
+func NewServer(addr string, logger *zap.Logger, ready ReadinessFunc) *Server {
+	s := &Server{logger: logger, ready: ready}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealth)
+	mux.HandleFunc("/readyz", s.handleReady)
+	mux.Handle("/metrics", promhttp.Handler())
+
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return s
+}
+
+func (s *Server) Start() {
+	s.logger.Info("Monitoring server listening", zap.String("addr", s.server.Addr))
+	if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.logger.Error("monitoring server stopped", zap.Error(err))
+	}
+}
+
+func (s *Server) Close(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte("ok\n"))
+}
+
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	if s.ready != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+		defer cancel()
+
+		if err := s.ready(ctx); err != nil {
+			s.logger.Warn("readiness check failed", zap.Error(err))
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(err.Error() + "\n"))
+			return
+		}
+	}
+
+	_, _ = w.Write([]byte("ok\n"))
+}
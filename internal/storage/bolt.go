@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// BoltStore persists entries in a single bbolt file so that conversation
+// state survives pod restarts when the file lives on a mounted volume.
+type BoltStore struct {
+	db *bolt.DB
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewBoltStore(path string, sweepInterval time.Duration) (*BoltStore, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create session store directory: %w", err)
+		}
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session store %s: %w", path, err)
+	}
+
+	s := &BoltStore{db: db, stop: make(chan struct{})}
+	s.sweep(time.Now())
+
+	go runJanitor(sweepInterval, s.stop, s.sweep)
+
+	return s, nil
+}
+
+func (s *BoltStore) Get(bucket, key string) ([]byte, bool, error) {
+	var (
+		value []byte
+		found bool
+	)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+
+		raw := b.Get([]byte(key))
+		if raw == nil {
+			return nil
+		}
+
+		var rec record
+		if err := json.Unmarshal(raw, &rec); err != nil {
+			return fmt.Errorf("failed to decode %s/%s: %w", bucket, key, err)
+		}
+		if rec.expired(time.Now()) {
+			return nil
+		}
+
+		value = rec.Value
+		found = true
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return value, found, nil
+}
+
+func (s *BoltStore) Set(bucket, key string, value []byte, ttl time.Duration) error {
+	data, err := json.Marshal(newRecord(value, ttl))
+	if err != nil {
+		return fmt.Errorf("failed to encode %s/%s: %w", bucket, key, err)
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), data)
+	})
+}
+
+func (s *BoltStore) Delete(bucket, key string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		return b.Delete([]byte(key))
+	})
+}
+
+func (s *BoltStore) Stats() (map[string]int, error) {
+	now := time.Now()
+	stats := make(map[string]int)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			count := 0
+			err := b.ForEach(func(_, v []byte) error {
+				var rec record
+				if err := json.Unmarshal(v, &rec); err == nil && !rec.expired(now) {
+					count++
+				}
+				return nil
+			})
+			stats[string(name)] = count
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (s *BoltStore) Close() error {
+	s.stopOnce.Do(func() { close(s.stop) })
+	return s.db.Close()
+}
+
+func (s *BoltStore) sweep(now time.Time) {
+	_ = s.db.Update(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			var expired [][]byte
+			err := b.ForEach(func(k, v []byte) error {
+				var rec record
+				if err := json.Unmarshal(v, &rec); err != nil || rec.expired(now) {
+					expired = append(expired, append([]byte(nil), k...))
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, k := range expired {
+				if err := b.Delete(k); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"encoding/json"
+	"slices"
+	"sort"
+)
+
+// Index is a sorted list of keys kept under a single store entry. The store
+// cannot enumerate a bucket, so tables that must be walked on startup record
+// their live keys here. Callers serialise their own updates.
+type Index struct {
+	store  Store
+	bucket string
+	key    string
+}
+
+// NewIndex keeps the list under key in bucket.
+func NewIndex(store Store, bucket, key string) Index {
+	return Index{store: store, bucket: bucket, key: key}
+}
+
+// List returns the recorded keys in order.
+func (x Index) List() ([]string, error) {
+	data, ok, err := x.store.Get(x.bucket, x.key)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	var keys []string
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Set adds or removes key. The entry is only rewritten when it changes.
+func (x Index) Set(key string, present bool) error {
+	keys, err := x.List()
+	if err != nil {
+		return err
+	}
+
+	pos := sort.SearchStrings(keys, key)
+	found := pos < len(keys) && keys[pos] == key
+
+	switch {
+	case present && !found:
+		keys = slices.Insert(keys, pos, key)
+	case !present && found:
+		keys = slices.Delete(keys, pos, pos+1)
+	default:
+		return nil
+	}
+
+	data, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+	return x.store.Set(x.bucket, x.key, data, 0)
+}
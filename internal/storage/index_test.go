@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestIndex(t *testing.T) {
+	store := NewMemoryStore(0)
+	defer store.Close()
+
+	index := NewIndex(store, "test_index", "pending")
+	steps := []struct {
+		key     string
+		present bool
+		want    []string
+	}{
+		{"20", true, []string{"20"}},
+		{"10", true, []string{"10", "20"}},
+		{"30", true, []string{"10", "20", "30"}},
+		{"20", true, []string{"10", "20", "30"}},
+		{"20", false, []string{"10", "30"}},
+		{"40", false, []string{"10", "30"}},
+	}
+
+	for _, step := range steps {
+		if err := index.Set(step.key, step.present); err != nil {
+			t.Fatalf("set %s %v: %v", step.key, step.present, err)
+		}
+		got, err := index.List()
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if !slices.Equal(got, step.want) {
+			t.Fatalf("after set %s %v: got %v, want %v", step.key, step.present, got, step.want)
+		}
+	}
+}
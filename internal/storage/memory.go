@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"sync"
+	"time"
+)
+
+type MemoryStore struct {
+	mu      sync.RWMutex
+	buckets map[string]map[string]record
+	closed  bool
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewMemoryStore(sweepInterval time.Duration) *MemoryStore {
+	s := &MemoryStore{
+		buckets: make(map[string]map[string]record),
+		stop:    make(chan struct{}),
+	}
+
+	go runJanitor(sweepInterval, s.stop, s.sweep)
+
+	return s
+}
+
+func (s *MemoryStore) Get(bucket, key string) ([]byte, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return nil, false, ErrClosed
+	}
+
+	rec, ok := s.buckets[bucket][key]
+	if !ok || rec.expired(time.Now()) {
+		return nil, false, nil
+	}
+
+	value := make([]byte, len(rec.Value))
+	copy(value, rec.Value)
+	return value, true, nil
+}
+
+func (s *MemoryStore) Set(bucket, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+
+	items, ok := s.buckets[bucket]
+	if !ok {
+		items = make(map[string]record)
+		s.buckets[bucket] = items
+	}
+
+	stored := make([]byte, len(value))
+	copy(stored, value)
+	items[key] = newRecord(stored, ttl)
+	return nil
+}
+
+func (s *MemoryStore) Delete(bucket, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+
+	delete(s.buckets[bucket], key)
+	return nil
+}
+
+func (s *MemoryStore) Stats() (map[string]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return nil, ErrClosed
+	}
+
+	now := time.Now()
+	stats := make(map[string]int, len(s.buckets))
+	for bucket, items := range s.buckets {
+		count := 0
+		for _, rec := range items {
+			if !rec.expired(now) {
+				count++
+			}
+		}
+		stats[bucket] = count
+	}
+	return stats, nil
+}
+
+func (s *MemoryStore) Close() error {
+	s.stopOnce.Do(func() { close(s.stop) })
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	return nil
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, items := range s.buckets {
+		for key, rec := range items {
+			if rec.expired(now) {
+				delete(items, key)
+			}
+		}
+	}
+}
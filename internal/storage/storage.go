@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"DobrikaDev/max-bot/utils/config"
+)
+
+const (
+	BackendMemory = "memory"
+	BackendBolt   = "bolt"
+
+	defaultBoltPath      = "data/sessions.db"
+	defaultSweepInterval = time.Minute
+)
+
+var ErrClosed = errors.New("storage is closed")
+
+// Store keeps short-lived conversation state (registration steps, drafts,
+// menu message ids) grouped into buckets. Entries with a positive TTL expire
+// and are no longer returned by Get once the TTL has passed. Stats reports
+// the number of live entries per bucket.
+type Store interface {
+	Get(bucket, key string) ([]byte, bool, error)
+	Set(bucket, key string, value []byte, ttl time.Duration) error
+	Delete(bucket, key string) error
+	Stats() (map[string]int, error)
+	Close() error
+}
+
+func New(cfg *config.Config) (Store, error) {
+	backend := strings.ToLower(strings.TrimSpace(cfg.SessionStore))
+
+	switch backend {
+	case "", BackendMemory:
+		return NewMemoryStore(defaultSweepInterval), nil
+	case BackendBolt:
+		path := strings.TrimSpace(cfg.SessionStorePath)
+		if path == "" {
+			path = defaultBoltPath
+		}
+		return NewBoltStore(path, defaultSweepInterval)
+	default:
+		return nil, fmt.Errorf("unknown session store backend %q", cfg.SessionStore)
+	}
+}
+
+type record struct {
+	Value     []byte `json:"value"`
+	ExpiresAt int64  `json:"expires_at,omitempty"`
+}
+
+func newRecord(value []byte, ttl time.Duration) record {
+	rec := record{Value: value}
+	if ttl > 0 {
+		rec.ExpiresAt = time.Now().Add(ttl).UnixNano()
+	}
+	return rec
+}
+
+func (r record) expired(now time.Time) bool {
+	return r.ExpiresAt > 0 && now.UnixNano() >= r.ExpiresAt
+}
+
+func runJanitor(interval time.Duration, stop <-chan struct{}, sweep func(now time.Time)) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			sweep(now)
+		}
+	}
+}
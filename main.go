@@ -5,21 +5,56 @@ import (
 	"DobrikaDev/max-bot/utils/config"
 	"DobrikaDev/max-bot/utils/logger"
 	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
 )
 
+const defaultShutdownTimeout = 20 * time.Second
+
 func main() {
-	ctx := context.Background()
+	os.Exit(run())
+}
+
+func run() int {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
 	cfg := config.MustLoadConfigFromFile("deployments/config.yaml")
 	logger, _ := logger.NewLogger()
 	defer logger.Sync()
 
 	container := di.NewContainer(ctx, cfg, logger)
+	bot := container.GetBot()
+
+	go container.GetMonitoringServer().Start()
 
 	go func() {
 		logger.Info("Starting MAX bot")
 
-		container.GetBot().Start()
+		bot.Start()
 	}()
 
 	<-ctx.Done()
+	stop()
+
+	timeout := cfg.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	logger.Info("Shutting down MAX bot", zap.Duration("timeout", timeout))
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := container.Close(shutdownCtx); err != nil {
+		logger.Error("shutdown finished with errors", zap.Error(err))
+		return 1
+	}
+
+	logger.Info("MAX bot stopped")
+	return 0
 }
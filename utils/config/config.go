@@ -1,15 +1,49 @@
 package config
 
 import (
+	"time"
+
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	MaxToken          string `mapstructure:"max_token" env:"MAX_TOKEN"`
-	UserServiceURL    string `mapstructure:"user_service_url" env:"USER_SERVICE_URL"`
+	MaxToken           string `mapstructure:"max_token" env:"MAX_TOKEN"`
+	MaxAPIURL          string `mapstructure:"max_api_url" env:"MAX_API_URL"`
+	UserServiceURL     string `mapstructure:"user_service_url" env:"USER_SERVICE_URL"`
 	CustomerServiceURL string `mapstructure:"customer_service_url" env:"CUSTOMER_SERVICE_URL"`
 	TaskServiceURL     string `mapstructure:"task_service_url" env:"TASK_SERVICE_URL"`
+
+	SessionStore     string        `mapstructure:"session_store" env:"SESSION_STORE" env-default:"memory"`
+	SessionStorePath string        `mapstructure:"session_store_path" env:"SESSION_STORE_PATH"`
+	SessionTTL       time.Duration `mapstructure:"session_ttl" env:"SESSION_TTL" env-default:"24h"`
+
+	UpdateMode        string `mapstructure:"update_mode" env:"UPDATE_MODE" env-default:"polling"`
+	WebhookURL        string `mapstructure:"webhook_url" env:"WEBHOOK_URL"`
+	WebhookListenAddr string `mapstructure:"webhook_listen_addr" env:"WEBHOOK_LISTEN_ADDR" env-default:":8080"`
+	WebhookPath       string `mapstructure:"webhook_path" env:"WEBHOOK_PATH" env-default:"/webhook"`
+	WebhookSecret     string `mapstructure:"webhook_secret" env:"WEBHOOK_SECRET"`
+
+	DispatcherWorkers   int           `mapstructure:"dispatcher_workers" env:"DISPATCHER_WORKERS" env-default:"16"`
+	DispatcherQueueSize int           `mapstructure:"dispatcher_queue_size" env:"DISPATCHER_QUEUE_SIZE" env-default:"64"`
+	UpdateTimeout       time.Duration `mapstructure:"update_timeout" env:"UPDATE_TIMEOUT" env-default:"30s"`
+	ShutdownTimeout     time.Duration `mapstructure:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" env-default:"20s"`
+
+	MonitoringAddr string `mapstructure:"monitoring_addr" env:"MONITORING_ADDR" env-default:":9090"`
+
+	CallbackSecret string        `mapstructure:"callback_secret" env:"CALLBACK_SECRET"`
+	CallbackTTL    time.Duration `mapstructure:"callback_ttl" env:"CALLBACK_TTL"`
+
+	NotifyDigestWindow time.Duration `mapstructure:"notify_digest_window" env:"NOTIFY_DIGEST_WINDOW" env-default:"1m"`
+	NotifyTimezone     string        `mapstructure:"notify_timezone" env:"NOTIFY_TIMEZONE" env-default:"Europe/Moscow"`
+
+	OutboxMaxAttempts int           `mapstructure:"outbox_max_attempts" env:"OUTBOX_MAX_ATTEMPTS" env-default:"10"`
+	OutboxMaxAge      time.Duration `mapstructure:"outbox_max_age" env:"OUTBOX_MAX_AGE" env-default:"1h"`
+
+	AccountStatusTTL time.Duration `mapstructure:"account_status_ttl" env:"ACCOUNT_STATUS_TTL" env-default:"30s"`
+
+	LocaleDir     string `mapstructure:"locale_dir" env:"LOCALE_DIR"`
+	MessageFormat string `mapstructure:"message_format" env:"MESSAGE_FORMAT" env-default:"markdown"`
 }
 
 func LoadConfigFromFile(path string) (*Config, error) {